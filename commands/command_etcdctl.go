@@ -0,0 +1,158 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/logging"
+	"github.com/vmware/etcd-recovery/pkg/task"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// anyHealthyHost selects the first host whose etcd member is a voter
+// following a leader.
+const anyHealthyHost = "any-healthy"
+
+func NewCommandEtcdctl() *cobra.Command {
+	var host string
+
+	cmd := &cobra.Command{
+		Use:   "etcdctl --host <host|any-healthy> -- <etcdctl args>",
+		Short: "Run etcdctl against the etcd member of a host",
+		Long: `Run etcdctl inside the running etcd container of a host, against its local
+member, with the endpoint and TLS flags already set. The output of etcdctl is
+streamed as it is written.
+
+--host is the name or address of a host of the hosts config file, or
+"any-healthy" to use the first host whose member is a voter following a
+leader.
+`,
+		Example: `  etcd-recovery etcdctl --host etcd-vm1 -- member list -w table
+  etcd-recovery etcdctl --host any-healthy -- endpoint status --cluster -w table`,
+		Args: usageArgs(cobra.MinimumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if host == "" {
+				return usageErrorf("--host is required, the name or address of a host or %q", anyHealthyHost)
+			}
+
+			hosts, err := loadHostsRecorded()
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			h, client, containerID, err := etcdctlTarget(hosts, host)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			slog.Debug("Running etcdctl", logging.Host(h.Name, h.Host), "container_id", containerID, "args", args)
+			if err := streamEtcdctl(client, containerID, args, cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
+				return fmt.Errorf("%s: %w", h.Name, err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&host, "host", "", fmt.Sprintf("name or address of the host to run etcdctl on, or %q", anyHealthyHost))
+
+	return cmd
+}
+
+// etcdctlTarget connects to the host selected by --host, and returns it
+// with the ID of its running etcd container.
+func etcdctlTarget(hosts []*config.Host, host string) (*config.Host, transport.Transport, string, error) {
+	if host == anyHealthyHost {
+		return anyHealthyEtcd(hosts)
+	}
+
+	h := findHost(hosts, host)
+	if h == nil {
+		return nil, nil, "", usageErrorf("unknown host %q, valid hosts are: %v or %q", host, hostNames(hosts), anyHealthyHost)
+	}
+
+	client, err := connect(h)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to connect to %s: %w", h.Name, err)
+	}
+	containerID, err := task.EtcdContainerID(client)
+	if err != nil {
+		client.Close()
+		return nil, nil, "", fmt.Errorf("%s: %w", h.Name, err)
+	}
+	return h, client, containerID, nil
+}
+
+// anyHealthyEtcd connects to the hosts in turn, and returns the first one
+// whose member is healthy, see healthyEtcd.
+func anyHealthyEtcd(hosts []*config.Host) (*config.Host, transport.Transport, string, error) {
+	var errs []error
+	for _, h := range hosts {
+		client, err := connect(h)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to connect: %w", h.Name, err))
+			continue
+		}
+
+		containerID, err := healthyEtcd(client)
+		if err != nil {
+			client.Close()
+			errs = append(errs, fmt.Errorf("%s: %w", h.Name, err))
+			continue
+		}
+		slog.Info("Selected healthy etcd member", logging.Host(h.Name, h.Host))
+		return h, client, containerID, nil
+	}
+	return nil, nil, "", fmt.Errorf("no healthy etcd member: %w", errors.Join(errs...))
+}
+
+// healthyEtcd returns the ID of the etcd container of client if its
+// member is a voter following a leader.
+func healthyEtcd(client transport.Transport) (string, error) {
+	p, err := task.ProbeEtcd(client)
+	switch {
+	case err != nil:
+		return "", err
+	case p.IsLearner:
+		return "", fmt.Errorf("member %x is a learner", p.MemberID)
+	case p.Leader == 0:
+		return "", fmt.Errorf("member %x has no leader", p.MemberID)
+	}
+	return p.ContainerID, nil
+}
+
+// streamEtcdctl runs etcdctl with args in the etcd container, writing its
+// output to stdout and stderr while it runs.
+func streamEtcdctl(client transport.Transport, containerID string, args []string, stdout, stderr io.Writer) error {
+	quoted := make([]string, 0, len(args))
+	for _, a := range args {
+		quoted = append(quoted, shellQuote(a))
+	}
+
+	res, err := client.Exec(task.EtcdctlCommand(containerID, quoted...), transport.ExecOptions{Stdout: stdout, Stderr: stderr})
+	if err != nil {
+		return fmt.Errorf("failed to run etcdctl: %w", err)
+	}
+	if res.ExitCode != 0 || res.Signal != "" {
+		// The stderr was already streamed.
+		return &etcdctlExitError{Status: res.ExitCode, Signal: res.Signal}
+	}
+	return nil
+}
+
+func hostNames(hosts []*config.Host) []string {
+	names := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		names = append(names, h.Name)
+	}
+	return names
+}
@@ -5,14 +5,15 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/spf13/cobra"
 
 	"github.com/vmware/etcd-recovery/pkg/cliui"
 	"github.com/vmware/etcd-recovery/pkg/config"
-	"github.com/vmware/etcd-recovery/pkg/ssh"
+	"github.com/vmware/etcd-recovery/pkg/logging"
 )
 
 // NewCommandExecute executes command against host(s)
@@ -24,8 +25,8 @@ func NewCommandExecute() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "exec",
 		Short: "Execute command against host(s)",
-		Run: func(cmd *cobra.Command, args []string) {
-			executeCommandFunc(cmd, args, userCmd)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeCommandFunc(cmd, args, userCmd)
 		},
 	}
 
@@ -34,14 +35,14 @@ func NewCommandExecute() *cobra.Command {
 	return cmd
 }
 
-func executeCommandFunc(_ *cobra.Command, _ []string, userCmd string) {
-	hosts, err := config.ParseHostFromFile(configFile)
+func executeCommandFunc(_ *cobra.Command, _ []string, userCmd string) error {
+	hosts, err := loadHostsRecorded()
 	if err != nil {
-		log.Fatalf("Error parsing hosts config file: %v", err)
+		return fmt.Errorf("failed to parse hosts config file: %w", err)
 	}
 
 	if len(hosts) == 0 {
-		log.Fatalf("hosts.json should contain at least one Host, got: %d", len(hosts))
+		return fmt.Errorf("%w: hosts.json should contain at least one Host", config.ErrInvalidConfig)
 	}
 
 	options := make([]string, len(hosts)+1)
@@ -52,47 +53,52 @@ func executeCommandFunc(_ *cobra.Command, _ []string, userCmd string) {
 	options[len(hosts)] = "all"
 
 	idx, _, err := cliui.Select(
+		"host",
 		"Select the member to execute command against with:",
 		options,
 	)
 	if err != nil {
 		// user didn't select any host
-		log.Fatalf("no host selected, exiting: %v", err)
+		return fmt.Errorf("no host selected: %w", err)
 	}
 
 	if idx == len(hosts) {
+		var errs []error
 		for _, host := range hosts {
 			out, err := executeUserCommand(host, userCmd)
 			if err != nil {
-				log.Printf("Error executing command %q on host (%s: %s), output:\n %s\n error:\n %v\n", userCmd, host.Name, host.Host, string(out), err)
+				slog.Error("Failed to execute command", logging.Host(host.Name, host.Host), "command", userCmd, "output", out, "err", err)
+				errs = append(errs, fmt.Errorf("%s: %w", host.Name, err))
 				continue
 			}
-			printLog("output:\n %s\n", string(out))
+			fmt.Printf("%s (%s):\n%s\n", host.Name, host.Host, out)
 		}
-	} else {
-		out, err := executeUserCommand(hosts[idx], userCmd)
-		if err != nil {
-			log.Fatalf("Error executing command %q on host (%s: %s), output:\n %s\n error:\n %v\n", userCmd, hosts[idx].Name, hosts[idx].Host, string(out), err)
+		if len(errs) > 0 {
+			return fmt.Errorf("failed to execute command on %d of %d hosts: %w", len(errs), len(hosts), errors.Join(errs...))
 		}
-		printLog("output:\n %s\n", string(out))
+		return nil
 	}
+
+	out, err := executeUserCommand(hosts[idx], userCmd)
+	if err != nil {
+		if len(out) > 0 {
+			fmt.Printf("%s\n", out)
+		}
+		return fmt.Errorf("failed to execute command on %s: %w", hosts[idx].Name, err)
+	}
+	fmt.Printf("%s\n", out)
+	return nil
 }
 
 func executeUserCommand(host *config.Host, command string) ([]byte, error) {
-	printLog("Connecting to host (%s: %s)\n", host.Name, host.Host)
-
-	client, err := ssh.NewClient(&ssh.Config{
-		User:                 host.Username,
-		Host:                 host.Host,
-		Password:             host.Password,
-		PrivateKeyPath:       host.PrivateKey,
-		PrivateKeyPassphrase: host.Passphrase,
-	})
+	slog.Debug("Connecting to host", logging.Host(host.Name, host.Host))
+
+	client, err := connect(host)
 	if err != nil {
-		log.Fatalf("Error creating ssh client to (%s: %s): %v", host.Name, host.Host, err)
+		return nil, fmt.Errorf("failed to connect to host: %w", err)
 	}
 	defer client.Close()
 
-	printLog("Executing command %q on host (%s: %s)\n", command, host.Name, host.Host)
+	slog.Debug("Executing command", logging.Host(host.Name, host.Host), "command", command)
 	return client.Run(command)
 }
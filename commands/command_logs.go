@@ -0,0 +1,322 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/logging"
+	"github.com/vmware/etcd-recovery/pkg/task"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// logSourceWidth is the width of the longest log source, an etcd
+// container with its ID shortened to 13 characters.
+const logSourceWidth = len("etcd/") + 13
+
+func NewCommandLogs() *cobra.Command {
+	var (
+		selected []string
+		since    time.Duration
+		follow   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the etcd and kubelet logs of the hosts",
+		Long: `Show the logs of the etcd containers, including the exited ones, and the
+kubelet journal of the hosts, every line prefixed with its host and source.
+
+The logs are merged in the order of their timestamps. With --follow, the logs
+of the newest etcd container and the kubelet journal are followed instead,
+printed as they are written until ctrl+c is pressed.
+`,
+		Example: `  etcd-recovery logs --since 30m
+  etcd-recovery logs --host etcd-vm2 --follow`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since < 0 {
+				return usageErrorf("invalid --since %v, it must not be negative", since)
+			}
+
+			hosts, err := loadHosts()
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+			if len(selected) > 0 {
+				var targets []*config.Host
+				for _, name := range selected {
+					h := findHost(hosts, name)
+					if h == nil {
+						return usageErrorf("unknown host %q, valid hosts are: %v", name, hostNames(hosts))
+					}
+					targets = append(targets, h)
+				}
+				hosts = targets
+			}
+
+			if follow {
+				return followLogs(cmd.OutOrStdout(), hosts, since)
+			}
+			return showLogs(cmd.OutOrStdout(), hosts, since)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&selected, "host", nil, "name or address of a host to show the logs of, repeat for several hosts (default all hosts)")
+	cmd.Flags().DurationVar(&since, "since", time.Hour, "only show the logs written in this duration, 0 for all the logs")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "follow the logs of the newest etcd container and of kubelet")
+
+	return cmd
+}
+
+// logLine is a line of the logs of a host.
+type logLine struct {
+	Time   time.Time
+	Prefix string
+	Text   string
+}
+
+// showLogs writes the logs of hosts to w, merged in the order of their
+// timestamps. The hosts which can't be read are skipped, and reported in
+// the error once the logs of the others are written.
+func showLogs(w io.Writer, hosts []*config.Host, since time.Duration) error {
+	width := hostWidth(hosts)
+
+	var (
+		lines []logLine
+		errs  []error
+	)
+	for _, h := range hosts {
+		client, err := connect(h)
+		if err != nil {
+			slog.Warn("Failed to read logs", logging.Host(h.Name, h.Host), "err", err)
+			errs = append(errs, fmt.Errorf("%s: failed to connect: %w", h.Name, err))
+			continue
+		}
+		hostLines, hostErrs := readHostLogs(client, h.Name, width, since)
+		client.Close()
+		lines = append(lines, hostLines...)
+		errs = append(errs, hostErrs...)
+	}
+
+	for _, l := range mergeLogLines(lines) {
+		fmt.Fprintf(w, "%s %s\n", l.Prefix, l.Text)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to read some of the logs: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// readHostLogs reads the logs of every etcd container, from the oldest
+// to the newest, and the kubelet journal of the host client is connected
+// to.
+func readHostLogs(client transport.Transport, host string, width int, since time.Duration) ([]logLine, []error) {
+	var (
+		lines []logLine
+		errs  []error
+	)
+	read := func(source, cmd string) {
+		res, err := client.Exec(cmd, transport.ExecOptions{})
+		if err == nil {
+			err = res.Err()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to read %s logs: %w", host, source, err))
+			return
+		}
+		lines = append(lines, parseLogLines(logPrefix(host, source, width), res.Stdout)...)
+	}
+
+	ids, err := task.EtcdContainerIDs(client)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", host, err))
+	}
+	for i := len(ids) - 1; i >= 0; i-- {
+		read(etcdLogSource(ids[i]), task.ContainerLogsCommand(ids[i], since, false))
+	}
+	read("kubelet", task.KubeletLogsCommand(since, false))
+	return lines, errs
+}
+
+// followLogs writes the logs of the newest etcd container and of kubelet
+// of every host to w as they are written, until they end.
+func followLogs(w io.Writer, hosts []*config.Host, since time.Duration) error {
+	width := hostWidth(hosts)
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	for _, h := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var err error
+			client, cErr := connect(h)
+			if cErr != nil {
+				err = fmt.Errorf("%s: failed to connect: %w", h.Name, cErr)
+			} else {
+				err = followHostLogs(client, h.Name, width, since, &mu, w)
+				client.Close()
+			}
+			if err != nil {
+				slog.Warn("Failed to follow logs", logging.Host(h.Name, h.Host), "err", err)
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to follow some of the logs: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// followHostLogs follows the logs of the newest etcd container and of
+// kubelet on the host client is connected to. The lines are written to w
+// while holding mu, as the other hosts write to it too.
+func followHostLogs(client transport.Transport, host string, width int, since time.Duration, mu *sync.Mutex, w io.Writer) error {
+	type source struct{ name, cmd string }
+	sources := []source{{"kubelet", task.KubeletLogsCommand(since, true)}}
+
+	ids, err := task.EtcdContainerIDs(client)
+	if err != nil {
+		return fmt.Errorf("%s: %w", host, err)
+	}
+	if len(ids) > 0 {
+		sources = append([]source{{etcdLogSource(ids[0]), task.ContainerLogsCommand(ids[0], since, true)}}, sources...)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(sources))
+	)
+	for i, s := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			out := &prefixWriter{prefix: logPrefix(host, s.name, width), mu: mu, w: w}
+			res, err := client.Exec(s.cmd, transport.ExecOptions{Stdout: out})
+			out.Flush()
+			if err == nil {
+				err = res.Err()
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: failed to follow %s logs: %w", host, s.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// logTimeLayouts are the layouts of the timestamps of crictl logs and of
+// journalctl -o short-iso-precise, whose time zone depends on its version.
+var logTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999-0700"}
+
+// parseLogLines splits out into lines prefixed with prefix. The lines
+// without a timestamp, e.g. continuation lines, take the time of the
+// line before so that they stay together when merged.
+func parseLogLines(prefix string, out []byte) []logLine {
+	var (
+		lines []logLine
+		last  time.Time
+	)
+	for _, text := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		text = strings.TrimRight(text, "\r")
+		if text == "" {
+			continue
+		}
+		ts, _, _ := strings.Cut(text, " ")
+		for _, layout := range logTimeLayouts {
+			if t, err := time.Parse(layout, ts); err == nil {
+				last = t
+				break
+			}
+		}
+		lines = append(lines, logLine{Time: last, Prefix: prefix, Text: text})
+	}
+	return lines
+}
+
+// mergeLogLines sorts the lines of all sources by time, keeping the
+// lines with the same time in the order of their sources.
+func mergeLogLines(lines []logLine) []logLine {
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Time.Before(lines[j].Time)
+	})
+	return lines
+}
+
+func etcdLogSource(containerID string) string {
+	if len(containerID) > 13 {
+		containerID = containerID[:13]
+	}
+	return "etcd/" + containerID
+}
+
+func logPrefix(host, source string, width int) string {
+	return fmt.Sprintf("%-*s %-*s |", width, host, logSourceWidth, source)
+}
+
+func hostWidth(hosts []*config.Host) int {
+	width := 0
+	for _, h := range hosts {
+		width = max(width, len(h.Name))
+	}
+	return width
+}
+
+// prefixWriter writes the complete lines written to it to w, prefixed
+// with prefix, while holding mu.
+type prefixWriter struct {
+	prefix string
+	mu     *sync.Mutex
+	w      io.Writer
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	i := bytes.LastIndexByte(p.buf, '\n')
+	if i < 0 {
+		return len(b), nil
+	}
+	p.writeLines(string(p.buf[:i]))
+	p.buf = p.buf[i+1:]
+	return len(b), nil
+}
+
+// Flush writes the last line if it doesn't end with a newline.
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		p.writeLines(string(p.buf))
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLines(s string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, line := range strings.Split(s, "\n") {
+		fmt.Fprintf(p.w, "%s %s\n", p.prefix, strings.TrimRight(line, "\r"))
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommandPreflight() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preflight",
+		Short: "Check that every host is ready for a repair",
+		Long: `Check that every host of the hosts config file is ready for a repair: it is
+reachable over SSH, sudo doesn't ask for a password, crictl and kubelet are
+installed, the backed up manifest exists and has an etcd container, and the
+filesystem of the etcd data directory has enough free space. The clocks and the
+etcd images of the hosts are then compared.
+
+Every check passes, warns or fails. The command fails if any check fails. The
+same checks run before repair, unless --skip-preflight is given.
+`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hosts, err := loadHosts()
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			results := preflight(hosts)
+			if err := writePreflight(cmd.OutOrStdout(), results); err != nil {
+				return err
+			}
+			return preflightError(results)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/etcd-recovery/pkg/cliui"
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/logging"
+	"github.com/vmware/etcd-recovery/pkg/plan"
+	"github.com/vmware/etcd-recovery/pkg/reconcile"
+	"github.com/vmware/etcd-recovery/pkg/task"
+)
+
+func NewCommandReconcile() *cobra.Command {
+	var noDashboard bool
+
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Converge the etcd membership to the hosts config",
+		Long: `Converge the etcd membership to the hosts config file, e.g. after partial recoveries.
+The members which aren't in the hosts config are removed, the peer URLs of the
+hosts whose address changed are updated, and the hosts without a member are
+added. The changes are applied once confirmed, in an order keeping the quorum.
+`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hosts, err := loadHostsRecorded()
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+			if err = validateParams(hosts, "reconcile"); err != nil {
+				return fmt.Errorf("failed to validate params: %w", err)
+			}
+			return reconcileMembers(hosts, !noDashboard)
+		},
+	}
+
+	cmd.Flags().BoolVar(&noDashboard, "no-dashboard", false, "print plain logs instead of the progress dashboard shown when stdout is a terminal")
+
+	return cmd
+}
+
+// reconcileMembers converges the membership to hosts through the leader,
+// or a voter following it, once the changes are confirmed.
+func reconcileMembers(hosts []*config.Host, dashboard bool) error {
+	probes := probeHosts(hosts)
+	q := task.CheckQuorum(probes)
+	slog.Info("Checked quorum", "voters", len(q.Voters), "needed", q.Needed(), "agreeing", q.Agreeing, "leader", fmt.Sprintf("%x", q.Leader))
+	if !q.Intact() {
+		return quorumLost(q, "the membership can't be changed safely")
+	}
+	master := leaderHost(hosts, probes, q)
+
+	actions, err := reconcilePlan(master, hosts)
+	if err != nil {
+		return err
+	}
+	if len(actions) == 0 {
+		fmt.Println("The members match the hosts config, there is nothing to reconcile.")
+		return nil
+	}
+
+	ok, err := cliui.Review("reconcile", "Apply these membership changes, through "+master.Name+"?", planText(actions))
+	if err != nil {
+		return fmt.Errorf("failed to confirm the membership changes: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: membership changes not confirmed", cliui.ErrUserCancelled)
+	}
+
+	targets := []*config.Host{master}
+	for _, a := range actions {
+		if a.Type == reconcile.AddMember {
+			targets = append(targets, a.Host)
+		}
+	}
+	p := &plan.ExecutionPlan{
+		Name:  "Reconcile",
+		Steps: reconcileSteps(hosts, master, actions),
+	}
+	summary, err := runPlan(p, "Reconcile members", targets, dashboard)
+	if summary != nil {
+		fmt.Print(summary)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reconcile members: %w", err)
+	}
+
+	slog.Info("Members reconciled")
+	return nil
+}
+
+// reconcilePlan lists the members and their health on master, and
+// returns the actions converging them to hosts.
+func reconcilePlan(master *config.Host, hosts []*config.Host) ([]*reconcile.Action, error) {
+	client, err := connect(master)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", master.Name, err)
+	}
+	defer client.Close()
+
+	containerID, err := task.EtcdContainerID(client)
+	if err != nil {
+		return nil, err
+	}
+	members, err := task.ListMembers(client, containerID)
+	if err != nil {
+		return nil, err
+	}
+	healthy, err := task.HealthyMembers(client, containerID)
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("Listed members", logging.Host(master.Name, master.Host), "members", len(members), "healthy", len(healthy))
+
+	return reconcile.Plan(members, healthy, hosts)
+}
+
+func planText(actions []*reconcile.Action) string {
+	var sb strings.Builder
+	for i, a := range actions {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, a)
+	}
+	return sb.String()
+}
+
+// reconcileSteps returns the steps applying actions through master, one
+// after another.
+func reconcileSteps(allHosts []*config.Host, master *config.Host, actions []*reconcile.Action) []*plan.Step {
+	steps := make([]*plan.Step, 0, len(actions))
+	for _, a := range actions {
+		var step *plan.Step
+		switch a.Type {
+		case reconcile.RemoveLearner, reconcile.RemoveMember:
+			step = &plan.Step{
+				Name:  fmt.Sprintf("%s-%x", a.Type, a.MemberID),
+				Host:  master,
+				Tasks: []task.Task{&task.RemoveMemberTask{Description: "Remove member", MemberID: a.MemberID}},
+			}
+		case reconcile.UpdatePeerURL:
+			step = &plan.Step{
+				Name:   fmt.Sprintf("%s-%s", a.Type, a.Host.Name),
+				Host:   master,
+				Target: a.Host,
+				Tasks:  []task.Task{&task.UpdatePeerURLTask{Description: "Update peer URL", MemberID: a.MemberID, PeerURL: a.NewPeerURL, Member: a.Host}},
+			}
+		case reconcile.AddMember:
+			step = addMemberStep(allHosts, master, a.Host, false)
+		}
+		if len(steps) > 0 {
+			step.DependsOn = []string{steps[len(steps)-1].Name}
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
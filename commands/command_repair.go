@@ -5,21 +5,48 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"slices"
 
 	"github.com/spf13/cobra"
 
 	"github.com/vmware/etcd-recovery/pkg/cliui"
 	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/logging"
 	"github.com/vmware/etcd-recovery/pkg/plan"
+	"github.com/vmware/etcd-recovery/pkg/report"
 	"github.com/vmware/etcd-recovery/pkg/task"
 )
 
-var validModes = []string{"add", "create", "both"}
+var validModes = []string{"add", "create", "both", "replace"}
+
+// repairOptions are the options of a repair given on the command line.
+type repairOptions struct {
+	mode      string
+	dashboard bool
+	// continueOnError moves on to the next member when adding one fails,
+	// instead of stopping.
+	continueOnError bool
+	// seedFallback creates the single-member cluster from the host with
+	// the next highest commit index when it fails from the selected one.
+	seedFallback bool
+	// force creates a single-member cluster even if the quorum is intact.
+	force bool
+	// member is the name of the host replaced in mode replace.
+	member string
+	// skipPreflight doesn't run the pre-flight checks before the repair.
+	skipPreflight bool
+}
 
 func NewCommandRepair() *cobra.Command {
-	var repairMode string
+	var (
+		opts        repairOptions
+		noDashboard bool
+		reportDir   string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "repair",
@@ -29,82 +56,291 @@ Supported Modes:
   - add: Add a new member to an existing cluster
   - create: Creates a single-member etcd cluster
   - both: Run both create and add actions sequentially
+  - replace: Replace a single failed member of a cluster which still has quorum
 `,
-		Args: cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
-			hosts, err := config.ParseHostFromFile(configFile)
-			if err != nil {
-				log.Fatalf("failed to parse hosts file: %v", err)
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(validModes, opts.mode) {
+				return usageErrorf("invalid repair mode %q, valid modes are: %v", opts.mode, validModes)
 			}
-			if err = validateParams(hosts, repairMode); err != nil {
-				log.Fatalf("failed to validate params: %v", err)
+			creating := opts.mode == "create" || opts.mode == "both"
+			if opts.continueOnError && opts.mode != "add" && opts.mode != "both" {
+				return usageErrorf("--continue-on-error only applies to the modes adding members: [add both]")
 			}
+			if opts.seedFallback && !creating {
+				return usageErrorf("--seed-fallback only applies to the modes creating a single-member cluster: [create both]")
+			}
+			if opts.force && !creating {
+				return usageErrorf("--force only applies to the modes creating a single-member cluster: [create both]")
+			}
+			if (opts.member != "") != (opts.mode == "replace") {
+				return usageErrorf("--member is required by, and only applies to, the replace mode")
+			}
+			opts.dashboard = !noDashboard
 
-			printLog("Repair with mode %s, all hosts: %v", repairMode, createOptions(hosts))
-			switch repairMode {
-			case "add":
-				masterMember := mustSelectMember(hosts, "Select the initial member used to create the single-member cluster:")
-				memberToAdd := mustSelectMember(getRemainingMembers(hosts, masterMember), "Select a learner member to add to the cluster:")
-				mustAddMemberToCluster(hosts, masterMember, memberToAdd)
-			case "create":
-				masterMember := mustSelectMember(hosts, "Select the member with the highest commit index to recover the cluster:")
-				mustCreateSingleMemberCluster(masterMember)
-			case "both":
-				masterMember := mustSelectMember(hosts, "Select the member with the highest commit index to recover the cluster:")
-				mustCreateSingleMemberCluster(masterMember)
-
-				remainingHosts := getRemainingMembers(hosts, masterMember)
-				for i, h := range remainingHosts {
-					printLog("Adding member %d/%d: %s (%s)", i+1, len(remainingHosts), h.Name, h.Host)
-					mustAddMemberToCluster(hosts, masterMember, h)
+			hosts, err := loadHostsRecorded()
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+			if err = validateParams(hosts, opts.mode); err != nil {
+				return fmt.Errorf("failed to validate params: %w", err)
+			}
+			if !opts.skipPreflight {
+				slog.Info("Running pre-flight checks")
+				results := preflight(hosts)
+				if err := preflightError(results); err != nil {
+					if wErr := writePreflight(cmd.OutOrStdout(), results); wErr != nil {
+						return wErr
+					}
+					return fmt.Errorf("%w, fix them or run again with --skip-preflight", err)
 				}
-			default:
-				log.Fatalf("Invalid repair mode: %s, , valid modes are %v", repairMode, validModes)
 			}
+
+			slog.Debug("Repairing cluster", "mode", opts.mode, "hosts", createOptions(hosts))
+			r := report.New(opts.mode)
+			err = repair(r, hosts, opts)
+			writeReport(r, reportDir, err)
+			if err != nil {
+				return fmt.Errorf("failed to repair cluster: %w", err)
+			}
+			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&repairMode, "mode", "m", "both", fmt.Sprintf("etcd cluster repair mode, valid modes are: %v", validModes))
+	cmd.Flags().StringVarP(&opts.mode, "mode", "m", "both", fmt.Sprintf("etcd cluster repair mode, valid modes are: %v", validModes))
+	cmd.Flags().BoolVar(&noDashboard, "no-dashboard", false, "print plain logs instead of the progress dashboard shown when stdout is a terminal")
+	cmd.Flags().StringVar(&opts.member, "member", "", "name or address of the host to replace in the replace mode, as in the hosts config file")
+	cmd.Flags().BoolVar(&opts.force, "force", false, "create a single-member cluster even if a quorum of voters is still reachable and agrees on a leader")
+	cmd.Flags().BoolVar(&opts.continueOnError, "continue-on-error", false, "when adding a member fails, remove it from the cluster and go on with the next one, instead of stopping")
+	cmd.Flags().BoolVar(&opts.seedFallback, "seed-fallback", false, "when etcd fails to start on the seed, restore its manifest and create the cluster from the host with the next highest commit index")
+	cmd.Flags().BoolVar(&opts.skipPreflight, "skip-preflight", false, "don't run the pre-flight checks of the preflight command before the repair")
+	cmd.Flags().StringVar(&reportDir, "report-dir", stateDir("reports"), "directory to write the Markdown and JSON recovery report to, empty to disable")
 
 	return cmd
 }
 
+// repair repairs the cluster of hosts in mode, recording it in r. The
+// members are verified once the plan has run, even if it failed.
+func repair(r *report.Report, hosts []*config.Host, opts repairOptions) error {
+	var (
+		seed            *config.Host
+		learners        []*config.Host
+		member          *config.Host
+		expectedMembers int
+		err             error
+	)
+	switch opts.mode {
+	case "add":
+		if seed, err = selectMember(createOptions(hosts), hosts, "Select the initial member used to create the single-member cluster:"); err != nil {
+			return err
+		}
+		if learners, err = selectMembers(getRemainingMembers(hosts, seed), "Select the learner members to add to the cluster:"); err != nil {
+			return err
+		}
+	case "create", "both":
+		if err = checkQuorum(hosts, opts.force); err != nil {
+			return err
+		}
+		readCommitIndexes(r, hosts)
+		if seed, err = selectMember(seedOptions(r, hosts), hosts, "Select the member with the highest commit index to recover the cluster:"); err != nil {
+			return err
+		}
+		expectedMembers = 1
+		if opts.mode == "both" {
+			expectedMembers = len(hosts)
+		}
+	case "replace":
+		if member = findHost(hosts, opts.member); member == nil {
+			return usageErrorf("unknown member %q, it isn't in the hosts config file", opts.member)
+		}
+		if seed, err = healthyMember(hosts, member); err != nil {
+			return err
+		}
+		expectedMembers = len(hosts)
+	default:
+		return fmt.Errorf("invalid repair mode %q, valid modes are: %v", opts.mode, validModes)
+	}
+
+	setSeed(r, seed, opts.mode)
+	snapshotMembers(r, seed)
+
+	candidates := []*config.Host{seed}
+	if opts.seedFallback {
+		candidates = seedCandidates(r, hosts, seed)
+	}
+
+	switch opts.mode {
+	case "add":
+		err = addMembersToCluster(r, hosts, seed, learners, opts)
+	case "create":
+		seed, err = createSingleMemberCluster(r, candidates, opts)
+	case "both":
+		seed, err = repairCluster(r, hosts, candidates, opts)
+	case "replace":
+		err = replaceMember(r, hosts, seed, member, opts)
+	}
+	if len(r.AbandonedSeeds) > 0 {
+		setSeed(r, seed, opts.mode)
+	}
+
+	verifyCluster(r, seed, expectedMembers)
+	return err
+}
+
 func validateParams(hosts []*config.Host, mode string) error {
 	if len(hosts) == 0 {
-		return fmt.Errorf("hosts.json should contain at least one Host, got: %d", len(hosts))
+		return fmt.Errorf("%w: hosts.json should contain at least one Host, got: %d", config.ErrInvalidConfig, len(hosts))
 	}
 
-	if mode == "add" {
+	if mode == "add" || mode == "replace" {
 		if len(hosts) == 1 {
-			return fmt.Errorf("hosts.json should contain at least two Host in 'add' mode, got: %d", len(hosts))
+			return fmt.Errorf("%w: hosts.json should contain at least two Host in '%s' mode, got: %d", config.ErrInvalidConfig, mode, len(hosts))
 		}
 	}
 
 	return nil
 }
 
-func mustCreateSingleMemberCluster(selectedHost *config.Host) {
-	printLog("Creating a single-member cluster from %s (%s)", selectedHost.Name, selectedHost.Host)
+// createSingleMemberCluster creates a single-member cluster from the
+// first candidate. With --seed-fallback, the next candidate is tried when
+// it fails, once the manifest of the failed one has been restored. It
+// returns the last candidate tried.
+func createSingleMemberCluster(r *report.Report, candidates []*config.Host, opts repairOptions) (*config.Host, error) {
+	var (
+		seed *config.Host
+		err  error
+	)
+	for i, c := range candidates {
+		seed = c
+		slog.Info("Creating a single-member cluster", logging.Host(seed.Name, seed.Host), "attempt", i+1, "candidates", len(candidates))
+
+		p := &plan.ExecutionPlan{
+			Name:      "CreateSingleMemberCluster",
+			Steps:     []*plan.Step{createSeedStep(seed, opts.seedFallback)},
+			Observers: []event.Observer{r},
+		}
+		if _, err = runPlan(p, "Create single-member cluster", []*config.Host{seed}, opts.dashboard); err == nil {
+			slog.Info("Single-member cluster created", logging.Host(seed.Name, seed.Host))
+			return seed, nil
+		}
+		err = fmt.Errorf("failed to create single-member cluster from %s: %w", seed.Name, err)
+
+		if i == len(candidates)-1 || errors.Is(err, task.ErrManifestNotRestored) || errors.Is(err, cliui.ErrUserCancelled) {
+			break
+		}
+		slog.Warn("Abandoning the seed, falling back to the next one", logging.Host(seed.Name, seed.Host), "err", err, "next", candidates[i+1].Name)
+		r.AbandonedSeeds = append(r.AbandonedSeeds, report.AbandonedSeed{Name: seed.Name, Address: seed.Host, Error: err.Error()})
+	}
+	return seed, err
+}
+
+// repairCluster creates a single-member cluster from the first candidate
+// and then adds the remaining members one by one. A member is only added
+// once the previous one has been promoted, or has failed with
+// --continue-on-error. It returns the seed the cluster was created from.
+func repairCluster(r *report.Report, allHosts []*config.Host, candidates []*config.Host, opts repairOptions) (*config.Host, error) {
+	if len(candidates) > 1 {
+		// The seed isn't known until the cluster is created.
+		master, err := createSingleMemberCluster(r, candidates, opts)
+		if err != nil {
+			return master, err
+		}
+		return master, addMembersToCluster(r, allHosts, master, getRemainingMembers(allHosts, master), opts)
+	}
+
+	master := candidates[0]
+	slog.Info("Creating a single-member cluster", logging.Host(master.Name, master.Host))
 
-	session := &plan.RemoteSession{
-		Host: selectedHost,
+	seed := createSeedStep(master, opts.seedFallback)
+	learners := getRemainingMembers(allHosts, master)
+	p := &plan.ExecutionPlan{
+		Name:            "RepairCluster",
+		Steps:           []*plan.Step{seed},
+		ContinueOnError: opts.continueOnError,
+		Observers:       []event.Observer{r},
+	}
+	p.Steps = append(p.Steps, addMemberSteps(allHosts, master, learners, seed.Name, opts.continueOnError)...)
+
+	summary, err := runPlan(p, "Repair etcd cluster", allHosts, opts.dashboard)
+	if summary != nil {
+		fmt.Print(summary)
+		if opts.continueOnError {
+			fmt.Print(membersSummary(summary, master, learners))
+		}
+	}
+	if err != nil {
+		return master, err
+	}
+
+	slog.Info("Cluster repaired")
+	return master, nil
+}
+
+// createSeedStep returns a step creating a single-member cluster from h.
+// The manifest of h is restored if it fails and restoreOnFailure is set.
+func createSeedStep(h *config.Host, restoreOnFailure bool) *plan.Step {
+	return &plan.Step{
+		Name: fmt.Sprintf("create-single-member-cluster-%s", h.Name),
+		Host: h,
 		Tasks: []task.Task{
 			&task.CreateSingleMemberClusterTask{
-				Description:    "CreateSingleMemberCluster",
-				BackupManifest: selectedHost.BackedupManifest,
+				Description:      "CreateSingleMemberCluster",
+				BackupManifest:   h.BackedupManifest,
+				RestoreOnFailure: restoreOnFailure,
 			},
 		},
 	}
-	p := &plan.ExecutionPlan{
-		Name:     "CreateSingleMemberCluster",
-		Sessions: []*plan.RemoteSession{session},
-	}
+}
+
+// addMemberStep returns a step adding learner to the cluster via master.
+// It runs on master. The learner is removed from the cluster if it fails
+// and removeOnFailure is set.
+func addMemberStep(allHosts []*config.Host, master, learner *config.Host, removeOnFailure bool) *plan.Step {
+	return &plan.Step{
+		Name:   addMemberStepName(learner),
+		Host:   master,
+		Target: learner,
+		Tasks: []task.Task{
+			&task.AddMemberTask{
+				Description: "Add member workflow",
+				Master:      master,
+				Learner:     learner,
+				AllHosts:    allHosts,
 
-	if err := p.Execute(); err != nil {
-		log.Fatalf("Failed to create single-member cluster: %v", err)
+				RemoveLearnerOnFailure: removeOnFailure,
+			},
+		},
 	}
+}
+
+func addMemberStepName(learner *config.Host) string {
+	return fmt.Sprintf("add-member-%s", learner.Name)
+}
 
-	printLog("Single-member cluster created successfully.")
+// addMemberSteps returns the steps adding learners one after another, the
+// first one after the step named after, if any. With continueOnError, a
+// step starts once the previous one has finished even if it failed, but
+// still only if the step named after succeeded.
+func addMemberSteps(allHosts []*config.Host, master *config.Host, learners []*config.Host, after string, continueOnError bool) []*plan.Step {
+	var steps []*plan.Step
+	prev := after
+	for _, h := range learners {
+		step := addMemberStep(allHosts, master, h, continueOnError)
+		switch {
+		case continueOnError:
+			if after != "" {
+				step.DependsOn = []string{after}
+			}
+			if prev != after {
+				step.After = []string{prev}
+			}
+		case prev != "":
+			step.DependsOn = []string{prev}
+		}
+		steps = append(steps, step)
+		prev = step.Name
+	}
+	return steps
 }
 
 func createOptions(hosts []*config.Host) []string {
@@ -115,53 +351,125 @@ func createOptions(hosts []*config.Host) []string {
 	return options
 }
 
-// mustSelectMember selects a member from a list of hosts. Use cases:
+// selectMember selects a member from a list of hosts, described by
+// options. Use cases:
 //   - Select the member with the highest commit index to recover the cluster, for mode "create" and "both"
 //   - Select the initial member used to create the single-member cluster, for mode "add"
-//   - Select a member to add into the cluster, for mode "add"
-func mustSelectMember(hosts []*config.Host, msg string) *config.Host {
-	options := createOptions(hosts)
+func selectMember(options []string, hosts []*config.Host, msg string) (*config.Host, error) {
 	if len(options) == 0 {
-		log.Fatal("No any hosts to select")
+		return nil, fmt.Errorf("no host to select")
 	}
 
 	learnerIdx, _, err := cliui.Select(
+		"seed",
 		msg,
 		options,
 	)
 	if err != nil {
-		log.Fatalf("Failed to select member: %v", err)
+		return nil, fmt.Errorf("failed to select member: %w", err)
 	}
 
-	return hosts[learnerIdx]
+	return hosts[learnerIdx], nil
 }
 
-func mustAddMemberToCluster(allHosts []*config.Host, master, learner *config.Host) {
-	printLog("Adding learner member %s (%s) to cluster via %s (%s)", learner.Name, learner.Host, master.Name, master.Host)
+// selectMembers selects the members to add into the cluster, for mode "add".
+func selectMembers(hosts []*config.Host, msg string) ([]*config.Host, error) {
+	options := createOptions(hosts)
+	if len(options) == 0 {
+		return nil, fmt.Errorf("no host to select")
+	}
 
-	// Execute workflow on master host to add the learner
-	session := &plan.RemoteSession{
-		Host: master,
-		Tasks: []task.Task{
-			&task.AddMemberTask{
-				Description: "Add member workflow",
-				Master:      master,
-				Learner:     learner,
-				AllHosts:    allHosts,
-			},
-		},
+	idx, err := cliui.MultiSelect("learners", msg, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select members: %w", err)
+	}
+	if len(idx) == 0 {
+		return nil, fmt.Errorf("no member selected")
 	}
 
+	selected := make([]*config.Host, 0, len(idx))
+	for _, i := range idx {
+		selected = append(selected, hosts[i])
+	}
+	return selected, nil
+}
+
+// addMembersToCluster adds the learners to the cluster via master, one
+// after another.
+func addMembersToCluster(r *report.Report, allHosts []*config.Host, master *config.Host, learners []*config.Host, opts repairOptions) error {
+	for _, l := range learners {
+		slog.Info("Adding learner member to the cluster", logging.Host(l.Name, l.Host), "via", master.Name)
+	}
+
+	p := &plan.ExecutionPlan{
+		Name:            "AddMember",
+		Steps:           addMemberSteps(allHosts, master, learners, "", opts.continueOnError),
+		ContinueOnError: opts.continueOnError,
+		Observers:       []event.Observer{r},
+	}
+
+	summary, err := runPlan(p, "Add members", append([]*config.Host{master}, learners...), opts.dashboard)
+	if summary != nil && len(learners) > 1 {
+		fmt.Print(summary)
+		if opts.continueOnError {
+			fmt.Print(membersSummary(summary, master, learners))
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add members to cluster: %w", err)
+	}
+
+	slog.Info("Members added to cluster")
+	return nil
+}
+
+// replaceMember replaces member through the healthy member master: the
+// stale member is removed from the cluster while etcd is stopped on the
+// replacement host, which is then added back as a learner and promoted.
+// The cluster is never forced to a single member.
+func replaceMember(r *report.Report, allHosts []*config.Host, master, member *config.Host, opts repairOptions) error {
+	slog.Info("Replacing member", logging.Host(member.Name, member.Host), "via", master.Name)
+
 	p := &plan.ExecutionPlan{
-		Name:     "AddMember",
-		Sessions: []*plan.RemoteSession{session},
+		Name:      "ReplaceMember",
+		Steps:     replaceMemberSteps(allHosts, master, member),
+		Observers: []event.Observer{r},
 	}
 
-	if err := p.Execute(); err != nil {
-		log.Fatalf("Failed to add member %s (%s) to cluster: %v", learner.Name, learner.Host, err)
+	summary, err := runPlan(p, "Replace member", []*config.Host{master, member}, opts.dashboard)
+	if summary != nil {
+		fmt.Print(summary)
 	}
+	if err != nil {
+		return fmt.Errorf("failed to replace member %s: %w", member.Name, err)
+	}
+
+	slog.Info("Member replaced", logging.Host(member.Name, member.Host))
+	return nil
+}
 
-	printLog("Member added to cluster successfully.")
+// replaceMemberSteps returns the steps replacing member through master.
+// The stale member is removed and etcd is stopped on the replacement
+// host in parallel, before the host is added back.
+func replaceMemberSteps(allHosts []*config.Host, master, member *config.Host) []*plan.Step {
+	remove := &plan.Step{
+		Name:   fmt.Sprintf("remove-member-%s", member.Name),
+		Host:   master,
+		Target: member,
+		Tasks: []task.Task{
+			&task.RemoveMemberTask{Description: "Remove stale member", Member: member},
+		},
+	}
+	stop := &plan.Step{
+		Name: fmt.Sprintf("stop-etcd-%s", member.Name),
+		Host: member,
+		Tasks: []task.Task{
+			&task.StopEtcdTask{Description: "Stop etcd"},
+		},
+	}
+	add := addMemberStep(allHosts, master, member, false)
+	add.DependsOn = []string{remove.Name, stop.Name}
+	return []*plan.Step{remove, stop, add}
 }
 
 func getRemainingMembers(hosts []*config.Host, masterHost *config.Host) []*config.Host {
@@ -5,7 +5,15 @@
 package commands
 
 import (
+	"log/slog"
+	"os"
+	"path/filepath"
+
 	"github.com/spf13/cobra"
+
+	"github.com/vmware/etcd-recovery/pkg/cliui"
+	"github.com/vmware/etcd-recovery/pkg/logging"
+	"github.com/vmware/etcd-recovery/pkg/redact"
 )
 
 const (
@@ -16,25 +24,112 @@ const (
 var (
 	configFile string
 	verbose    bool
+	local      bool
+
+	logLevel  string
+	logFormat string
+
+	answersFile    string
+	answers        []string
+	nonInteractive bool
+
+	transcriptDir    string
+	transcriptFormat string
 
 	rootCmd = &cobra.Command{
 		Use:   cliName,
 		Short: cliDescription,
+		// The errors are logged by Execute, and mapped to exit codes.
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := setupLogging(nil); err != nil {
+				return &usageError{err: err}
+			}
+			if err := setupPrompter(); err != nil {
+				return &usageError{err: err}
+			}
+			return nil
+		},
 	}
 )
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "hosts.json", "path to etcd cluster hosts config file")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output, same as --log-level debug")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "minimum level of the logs, valid levels are: [debug info warn error]")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logging.FormatText, "format of the logs, valid formats are: [text json]")
+	rootCmd.PersistentFlags().BoolVar(&local, "local", false, "run commands directly on this machine instead of over SSH for the host in the config file that it belongs to")
+	rootCmd.PersistentFlags().StringVar(&answersFile, "answers", "", "path to a JSON file with the answers to the prompts, keyed by prompt name")
+	rootCmd.PersistentFlags().StringArrayVar(&answers, "answer", nil, "answer to a prompt as key=value, e.g. --answer seed=etcd-vm1 (can be repeated)")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "never prompt, and fail if a prompt has no answer")
+	rootCmd.PersistentFlags().StringVar(&transcriptDir, "transcript-dir", stateDir("transcripts"), "directory to write the transcript of the actions taken on the hosts to, a new file per run, empty to disable")
+	rootCmd.PersistentFlags().StringVar(&transcriptFormat, "transcript-format", "json", "format of the transcript, valid formats are: [json text]")
+
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
 
 	rootCmd.AddCommand(
 		NewCommandVersion(),
 		NewCommandSelect(),
 		NewCommandRepair(),
+		NewCommandReconcile(),
+		NewCommandStatus(),
+		NewCommandPreflight(),
 		NewCommandExecute(),
+		NewCommandEtcdctl(),
+		NewCommandLogs(),
+		NewCommandSupportBundle(),
 	)
 }
 
+// stateDir returns the default directory of the files named name written
+// by the runs, such as the transcripts and the reports, under
+// $XDG_STATE_HOME/etcd-recovery or ~/.local/state/etcd-recovery. It is
+// relative to the working directory if the home directory is unknown.
+func stateDir(name string) string {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return name
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, cliName, name)
+}
+
+// setupLogging configures the default logger from the flags. The
+// records are masked by r, or only for the values that look like secrets
+// if r is nil.
+func setupLogging(r *redact.Redactor) error {
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	if verbose {
+		level = slog.LevelDebug
+	}
+	return logging.Setup(logging.Options{Level: level, Format: logFormat, Redactor: r})
+}
+
+// setupPrompter answers the prompts from --answers and --answer first, and
+// asks the user the remaining ones unless --non-interactive is set.
+func setupPrompter() error {
+	scripted, err := parseAnswers(answersFile, answers)
+	if err != nil {
+		return err
+	}
+
+	var fallback cliui.Prompter
+	if !nonInteractive {
+		fallback = cliui.DefaultPrompter()
+	}
+	cliui.SetPrompter(cliui.NewScriptedPrompter(scripted, fallback))
+	return nil
+}
+
 func RootCmd() *cobra.Command {
 	return rootCmd
 }
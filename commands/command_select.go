@@ -5,15 +5,17 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/vmware/etcd-recovery/pkg/config"
-	"github.com/vmware/etcd-recovery/pkg/ssh"
+	"github.com/vmware/etcd-recovery/pkg/logging"
+	"github.com/vmware/etcd-recovery/pkg/transport"
 )
 
 // NewCommandSelect selects the best member for cluster recovery.
@@ -27,14 +29,14 @@ func NewCommandSelect() *cobra.Command {
 	return &cobra.Command{
 		Use:   "select",
 		Short: "Select the best member to recover the cluster from",
-		Run:   selectCommandFunc,
+		RunE:  selectCommandFunc,
 	}
 }
 
-func selectCommandFunc(cmd *cobra.Command, args []string) {
-	hostCfg, err := config.ParseHostFromFile(configFile)
+func selectCommandFunc(cmd *cobra.Command, args []string) error {
+	hostCfg, err := loadHostsRecorded()
 	if err != nil {
-		log.Fatalf("Error parsing hosts config file: %v", err)
+		return fmt.Errorf("failed to parse hosts config file: %w", err)
 	}
 
 	var (
@@ -42,43 +44,16 @@ func selectCommandFunc(cmd *cobra.Command, args []string) {
 		maxCommitIndex int
 	)
 	for _, h := range hostCfg {
-		printLog("Connecting to host (%s: %s)\n", h.Name, h.Host)
-
-		client, err := ssh.NewClient(&ssh.Config{
-			User:                 h.Username,
-			Host:                 h.Host,
-			Password:             h.Password,
-			PrivateKeyPath:       h.PrivateKey,
-			PrivateKeyPassphrase: h.Passphrase,
-		})
+		commitIndex, err := readCommitIndex(h)
 		if err != nil {
-			log.Printf("Error creating ssh client to (%s: %s): %v\n", h.Name, h.Host, err)
-			continue
-		}
-
-		targetPath := getTargetPath(h.Username)
-		_, err = client.Run(fmt.Sprintf("%s version", targetPath))
-		if err != nil {
-			printLog("Uploading etcd-diagnosis to %s on host (%s: %s)\n", targetPath, h.Name, h.Host)
-			if uErr := client.Upload("./etcd-diagnosis", targetPath); uErr != nil {
-				log.Fatalf("Error uploading etcd-diagnosis to %s on (%v: %v): %v", targetPath, h.Name, h.Host, uErr)
+			if errors.Is(err, errUploadDiagnosis) {
+				return fmt.Errorf("failed to read commit index of %s: %w", h.Name, err)
 			}
-		}
-
-		commitIndexCmd := fmt.Sprintf("sudo %s commit-index /var/lib/etcd", targetPath)
-		resp, err := client.Run(commitIndexCmd)
-		if err != nil {
-			// The directory /var/lib/etcd might have already been removed.
-			log.Printf("Error running etcd-diagnosis on (%v: %v), output:\n %s\n error:\n %v\n", h.Name, h.Host, string(resp), err)
+			slog.Error("Failed to read commit index", logging.Host(h.Name, h.Host), "err", err)
 			continue
 		}
 
-		commitIndex, err := strconv.Atoi(strings.TrimSpace(string(resp)))
-		if err != nil {
-			log.Fatalf("Error converting commit index to int (%v: %v): %v", h.Name, h.Host, err)
-		}
-
-		printLog("Member (%s: %s), Commit index: %d\n", h.Name, h.Host, commitIndex)
+		slog.Info("Read commit index", logging.Host(h.Name, h.Host), "commit_index", commitIndex)
 
 		if commitIndex > maxCommitIndex {
 			maxCommitIndex = commitIndex
@@ -92,6 +67,57 @@ func selectCommandFunc(cmd *cobra.Command, args []string) {
 	for _, h := range bestHosts {
 		fmt.Printf("- %s: %s\n", h.Name, h.Host)
 	}
+	return nil
+}
+
+// errUploadDiagnosis is returned by readCommitIndex when etcd-diagnosis
+// can't be uploaded to a host.
+var errUploadDiagnosis = errors.New("failed to upload etcd-diagnosis")
+
+// readCommitIndex reads the commit index of the etcd data of h with
+// etcd-diagnosis, which is uploaded first if it isn't on h yet.
+func readCommitIndex(h *config.Host) (int, error) {
+	slog.Debug("Connecting to host", logging.Host(h.Name, h.Host))
+
+	client, err := connect(h)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to host: %w", err)
+	}
+	defer client.Close()
+
+	targetPath, err := ensureDiagnosis(client, h)
+	if err != nil {
+		return 0, err
+	}
+
+	commitIndexCmd := fmt.Sprintf("sudo %s commit-index /var/lib/etcd", targetPath)
+	res, err := client.Exec(commitIndexCmd, transport.ExecOptions{})
+	if err == nil {
+		err = res.Err()
+	}
+	if err != nil {
+		// The directory /var/lib/etcd might have already been removed.
+		return 0, fmt.Errorf("failed to run etcd-diagnosis: %w", err)
+	}
+
+	commitIndex, err := strconv.Atoi(strings.TrimSpace(string(res.Stdout)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse commit index: %w", err)
+	}
+	return commitIndex, nil
+}
+
+// ensureDiagnosis uploads etcd-diagnosis to h unless it is already there,
+// and returns its path on h.
+func ensureDiagnosis(client transport.Transport, h *config.Host) (string, error) {
+	targetPath := getTargetPath(h.Username)
+	if _, err := client.Run(fmt.Sprintf("%s version", targetPath)); err != nil {
+		slog.Info("Uploading etcd-diagnosis", logging.Host(h.Name, h.Host), "path", targetPath)
+		if uErr := client.Upload("./etcd-diagnosis", targetPath); uErr != nil {
+			return "", fmt.Errorf("%w to %s: %w", errUploadDiagnosis, targetPath, uErr)
+		}
+	}
+	return targetPath, nil
 }
 
 func getTargetPath(user string) string {
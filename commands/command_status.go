@@ -0,0 +1,257 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/etcd-recovery/pkg/cliui"
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/task"
+)
+
+var validStatusFormats = []string{"table", "json"}
+
+func NewCommandStatus() *cobra.Command {
+	var (
+		format   string
+		watch    bool
+		interval time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the etcd status of every host",
+		Long: `Show the etcd status of every host of the hosts config file: whether it is
+reachable over SSH, the etcd container, the member and its role, the leader,
+the raft term and index, the DB size, the active alarms and whether the
+manifest passes --force-new-cluster.
+
+With --watch, the status is refreshed every --interval until 'q' is pressed,
+highlighting the changes between refreshes: a new leader, the progress of the
+learners, the members appearing or disappearing and the new alarms.
+`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(validStatusFormats, format) {
+				return usageErrorf("invalid format %q, valid formats are: %v", format, validStatusFormats)
+			}
+			if watch && format != "table" {
+				return usageErrorf("--watch only supports the table format")
+			}
+			if interval <= 0 {
+				return usageErrorf("invalid interval %v, it must be positive", interval)
+			}
+
+			hosts, err := loadHosts()
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			if watch {
+				return cliui.Watch("etcd status", interval, newStatusWatcher(hosts).refresh)
+			}
+
+			return writeStatuses(cmd.OutOrStdout(), forEachHost(hosts, readHostStatus), format)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "table", fmt.Sprintf("output format, valid formats are: %v", validStatusFormats))
+	cmd.Flags().BoolVar(&watch, "watch", false, "refresh the status until 'q' is pressed, highlighting the changes")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "time between two refreshes with --watch")
+
+	return cmd
+}
+
+// hostStatus is the etcd status of a host.
+type hostStatus struct {
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	Reachable bool   `json:"reachable"`
+	Running   bool   `json:"running"`
+
+	ContainerID string `json:"container_id,omitempty"`
+	MemberID    string `json:"member_id,omitempty"`
+	// Role is voter or learner.
+	Role      string   `json:"role,omitempty"`
+	Leader    string   `json:"leader,omitempty"`
+	RaftTerm  uint64   `json:"raft_term,omitempty"`
+	RaftIndex uint64   `json:"raft_index,omitempty"`
+	DBSize    int64    `json:"db_size,omitempty"`
+	Alarms    []string `json:"alarms,omitempty"`
+
+	ForceNewCluster bool `json:"force_new_cluster"`
+
+	// Errors are the reasons the columns above couldn't be filled.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// readHostStatus connects to h and reads its etcd status. The failures
+// are recorded in the status rather than returned.
+func readHostStatus(h *config.Host) *hostStatus {
+	s := &hostStatus{Name: h.Name, Address: h.Host}
+
+	client, err := connect(h)
+	if err != nil {
+		s.Errors = append(s.Errors, err.Error())
+		return s
+	}
+	defer client.Close()
+	s.Reachable = true
+
+	if s.ForceNewCluster, err = task.ManifestForcesNewCluster(client); err != nil {
+		s.Errors = append(s.Errors, fmt.Sprintf("failed to read manifest: %v", err))
+	}
+
+	p, err := task.ProbeEtcd(client)
+	if p != nil {
+		s.Running = true
+		s.ContainerID = p.ContainerID
+	}
+	if err != nil {
+		if !errors.Is(err, task.ErrEtcdNotRunning) {
+			s.Errors = append(s.Errors, err.Error())
+		}
+		return s
+	}
+
+	s.MemberID = fmt.Sprintf("%x", p.MemberID)
+	s.Role = "voter"
+	if p.IsLearner {
+		s.Role = "learner"
+	}
+	if p.Leader != 0 {
+		s.Leader = fmt.Sprintf("%x", p.Leader)
+	}
+	s.RaftTerm = p.RaftTerm
+	s.RaftIndex = p.RaftIndex
+	s.DBSize = p.DBSize
+	s.Alarms = p.Alarms
+	if p.AlarmsErr != nil {
+		s.Errors = append(s.Errors, p.AlarmsErr.Error())
+	}
+	return s
+}
+
+func writeStatuses(w io.Writer, statuses []*hostStatus, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(statusHeader, "\t"))
+	for _, s := range statuses {
+		fmt.Fprintln(tw, strings.Join(statusRow(s), "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, s := range statuses {
+		for _, e := range s.Errors {
+			fmt.Fprintf(w, "%s: %s\n", s.Name, e)
+		}
+	}
+	return nil
+}
+
+var statusHeader = []string{"HOST", "ADDRESS", "SSH", "CONTAINER", "MEMBER", "ROLE", "LEADER", "TERM", "INDEX", "DB SIZE", "ALARMS", "FORCE-NEW-CLUSTER"}
+
+// The columns of statusHeader highlighted by status --watch.
+const (
+	statusSSHColumn    = 2
+	statusMemberColumn = 4
+	statusRoleColumn   = 5
+	statusLeaderColumn = 6
+	statusIndexColumn  = 8
+	statusAlarmsColumn = 10
+)
+
+// statusRow returns the columns of statusHeader for s.
+func statusRow(s *hostStatus) []string {
+	return []string{
+		s.Name,
+		s.Address,
+		yesNo(s.Reachable, "ok", "unreachable"),
+		orDash(containerColumn(s)),
+		orDash(s.MemberID),
+		orDash(s.Role),
+		orDash(leaderColumn(s)),
+		orDash(uintColumn(s.RaftTerm)),
+		orDash(uintColumn(s.RaftIndex)),
+		orDash(formatBytes(s.DBSize)),
+		orDash(strings.Join(s.Alarms, ",")),
+		yesNo(s.ForceNewCluster, "yes", "no"),
+	}
+}
+
+func containerColumn(s *hostStatus) string {
+	switch {
+	case !s.Reachable:
+		return ""
+	case !s.Running:
+		return "not running"
+	case len(s.ContainerID) > 13:
+		return s.ContainerID[:13]
+	default:
+		return s.ContainerID
+	}
+}
+
+func leaderColumn(s *hostStatus) string {
+	if s.Leader != "" && s.Leader == s.MemberID {
+		return s.Leader + " (self)"
+	}
+	return s.Leader
+}
+
+func uintColumn(v uint64) string {
+	if v == 0 {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func yesNo(v bool, yes, no string) string {
+	if v {
+		return yes
+	}
+	return no
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// formatBytes formats n bytes with a binary unit, e.g. "20 KiB".
+func formatBytes(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/etcd-recovery/pkg/audit"
+	"github.com/vmware/etcd-recovery/pkg/bundle"
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/logging"
+	"github.com/vmware/etcd-recovery/pkg/task"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+func NewCommandSupportBundle() *cobra.Command {
+	var (
+		output          string
+		since           time.Duration
+		uploadDiagnosis bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "Collect the etcd artifacts of every host into a tarball",
+		Long: `Collect the artifacts needed to escalate an etcd issue from every host of the
+hosts config file into a single gzipped tarball: the current and backed up etcd
+manifests, a listing of the data directory with sizes, the output of
+etcd-diagnosis, the logs of the etcd containers and of kubelet, the expiry dates
+of the etcd certificates, the disk and memory usage and the time sync status.
+
+The hosts are collected concurrently, and a command collecting an artifact is
+stopped after 2 minutes. etcd-diagnosis is only run if it is already on a host,
+unless --upload-diagnosis is set.
+
+The passwords and passphrases of the hosts config file, private keys and values
+that look like secrets are masked. The hosts which can't be reached are listed
+in errors.txt in the bundle.
+`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since < 0 {
+				return usageErrorf("invalid --since %v, it must not be negative", since)
+			}
+
+			hosts, err := loadHosts()
+			if err != nil {
+				return fmt.Errorf("failed to parse hosts file: %w", err)
+			}
+
+			root := "etcd-recovery-support-" + time.Now().UTC().Format("20060102T150405Z")
+			if output == "" {
+				output = root + ".tar.gz"
+			}
+			if err := writeSupportBundle(output, root, hosts, since, uploadDiagnosis); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Support bundle written to %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "path of the tarball (default etcd-recovery-support-<time>.tar.gz)")
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "only collect the logs written in this duration, 0 for all the logs")
+	cmd.Flags().BoolVar(&uploadDiagnosis, "upload-diagnosis", false, "upload etcd-diagnosis to the hosts which don't have it, as select does")
+
+	return cmd
+}
+
+// artifactTimeout bounds every command collecting an artifact, so that a
+// stuck host can't block the bundle.
+const artifactTimeout = 2 * time.Minute
+
+// writeSupportBundle collects the artifacts of hosts into the tarball at
+// output, under the directory root. The failures to collect an artifact
+// are logged and listed in errors.txt rather than returned.
+func writeSupportBundle(output, root string, hosts []*config.Host, since time.Duration, uploadDiagnosis bool) (err error) {
+	// The secrets are masked, but the bundle still describes the cluster.
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create support bundle: %w", err)
+	}
+	defer func() {
+		if cErr := f.Close(); cErr != nil && err == nil {
+			err = fmt.Errorf("failed to write support bundle: %w", cErr)
+		}
+	}()
+
+	b := bundle.NewWriter(f, root, hostsRedactor(hosts))
+	add := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		return b.Add(name, append(data, '\n'))
+	}
+
+	header, err := audit.NewHeader(configFile)
+	if err != nil {
+		return err
+	}
+	if err := add("header.json", header); err != nil {
+		return err
+	}
+	if err := add("hosts.json", withoutSecrets(hosts)); err != nil {
+		return err
+	}
+
+	// The hosts are collected concurrently, but the bundle is written one
+	// file at a time.
+	var mu sync.Mutex
+	addFile := func(name string, data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return b.Add(name, data)
+	}
+
+	type hostResult struct {
+		errs []string
+		err  error
+	}
+	results := forEachHost(hosts, func(h *config.Host) hostResult {
+		slog.Info("Collecting support bundle artifacts", logging.Host(h.Name, h.Host))
+		client, err := connect(h)
+		if err != nil {
+			slog.Warn("Failed to collect artifacts", logging.Host(h.Name, h.Host), "err", err)
+			return hostResult{errs: []string{fmt.Sprintf("%s: failed to connect: %v", h.Name, err)}}
+		}
+		defer client.Close()
+
+		var r hostResult
+		hostErrs, err := collectHostArtifacts(&timeoutTransport{Transport: client, timeout: artifactTimeout}, h, since, uploadDiagnosis, addFile)
+		for _, e := range hostErrs {
+			slog.Warn("Failed to collect artifact", logging.Host(h.Name, h.Host), "err", e)
+			r.errs = append(r.errs, fmt.Sprintf("%s: %v", h.Name, e))
+		}
+		r.err = err
+		return r
+	})
+
+	var errs []string
+	for _, r := range results {
+		if r.err != nil {
+			return r.err
+		}
+		errs = append(errs, r.errs...)
+	}
+
+	if len(errs) > 0 {
+		if err := b.Add("errors.txt", []byte(strings.Join(errs, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+	return b.Close()
+}
+
+// withoutSecrets returns copies of hosts without the passwords, the
+// passphrases, and the private keys given inline rather than as a path.
+// The bundle is still redacted, in case a secret is found elsewhere.
+func withoutSecrets(hosts []*config.Host) []*config.Host {
+	copies := make([]*config.Host, 0, len(hosts))
+	for _, h := range hosts {
+		c := *h
+		c.Password = ""
+		c.Passphrase = ""
+		if strings.Contains(c.PrivateKey, "PRIVATE KEY") || strings.Contains(c.PrivateKey, "\n") {
+			c.PrivateKey = ""
+		}
+		copies = append(copies, &c)
+	}
+	return copies
+}
+
+// hostArtifact is a file of the support bundle holding the output of
+// Command on a host.
+type hostArtifact struct {
+	Name    string
+	Command string
+}
+
+// hostArtifacts returns the artifacts read with a command on every host.
+func hostArtifacts(since time.Duration) []hostArtifact {
+	return []hostArtifact{
+		{Name: "data-dir.txt", Command: "sudo ls -lR --time-style=full-iso /var/lib/etcd; sudo du -ab /var/lib/etcd"},
+		{Name: "containers.txt", Command: "sudo crictl ps -a --label io.kubernetes.container.name=etcd"},
+		{Name: "logs/kubelet.log", Command: task.KubeletLogsCommand(since, false)},
+		{
+			Name: "certificates.txt",
+			Command: `sudo sh -c 'for f in /etc/kubernetes/pki/etcd/*.crt /etc/kubernetes/pki/apiserver-etcd-client.crt; do ` +
+				`echo "$f"; openssl x509 -noout -subject -enddate -in "$f"; done'`,
+		},
+		{Name: "system.txt", Command: "df -h; echo; free -m; echo; uptime"},
+		{Name: "time-sync.txt", Command: "timedatectl status; echo; chronyc tracking 2>/dev/null || timedatectl timesync-status 2>/dev/null || true"},
+	}
+}
+
+// timeoutTransport runs the commands of the embedded transport with
+// timeout, unless they are given one.
+type timeoutTransport struct {
+	transport.Transport
+
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) Exec(cmd string, opts transport.ExecOptions) (*transport.Result, error) {
+	if opts.Timeout == 0 {
+		opts.Timeout = t.timeout
+	}
+	return t.Transport.Exec(cmd, opts)
+}
+
+// Run runs cmd through Exec, for the timeout, and returns its stdout
+// followed by its stderr.
+func (t *timeoutTransport) Run(cmd string) ([]byte, error) {
+	res, err := t.Exec(cmd, transport.ExecOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return append(res.Stdout, res.Stderr...), res.Err()
+}
+
+// collectHostArtifacts adds the artifacts of h, read through client, to
+// the bundle with add, under a directory named after h. etcd-diagnosis is
+// uploaded if uploadDiagnosis is set, otherwise it is only run if it is
+// already on h. The artifacts which can't be read are returned as errors,
+// and the error is only set if add fails.
+func collectHostArtifacts(client transport.Transport, h *config.Host, since time.Duration, uploadDiagnosis bool, add func(string, []byte) error) ([]error, error) {
+	var errs []error
+	addFile := func(name string, data []byte) error {
+		return add(h.Name+"/"+name, data)
+	}
+
+	type manifest struct{ name, path string }
+	manifests := []manifest{{"manifests/etcd.yaml", task.EtcdManifestPath}}
+	if h.BackedupManifest != "" {
+		manifests = append(manifests, manifest{"manifests/backup-etcd.yaml", h.BackedupManifest})
+	}
+	for _, m := range manifests {
+		data, err := task.ReadFile(client, m.path)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("failed to read %s: %w", m.path, err))
+		case data == nil:
+			errs = append(errs, fmt.Errorf("%s not found", m.path))
+		default:
+			if err := addFile(m.name, data); err != nil {
+				return errs, err
+			}
+		}
+	}
+
+	for _, a := range hostArtifacts(since) {
+		out, err := client.Run(a.Command)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to collect %s: %w", a.Name, err))
+		}
+		if len(out) > 0 {
+			if err := addFile(a.Name, out); err != nil {
+				return errs, err
+			}
+		}
+	}
+
+	diagnosis := getTargetPath(h.Username)
+	var diagnosisErr error
+	if uploadDiagnosis {
+		diagnosis, diagnosisErr = ensureDiagnosis(client, h)
+	} else if _, err := client.Run(diagnosis + " version"); err != nil {
+		diagnosisErr = fmt.Errorf("etcd-diagnosis not found at %s, use --upload-diagnosis to upload it: %w", diagnosis, err)
+	}
+	if diagnosisErr != nil {
+		errs = append(errs, diagnosisErr)
+	} else {
+		out, err := client.Run(fmt.Sprintf("sudo %s commit-index /var/lib/etcd", diagnosis))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to run etcd-diagnosis: %w", err))
+		}
+		if err := addFile("etcd-diagnosis.txt", out); err != nil {
+			return errs, err
+		}
+	}
+
+	ids, err := task.EtcdContainerIDs(client)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, id := range ids {
+		logs, err := task.ContainerLogs(client, id, since)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := addFile("logs/etcd-"+id+".log", logs); err != nil {
+			return errs, err
+		}
+	}
+	return errs, nil
+}
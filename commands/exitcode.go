@@ -0,0 +1,151 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vmware/etcd-recovery/pkg/cliui"
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/ssh"
+	"github.com/vmware/etcd-recovery/pkg/task"
+)
+
+// Exit codes of etcd-recovery. They are documented in the README, and
+// must not be renumbered as scripts depend on them.
+const (
+	ExitOK                 = 0
+	ExitError              = 1
+	ExitUsage              = 2
+	ExitInvalidConfig      = 3
+	ExitUserCancelled      = 4
+	ExitMissingAnswer      = 5
+	ExitUnreachable        = 6
+	ExitAuthFailed         = 7
+	ExitHostKeyMismatch    = 8
+	ExitEtcdNotRunning     = 9
+	ExitMultiMemberCluster = 10
+	ExitLearnerNotInSync   = 11
+	ExitQuorumIntact       = 12
+	ExitQuorumLost         = 13
+	ExitPreflightFailed    = 14
+	ExitEtcdctlTerminated  = 15
+	// ExitEtcdctlBase is added to the non-zero exit status of etcdctl, so
+	// that its codes don't overlap with the ones above.
+	ExitEtcdctlBase = 100
+)
+
+// etcdctlExitError is returned when etcdctl, run by the etcdctl command,
+// exits with a non-zero status or is terminated by a signal.
+type etcdctlExitError struct {
+	Status int
+	Signal string
+}
+
+func (e *etcdctlExitError) Error() string {
+	if e.Signal != "" {
+		return fmt.Sprintf("etcdctl was terminated by signal %s", e.Signal)
+	}
+	return fmt.Sprintf("etcdctl exited with status %d", e.Status)
+}
+
+// usageError is returned for invalid flags or arguments.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+// usageArgs reports the errors of validate as usage errors.
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return &usageError{err: err}
+		}
+		return nil
+	}
+}
+
+// ExitCode returns the exit code reporting err. When err wraps several
+// typed errors, e.g. the errors of several steps of a plan, the first one
+// in the order of the checks below wins, which isn't the order of the
+// codes. The README documents this order, keep it in sync.
+func ExitCode(err error) int {
+	var (
+		usageErr   *usageError
+		missingErr *cliui.MissingAnswerError
+		etcdctlErr *etcdctlExitError
+	)
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usageErr):
+		return ExitUsage
+	case errors.Is(err, config.ErrInvalidConfig):
+		return ExitInvalidConfig
+	case errors.Is(err, ssh.ErrHostKeyMismatch):
+		// Checked before ErrUserCancelled, which it wraps when the user
+		// rejects the changed key.
+		return ExitHostKeyMismatch
+	case errors.Is(err, cliui.ErrUserCancelled):
+		return ExitUserCancelled
+	case errors.As(err, &missingErr):
+		return ExitMissingAnswer
+	case errors.Is(err, ssh.ErrUnreachable):
+		return ExitUnreachable
+	case errors.Is(err, ssh.ErrAuthFailed):
+		return ExitAuthFailed
+	case errors.Is(err, task.ErrEtcdNotRunning):
+		return ExitEtcdNotRunning
+	case errors.Is(err, task.ErrMultiMemberCluster):
+		return ExitMultiMemberCluster
+	case errors.Is(err, task.ErrLearnerNotInSync):
+		return ExitLearnerNotInSync
+	case errors.Is(err, task.ErrQuorumIntact):
+		return ExitQuorumIntact
+	case errors.Is(err, task.ErrQuorumLost):
+		return ExitQuorumLost
+	case errors.Is(err, errPreflightFailed):
+		return ExitPreflightFailed
+	case errors.As(err, &etcdctlErr):
+		if etcdctlErr.Signal != "" {
+			return ExitEtcdctlTerminated
+		}
+		return min(ExitEtcdctlBase+etcdctlErr.Status, 255)
+	default:
+		return ExitError
+	}
+}
+
+// Execute runs the command given on the command line, and returns its
+// exit code. The transcript is closed whether the command failed or not.
+func Execute() int {
+	err := rootCmd.Execute()
+	if cErr := closeTranscript(); cErr != nil {
+		slog.Error("Failed to close the transcript", "err", cErr)
+		if err == nil {
+			err = cErr
+		}
+	}
+
+	if err != nil {
+		slog.Error("Command failed", "err", err, "exit_code", ExitCode(err))
+	}
+	return ExitCode(err)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/plan"
+)
+
+// membersSummary renders the outcome of adding every learner via seed,
+// followed by the command adding again the ones that weren't added.
+func membersSummary(summary *plan.Summary, seed *config.Host, learners []*config.Host) string {
+	results := map[string]*plan.StepResult{}
+	for _, r := range summary.Steps {
+		results[r.Name] = r
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\nMembers:\n")
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	var retry []string
+	for _, l := range learners {
+		status := "not run"
+		if r := results[addMemberStepName(l)]; r != nil {
+			status = string(r.Status)
+			if r.Status == plan.StepSucceeded {
+				status = "added"
+			} else if r.Err != nil {
+				// The full error is in the summary of the plan.
+				status += ": " + plan.FirstLine(r.Err)
+			}
+			if r.Status != plan.StepSucceeded {
+				retry = append(retry, l.Name)
+			}
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", l.Name, l.Host, status)
+	}
+	_ = w.Flush()
+
+	if len(retry) == 0 {
+		return sb.String()
+	}
+	for _, r := range summary.Steps {
+		if r.Status == plan.StepFailed && !strings.HasPrefix(r.Name, "add-member-") {
+			// The members can't be added without the seed.
+			return sb.String()
+		}
+	}
+
+	sb.WriteString("\nTo add the members which weren't added, run:\n  " + retryCommand(seed, retry) + "\n")
+	return sb.String()
+}
+
+// retryCommand returns the command adding learners to the cluster of
+// seed without prompting for them.
+func retryCommand(seed *config.Host, learners []string) string {
+	args := []string{cliName, "repair", "--mode", "add"}
+	if configFile != config.DefaultConfigFilename {
+		args = append(args, "--config", shellQuote(configFile))
+	}
+	if local {
+		args = append(args, "--local")
+	}
+	if len(learners) > 1 {
+		args = append(args, "--continue-on-error")
+	}
+	args = append(args,
+		"--answer", shellQuote("seed="+seed.Name),
+		"--answer", shellQuote("learners="+strings.Join(learners, ",")),
+	)
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s for a POSIX shell if needed.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`!*?[](){}<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
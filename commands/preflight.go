@@ -0,0 +1,322 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/task"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// errPreflightFailed is returned when a pre-flight check fails.
+var errPreflightFailed = errors.New("pre-flight checks failed")
+
+type preflightStatus string
+
+const (
+	preflightPass preflightStatus = "pass"
+	preflightWarn preflightStatus = "warn"
+	preflightFail preflightStatus = "fail"
+)
+
+const (
+	// minDataDirFree fails the data-dir check, as etcd can't start nor
+	// write its WAL without free space.
+	minDataDirFree = 1 << 30
+	// lowDataDirFree warns, as a restored or synced member may need as
+	// much space as the DB size of the cluster.
+	lowDataDirFree = 4 << 30
+	// maxClockSkew warns, as etcd complains about the clock difference
+	// with its peers above it.
+	maxClockSkew = time.Second
+	// maxClockSkewFail fails, as the TLS certificates may not be valid
+	// yet or any longer on some of the hosts.
+	maxClockSkewFail = time.Minute
+)
+
+// preflightBinaries are the binaries the tasks run on every host.
+var preflightBinaries = []string{"crictl", "kubelet"}
+
+// preflightResult is the result of a pre-flight check of a host, or of
+// the hosts together if Host is empty.
+type preflightResult struct {
+	Host   string
+	Check  string
+	Status preflightStatus
+	Detail string
+}
+
+// hostFacts are read by the pre-flight checks of a host, and compared
+// between the hosts.
+type hostFacts struct {
+	// clockOffset is the offset of the clock of the host from the clock
+	// of this machine, if clockRead is set.
+	clockOffset time.Duration
+	clockRead   bool
+	// image is the etcd image of the manifest of the host, or of its
+	// backed up manifest if it has none.
+	image string
+}
+
+// preflight runs the pre-flight checks of every host, and of the hosts
+// together.
+func preflight(hosts []*config.Host) []preflightResult {
+	var results []preflightResult
+	facts := make([]hostFacts, len(hosts))
+	for i, h := range hosts {
+		client, err := connect(h)
+		if err != nil {
+			results = append(results, preflightResult{Host: h.Name, Check: "ssh", Status: preflightFail, Detail: err.Error()})
+			continue
+		}
+		results = append(results, preflightResult{Host: h.Name, Check: "ssh", Status: preflightPass})
+
+		hostResults, f := preflightHost(client, h)
+		client.Close()
+		results = append(results, hostResults...)
+		facts[i] = f
+	}
+	return append(results, preflightCluster(hosts, facts)...)
+}
+
+// preflightHost checks the host client is connected to: passwordless
+// sudo, the binaries, the backed up manifest and the free space of the
+// data-dir filesystem. It also reads the facts compared between hosts.
+func preflightHost(client transport.Transport, h *config.Host) ([]preflightResult, hostFacts) {
+	var (
+		results []preflightResult
+		facts   hostFacts
+	)
+	add := func(check string, status preflightStatus, format string, args ...any) {
+		results = append(results, preflightResult{Host: h.Name, Check: check, Status: status, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if _, err := runCommand(client, "sudo -n true"); err != nil {
+		add("sudo", preflightFail, "passwordless sudo is required: %v", err)
+	} else {
+		add("sudo", preflightPass, "")
+	}
+
+	// The binaries are looked up with the PATH of sudo, which runs them.
+	var missing []string
+	for _, b := range preflightBinaries {
+		if _, err := runCommand(client, fmt.Sprintf("sudo -n sh -c 'command -v %s'", b)); err != nil {
+			missing = append(missing, b)
+		}
+	}
+	if len(missing) > 0 {
+		add("binaries", preflightFail, "not found: %s", strings.Join(missing, ", "))
+	} else {
+		add("binaries", preflightPass, "%s", strings.Join(preflightBinaries, ", "))
+	}
+
+	backupImage, err := manifestImage(client, h.BackedupManifest)
+	if err != nil {
+		add("backup manifest", preflightFail, "%s: %v", h.BackedupManifest, err)
+	} else {
+		add("backup manifest", preflightPass, "%s", h.BackedupManifest)
+	}
+	if facts.image, err = manifestImage(client, task.EtcdManifestPath); err != nil {
+		facts.image = backupImage
+	}
+
+	if free, err := dataDirFree(client); err != nil {
+		add("data-dir space", preflightFail, "%v", err)
+	} else {
+		status := preflightPass
+		switch {
+		case free < minDataDirFree:
+			status = preflightFail
+		case free < lowDataDirFree:
+			status = preflightWarn
+		}
+		add("data-dir space", status, "%s available", cmp.Or(formatBytes(free), "0 B"))
+	}
+
+	if offset, err := clockOffset(client); err != nil {
+		add("clock", preflightWarn, "%v", err)
+	} else {
+		facts.clockOffset, facts.clockRead = offset, true
+	}
+	return results, facts
+}
+
+// preflightCluster compares the clocks and the etcd images of the hosts
+// whose facts could be read.
+func preflightCluster(hosts []*config.Host, facts []hostFacts) []preflightResult {
+	var results []preflightResult
+
+	var (
+		earliest, latest         time.Duration
+		earliestHost, latestHost string
+	)
+	for i, f := range facts {
+		if !f.clockRead {
+			continue
+		}
+		if earliestHost == "" || f.clockOffset < earliest {
+			earliest, earliestHost = f.clockOffset, hosts[i].Name
+		}
+		if latestHost == "" || f.clockOffset > latest {
+			latest, latestHost = f.clockOffset, hosts[i].Name
+		}
+	}
+	if earliestHost != "" {
+		skew := latest - earliest
+		status := preflightPass
+		switch {
+		case skew > maxClockSkewFail:
+			status = preflightFail
+		case skew > maxClockSkew:
+			status = preflightWarn
+		}
+		detail := fmt.Sprintf("%v", skew.Round(time.Millisecond))
+		if earliestHost != latestHost {
+			detail += fmt.Sprintf(" between %s and %s", earliestHost, latestHost)
+		}
+		results = append(results, preflightResult{Check: "clock skew", Status: status, Detail: detail})
+	}
+
+	images := map[string][]string{}
+	for i, f := range facts {
+		if f.image != "" {
+			images[f.image] = append(images[f.image], hosts[i].Name)
+		}
+	}
+	switch len(images) {
+	case 0:
+	case 1:
+		for image := range images {
+			results = append(results, preflightResult{Check: "etcd image", Status: preflightPass, Detail: image})
+		}
+	default:
+		var details []string
+		for image, names := range images {
+			details = append(details, fmt.Sprintf("%s on %s", image, strings.Join(names, ", ")))
+		}
+		slices.Sort(details)
+		results = append(results, preflightResult{Check: "etcd image", Status: preflightWarn, Detail: strings.Join(details, "; ")})
+	}
+	return results
+}
+
+// runCommand runs cmd on the host client is connected to, and returns its
+// stdout. It fails if cmd exits with a non-zero status.
+func runCommand(client transport.Transport, cmd string) ([]byte, error) {
+	res, err := client.Exec(cmd, transport.ExecOptions{})
+	if err == nil {
+		err = res.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res.Stdout, nil
+}
+
+// manifestImage reads the manifest at path, and returns the image of its
+// etcd container.
+func manifestImage(client transport.Transport, path string) (string, error) {
+	if path == "" {
+		return "", errors.New("not set in the hosts config file")
+	}
+	data, err := task.ReadFile(client, path)
+	if err != nil {
+		return "", err
+	}
+	if data == nil {
+		return "", errors.New("not found")
+	}
+	c, err := task.EtcdContainer(data)
+	if err != nil {
+		return "", err
+	}
+	if c == nil {
+		return "", errors.New("no etcd container")
+	}
+	return c.Image, nil
+}
+
+// dataDirFree returns the free space of the filesystem of the etcd data
+// directory, or of its parent if it doesn't exist yet.
+func dataDirFree(client transport.Transport) (int64, error) {
+	out, err := runCommand(client, "df -P -B1 /var/lib/etcd 2>/dev/null || df -P -B1 /var/lib")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the free space: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(lines) < 2 || len(fields) < 6 {
+		return 0, fmt.Errorf("failed to parse df output: %q", out)
+	}
+	free, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse df output: %w", err)
+	}
+	return free, nil
+}
+
+// clockOffset returns the offset of the clock of the host from the clock
+// of this machine, compared to the middle of the round trip.
+func clockOffset(client transport.Transport) (time.Duration, error) {
+	start := time.Now()
+	out, err := runCommand(client, "date +%s.%N")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the time: %w", err)
+	}
+	rtt := time.Since(start)
+
+	secs, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the time: %w", err)
+	}
+	sec, frac := math.Modf(secs)
+	remote := time.Unix(int64(sec), int64(frac*1e9))
+	return remote.Sub(start.Add(rtt / 2)), nil
+}
+
+// writePreflight writes the results as a table, the results of the hosts
+// together last.
+func writePreflight(w io.Writer, results []preflightResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tCHECK\tRESULT\tDETAIL")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", orDash(r.Host), r.Check, strings.ToUpper(string(r.Status)), r.Detail)
+	}
+	return tw.Flush()
+}
+
+// preflightError returns an error listing the failed checks, or nil if
+// none failed. The warnings are logged.
+func preflightError(results []preflightResult) error {
+	var failed []string
+	for _, r := range results {
+		name := r.Check
+		if r.Host != "" {
+			name = r.Host + " " + r.Check
+		}
+		switch r.Status {
+		case preflightWarn:
+			slog.Warn("Pre-flight check warning", "check", name, "detail", r.Detail)
+		case preflightFail:
+			failed = append(failed, fmt.Sprintf("%s: %s", name, r.Detail))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errPreflightFailed, strings.Join(failed, "; "))
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/vmware/etcd-recovery/pkg/cliui"
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/plan"
+)
+
+// runPlan runs p while showing the progress of hosts on a dashboard, and
+// records it in the transcript. The logs are printed as they are when
+// stdout isn't a terminal or when the dashboard is disabled. Pressing
+// ctrl+c cancels p once its running steps have finished, and returns
+// cliui.ErrUserCancelled so that the transcript and the report are still
+// written.
+func runPlan(p *plan.ExecutionPlan, title string, hosts []*config.Host, dashboard bool) (*plan.Summary, error) {
+	if transcript != nil {
+		p.Observers = append(p.Observers, transcript)
+	}
+
+	if !dashboard || !isatty.IsTerminal(os.Stdout.Fd()) {
+		interrupted, stop := notifyInterrupt()
+		defer stop()
+		p.Cancel = interrupted
+		return cancelled(p.Run())
+	}
+
+	dashboardHosts := make([]cliui.DashboardHost, 0, len(hosts))
+	for _, h := range hosts {
+		dashboardHosts = append(dashboardHosts, cliui.DashboardHost{Name: h.Name, Address: h.Host})
+	}
+
+	d := cliui.NewDashboard(title, dashboardHosts)
+	p.Observers = append(p.Observers, d)
+	p.Cancel = d.Interrupted()
+	d.Start()
+	defer d.Stop()
+
+	return cancelled(p.Run())
+}
+
+// cancelled reports a plan cancelled with ctrl+c as cancelled by the user.
+func cancelled(summary *plan.Summary, err error) (*plan.Summary, error) {
+	if errors.Is(err, plan.ErrCancelled) {
+		err = fmt.Errorf("%w: %w", cliui.ErrUserCancelled, err)
+	}
+	return summary, err
+}
+
+// notifyInterrupt returns a channel closed on the first ctrl+c. The next
+// one exits the process as usual. stop must be called once done.
+func notifyInterrupt() (interrupted <-chan struct{}, stop func()) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+
+	ch := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sig:
+			signal.Stop(sig)
+			slog.Warn("Interrupted, waiting for the running steps to finish, press ctrl+c again to exit now")
+			close(ch)
+		case <-done:
+		}
+	}()
+	return ch, func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
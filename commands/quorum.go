@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/logging"
+	"github.com/vmware/etcd-recovery/pkg/task"
+)
+
+// probeHosts probes etcd on every host, concurrently. The probes of the
+// hosts which can't be probed are nil.
+func probeHosts(hosts []*config.Host) []*task.EtcdProbe {
+	return forEachHost(hosts, probeHost)
+}
+
+// probeHost probes etcd on h, or returns nil if it can't be probed.
+func probeHost(h *config.Host) *task.EtcdProbe {
+	client, err := connect(h)
+	if err != nil {
+		slog.Warn("Failed to probe etcd", logging.Host(h.Name, h.Host), "err", err)
+		return nil
+	}
+	defer client.Close()
+
+	p, err := task.ProbeEtcd(client)
+	if err != nil {
+		slog.Warn("Failed to probe etcd", logging.Host(h.Name, h.Host), "err", err)
+		return nil
+	}
+	slog.Debug("Probed etcd", logging.Host(h.Name, h.Host), "member_id", fmt.Sprintf("%x", p.MemberID), "leader", fmt.Sprintf("%x", p.Leader))
+	return p
+}
+
+// checkQuorum refuses to force a new cluster, unless force is set, when
+// a quorum of voters is still reachable and agrees on a leader, as the
+// membership of a healthy cluster would be thrown away.
+func checkQuorum(hosts []*config.Host, force bool) error {
+	probes := probeHosts(hosts)
+	q := task.CheckQuorum(probes)
+	slog.Info("Checked quorum", "voters", len(q.Voters), "needed", q.Needed(), "agreeing", q.Agreeing, "leader", fmt.Sprintf("%x", q.Leader))
+	if !q.Intact() {
+		return nil
+	}
+
+	var failed []string
+	for i, p := range probes {
+		if p == nil || p.Leader != q.Leader {
+			failed = append(failed, hosts[i].Name)
+		}
+	}
+	if force {
+		slog.Warn("Quorum is intact, forcing a new cluster anyway", "agreeing", q.Agreeing, "voters", len(q.Voters))
+		return nil
+	}
+
+	return fmt.Errorf("%w: %d of %d voters agree on leader %x, so the cluster doesn't need to be recreated. %s, "+
+		"or pass --force to create a single-member cluster anyway", task.ErrQuorumIntact, q.Agreeing, len(q.Voters), q.Leader, replaceHint(failed))
+}
+
+// replaceHint suggests how to replace the failed members of a cluster
+// which still has quorum.
+func replaceHint(failed []string) string {
+	if len(failed) == 0 {
+		return "Every host agrees on the leader, there is nothing to repair"
+	}
+	cmds := make([]string, 0, len(failed))
+	for _, name := range failed {
+		cmds = append(cmds, fmt.Sprintf("`%s repair --mode replace --member %s`", cliName, name))
+	}
+	return "Replace the failed members instead, one after another: " + strings.Join(cmds, ", ")
+}
+
+// healthyMember returns the host to replace member through, see
+// leaderHost. It fails if no quorum of voters is reachable and agrees on
+// a leader, or if member is itself a voter following the leader.
+func healthyMember(hosts []*config.Host, member *config.Host) (*config.Host, error) {
+	probes := probeHosts(hosts)
+	q := task.CheckQuorum(probes)
+	slog.Info("Checked quorum", "voters", len(q.Voters), "needed", q.Needed(), "agreeing", q.Agreeing, "leader", fmt.Sprintf("%x", q.Leader))
+	if !q.Intact() {
+		return nil, quorumLost(q, "the member can't be replaced")
+	}
+
+	for i, p := range probes {
+		if hosts[i].Name == member.Name && p != nil && !p.IsLearner && p.Leader == q.Leader {
+			return nil, fmt.Errorf("member %s is healthy and follows leader %x, there is nothing to replace", member.Name, q.Leader)
+		}
+	}
+
+	healthy := leaderHost(hosts, probes, q)
+	slog.Info("Selected the healthy member to replace the member through", logging.Host(healthy.Name, healthy.Host), "member", member.Name)
+	return healthy, nil
+}
+
+// leaderHost returns the host of the leader agreed upon in q if it is one
+// of hosts, otherwise a voter following it. q must be intact.
+func leaderHost(hosts []*config.Host, probes []*task.EtcdProbe, q *task.Quorum) *config.Host {
+	var healthy *config.Host
+	for i, p := range probes {
+		if p == nil || p.IsLearner || p.Leader != q.Leader {
+			continue
+		}
+		if healthy == nil || p.MemberID == q.Leader {
+			healthy = hosts[i]
+		}
+	}
+	return healthy
+}
+
+// quorumLost returns the error reporting that what can't be done as the
+// quorum of q is lost.
+func quorumLost(q *task.Quorum, what string) error {
+	return fmt.Errorf("%w: %d of %d voters agree on a leader, %d needed, so %s. "+
+		"Recreate the cluster with `%s repair --mode both` instead", task.ErrQuorumLost, q.Agreeing, len(q.Voters), q.Needed(), what, cliName)
+}
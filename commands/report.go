@@ -0,0 +1,233 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/logging"
+	"github.com/vmware/etcd-recovery/pkg/report"
+	"github.com/vmware/etcd-recovery/pkg/task"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// readCommitIndexes reads the commit index of every host into r. The
+// hosts which can't be read are reported without one.
+func readCommitIndexes(r *report.Report, hosts []*config.Host) {
+	for _, h := range hosts {
+		rh := &report.Host{Name: h.Name, Address: h.Host}
+		commitIndex, err := readCommitIndex(h)
+		if err != nil {
+			slog.Warn("Failed to read commit index", logging.Host(h.Name, h.Host), "err", err)
+			rh.CommitIndexError = err.Error()
+		} else {
+			slog.Info("Read commit index", logging.Host(h.Name, h.Host), "commit_index", commitIndex)
+			rh.CommitIndex = &commitIndex
+		}
+		r.Hosts = append(r.Hosts, rh)
+	}
+}
+
+// seedOptions returns the options to select the seed from, with the
+// commit index of the hosts read into r.
+func seedOptions(r *report.Report, hosts []*config.Host) []string {
+	options := createOptions(hosts)
+	for i, h := range hosts {
+		if rh := reportHost(r, h); rh != nil && rh.CommitIndex != nil {
+			options[i] = fmt.Sprintf("%s (%s, commit index %d)", h.Name, h.Host, *rh.CommitIndex)
+		}
+	}
+	return options
+}
+
+// seedCandidates returns seed followed by the other hosts whose commit
+// index was read into r, from the highest commit index to the lowest as
+// ranked by the select command.
+func seedCandidates(r *report.Report, hosts []*config.Host, seed *config.Host) []*config.Host {
+	candidates := []*config.Host{seed}
+	for _, h := range hosts {
+		if rh := reportHost(r, h); h.Name != seed.Name && rh != nil && rh.CommitIndex != nil {
+			candidates = append(candidates, h)
+		}
+	}
+	slices.SortStableFunc(candidates[1:], func(a, b *config.Host) int {
+		return *reportHost(r, b).CommitIndex - *reportHost(r, a).CommitIndex
+	})
+	return candidates
+}
+
+func reportHost(r *report.Report, h *config.Host) *report.Host {
+	for _, rh := range r.Hosts {
+		if rh.Name == h.Name {
+			return rh
+		}
+	}
+	return nil
+}
+
+// setSeed records the seed selected in mode, and why it was chosen.
+func setSeed(r *report.Report, seed *config.Host, mode string) {
+	s := &report.Seed{Name: seed.Name, Address: seed.Host}
+	r.Seed = s
+
+	if mode == "add" {
+		s.Reason = "initial member of the single-member cluster, selected by the user"
+		return
+	}
+	if mode == "replace" {
+		s.Reason = "healthy voter following the leader, the failed member was replaced through it"
+		return
+	}
+
+	var best *report.Host
+	for _, rh := range r.Hosts {
+		if rh.CommitIndex != nil && (best == nil || *rh.CommitIndex > *best.CommitIndex) {
+			best = rh
+		}
+	}
+
+	rh := reportHost(r, seed)
+	switch {
+	case rh == nil || rh.CommitIndex == nil:
+		s.Reason = "selected by the user, its commit index couldn't be read"
+	case *rh.CommitIndex >= *best.CommitIndex:
+		s.CommitIndex = rh.CommitIndex
+		s.Reason = "highest commit index of the hosts, selected by the user"
+	default:
+		s.CommitIndex = rh.CommitIndex
+		s.Reason = fmt.Sprintf("selected by the user, although %s has a higher commit index (%d)", best.Name, *best.CommitIndex)
+	}
+
+	if len(r.AbandonedSeeds) > 0 {
+		names := make([]string, 0, len(r.AbandonedSeeds))
+		for _, a := range r.AbandonedSeeds {
+			names = append(names, a.Name)
+		}
+		s.Reason = fmt.Sprintf("next highest commit index, after the single-member cluster failed to be created from %s", strings.Join(names, ", "))
+	}
+}
+
+// listMembers lists the members of the cluster on the seed, without
+// waiting for etcd nor retrying, as the cluster may have lost quorum.
+func listMembers(client transport.Transport) ([]report.Member, error) {
+	containerID, err := task.EtcdContainerID(client)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Exec(task.EtcdctlCommand(containerID, "--command-timeout=5s", "member", "list", "-w", "json"), transport.ExecOptions{})
+	if err == nil {
+		err = res.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	members, err := task.ParseMembers(res.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	return report.Members(members), nil
+}
+
+// snapshotMembers records the members on the seed before the repair.
+func snapshotMembers(r *report.Report, seed *config.Host) {
+	client, err := connect(seed)
+	if err != nil {
+		r.MembersBeforeError = err.Error()
+		return
+	}
+	defer client.Close()
+
+	if r.MembersBefore, err = listMembers(client); err != nil {
+		slog.Warn("Failed to list the members before the repair", "err", err)
+		r.MembersBeforeError = err.Error()
+	}
+}
+
+// verifyCluster records the members after the repair, and checks that
+// etcd is running and healthy on the seed, that every member is started
+// and promoted and, if expectedMembers isn't 0, that there are that many
+// members.
+func verifyCluster(r *report.Report, seed *config.Host, expectedMembers int) {
+	client, err := connect(seed)
+	if err != nil {
+		r.MembersAfterError = err.Error()
+		r.Verification = append(r.Verification, report.Check{Name: "Connect to " + seed.Name, Detail: err.Error()})
+		return
+	}
+	defer client.Close()
+
+	containerID, err := task.EtcdContainerID(client)
+	check := report.Check{Name: "etcd running on " + seed.Name, Passed: err == nil}
+	if err != nil {
+		check.Detail = err.Error()
+	}
+	r.Verification = append(r.Verification, check)
+	if err != nil {
+		r.MembersAfterError = err.Error()
+		return
+	}
+
+	res, err := client.Exec(task.EtcdctlCommand(containerID, "--command-timeout=5s", "endpoint", "health", "--cluster"), transport.ExecOptions{})
+	if err == nil {
+		err = res.Err()
+	}
+	check = report.Check{Name: "All endpoints healthy", Passed: err == nil}
+	if err != nil {
+		check.Detail = err.Error()
+	} else {
+		check.Detail = strings.TrimSpace(string(res.Stdout))
+	}
+	r.Verification = append(r.Verification, check)
+
+	if r.MembersAfter, err = listMembers(client); err != nil {
+		r.MembersAfterError = err.Error()
+		r.Verification = append(r.Verification, report.Check{Name: "List members", Detail: err.Error()})
+		return
+	}
+
+	var unstarted, learners []string
+	for _, m := range r.MembersAfter {
+		if m.Name == "" {
+			unstarted = append(unstarted, m.ID)
+		}
+		if m.IsLearner {
+			learners = append(learners, m.ID)
+		}
+	}
+	r.Verification = append(r.Verification,
+		report.Check{Name: "All members started", Passed: len(unstarted) == 0, Detail: strings.Join(unstarted, ", ")},
+		report.Check{Name: "No learner left", Passed: len(learners) == 0, Detail: strings.Join(learners, ", ")},
+	)
+	if expectedMembers > 0 {
+		r.Verification = append(r.Verification, report.Check{
+			Name:   fmt.Sprintf("%d members", expectedMembers),
+			Passed: len(r.MembersAfter) == expectedMembers,
+			Detail: fmt.Sprintf("%d members", len(r.MembersAfter)),
+		})
+	}
+}
+
+// writeReport finishes r with the result of the repair, and writes it to
+// dir unless it is empty. Nothing is written if no seed was selected, as
+// nothing was done then.
+func writeReport(r *report.Report, dir string, err error) {
+	if dir == "" || r.Seed == nil {
+		return
+	}
+
+	r.Finish(err)
+	mdPath, jsonPath, wErr := r.Write(dir)
+	if wErr != nil {
+		slog.Error("Failed to write the recovery report", "err", wErr)
+		return
+	}
+	slog.Info("Recovery report written", "markdown", mdPath, "json", jsonPath)
+}
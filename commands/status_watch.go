@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/vmware/etcd-recovery/pkg/cliui"
+	"github.com/vmware/etcd-recovery/pkg/config"
+)
+
+// maxWatchChanges is the number of changes kept below the table by
+// status --watch.
+const maxWatchChanges = 10
+
+// statusChange is a change of the status of a host between two
+// refreshes.
+type statusChange struct {
+	Host string
+	// Column is the column of statusHeader highlighted.
+	Column  int
+	Message string
+}
+
+// statusChanges returns the changes from prev to cur worth highlighting:
+// a new leader, the progress of the learners, the members appearing or
+// disappearing and the new alarms. The hosts are matched by name, and
+// the hosts missing from prev have no changes.
+func statusChanges(prev, cur []*hostStatus) []statusChange {
+	var changes []statusChange
+	for _, c := range cur {
+		i := slices.IndexFunc(prev, func(p *hostStatus) bool { return p.Name == c.Name })
+		if i < 0 {
+			continue
+		}
+		p := prev[i]
+		add := func(column int, format string, args ...any) {
+			changes = append(changes, statusChange{Host: c.Name, Column: column, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if p.Reachable && !c.Reachable {
+			add(statusSSHColumn, "%s became unreachable", c.Name)
+			continue
+		}
+
+		switch {
+		case p.MemberID == c.MemberID:
+		case p.MemberID == "":
+			add(statusMemberColumn, "member %s appeared on %s", c.MemberID, c.Name)
+		case c.MemberID == "":
+			add(statusMemberColumn, "member %s disappeared from %s", p.MemberID, c.Name)
+		default:
+			add(statusMemberColumn, "member %s replaced by %s on %s", p.MemberID, c.MemberID, c.Name)
+		}
+
+		if p.MemberID == c.MemberID && p.Role != "" && c.Role != "" && p.Role != c.Role {
+			if c.Role == "voter" {
+				add(statusRoleColumn, "learner %s promoted on %s", c.MemberID, c.Name)
+			} else {
+				add(statusRoleColumn, "member %s is now a %s on %s", c.MemberID, c.Role, c.Name)
+			}
+		}
+
+		if c.Leader != "" && p.Leader != c.Leader {
+			if p.Leader == "" {
+				add(statusLeaderColumn, "leader %s elected", c.Leader)
+			} else {
+				add(statusLeaderColumn, "new leader %s, was %s", c.Leader, p.Leader)
+			}
+		}
+
+		if c.Role == "learner" && p.MemberID == c.MemberID && c.RaftIndex > p.RaftIndex {
+			add(statusIndexColumn, "learner %s on %s at raft index %d (+%d)", c.MemberID, c.Name, c.RaftIndex, c.RaftIndex-p.RaftIndex)
+		}
+
+		for _, a := range c.Alarms {
+			if !slices.Contains(p.Alarms, a) {
+				add(statusAlarmsColumn, "new alarm %s on %s", a, c.Name)
+			}
+		}
+	}
+	return changes
+}
+
+// statusWatcher reads the status of the hosts on every refresh of
+// status --watch, and keeps the changes since the previous ones.
+type statusWatcher struct {
+	hosts   []*config.Host
+	read    func(*config.Host) *hostStatus
+	now     func() time.Time
+	prev    []*hostStatus
+	changes []string
+}
+
+func newStatusWatcher(hosts []*config.Host) *statusWatcher {
+	return &statusWatcher{hosts: hosts, read: readHostStatus, now: time.Now}
+}
+
+// refresh reads the status of the hosts again, and returns the view with
+// the cells changed since the previous refresh highlighted.
+func (w *statusWatcher) refresh() cliui.WatchView {
+	statuses := forEachHost(w.hosts, w.read)
+
+	changes := statusChanges(w.prev, statuses)
+	w.prev = statuses
+
+	at := w.now().Format(time.TimeOnly)
+	var messages []string
+	for _, c := range changes {
+		// Every member reports the same new leader.
+		if !slices.Contains(messages, c.Message) {
+			messages = append(messages, c.Message)
+			w.changes = append(w.changes, at+" "+c.Message)
+		}
+	}
+	if len(w.changes) > maxWatchChanges {
+		w.changes = w.changes[len(w.changes)-maxWatchChanges:]
+	}
+
+	view := cliui.WatchView{Header: statusHeader, Changes: slices.Clone(w.changes)}
+	for _, s := range statuses {
+		row := make([]cliui.WatchCell, 0, len(statusHeader))
+		for i, text := range statusRow(s) {
+			changed := slices.ContainsFunc(changes, func(c statusChange) bool {
+				return c.Host == s.Name && c.Column == i
+			})
+			row = append(row, cliui.WatchCell{Text: text, Changed: changed})
+		}
+		view.Rows = append(view.Rows, row)
+
+		for _, e := range s.Errors {
+			view.Notes = append(view.Notes, fmt.Sprintf("%s: %s", s.Name, e))
+		}
+	}
+	return view
+}
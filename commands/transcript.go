@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package commands
+
+import (
+	"log/slog"
+
+	"github.com/vmware/etcd-recovery/pkg/audit"
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/redact"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// transcript records the actions taken on the hosts during this run. It
+// is nil if --transcript-dir is empty.
+var transcript *audit.Transcript
+
+// openTranscript starts the transcript of the run, masking the passwords
+// and passphrases of hosts in it.
+func openTranscript(hosts []*config.Host) error {
+	if transcriptDir == "" || transcript != nil {
+		return nil
+	}
+
+	header, err := audit.NewHeader(configFile)
+	if err != nil {
+		return err
+	}
+	t, err := audit.Open(transcriptDir, audit.Format(transcriptFormat), header, hostsRedactor(hosts))
+	if err != nil {
+		return err
+	}
+
+	transcript = t
+	slog.Info("Recording the actions taken on the hosts", "transcript", t.Path())
+	return nil
+}
+
+func closeTranscript() error {
+	if transcript == nil {
+		return nil
+	}
+	err := transcript.Close()
+	transcript = nil
+	return err
+}
+
+// hostsRedactor returns a redactor masking the secrets of hosts.
+func hostsRedactor(hosts []*config.Host) *redact.Redactor {
+	var secrets []string
+	for _, h := range hosts {
+		secrets = append(secrets, h.Password, h.Passphrase)
+	}
+	return redact.New(secrets...)
+}
+
+// connect opens a transport to h whose commands are recorded in the
+// transcript. Plans record them through their observers instead.
+func connect(h *config.Host) (transport.Transport, error) {
+	client, err := h.Connect()
+	if err != nil || transcript == nil {
+		return client, err
+	}
+	return event.NewTransport(client, h.Host, transcript.OnEvent), nil
+}
@@ -4,10 +4,119 @@
 
 package commands
 
-import "log"
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
 
-func printLog(format string, v ...any) {
-	if verbose {
-		log.Printf(format, v...)
+	"github.com/vmware/etcd-recovery/pkg/cliui"
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/logging"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// loadHosts parses the hosts config file. When --local is set, the host
+// whose address belongs to this machine is marked as local so that it
+// is driven without SSH. It also masks the secrets of the hosts in the
+// logs.
+func loadHosts() ([]*config.Host, error) {
+	hosts, err := config.ParseHostFromFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setupLogging(hostsRedactor(hosts)); err != nil {
+		return nil, err
+	}
+
+	if local {
+		if err := markLocalHost(hosts, transport.IsLocalAddress); err != nil {
+			return nil, err
+		}
+	}
+
+	return hosts, nil
+}
+
+// loadHostsRecorded is loadHosts for the commands which change the hosts,
+// and also starts the transcript of the run. The read-only commands don't
+// record a transcript.
+func loadHostsRecorded() ([]*config.Host, error) {
+	hosts, err := loadHosts()
+	if err != nil {
+		return nil, err
+	}
+	if err := openTranscript(hosts); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+func markLocalHost(hosts []*config.Host, isLocal func(string) bool) error {
+	found := false
+	for _, h := range hosts {
+		if isLocal(h.Host) {
+			if found {
+				return fmt.Errorf("%w: more than one host in %s belongs to this machine", config.ErrInvalidConfig, configFile)
+			}
+			h.Local = true
+			found = true
+			slog.Debug("Host is local, commands will be executed without SSH", logging.Host(h.Name, h.Host))
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("%w: --local is set, but none of the hosts in %s belongs to this machine", config.ErrInvalidConfig, configFile)
+	}
+	return nil
+}
+
+// forEachHost calls fn for every host concurrently, so that a slow host
+// doesn't delay the others, and returns the results in the order of hosts.
+func forEachHost[T any](hosts []*config.Host, fn func(h *config.Host) T) []T {
+	results := make([]T, len(hosts))
+	var wg sync.WaitGroup
+	for i, h := range hosts {
+		wg.Go(func() {
+			results[i] = fn(h)
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+// findHost returns the host named name, or whose address is name, or nil
+// if there is none. It resolves the hosts given on the command line.
+func findHost(hosts []*config.Host, name string) *config.Host {
+	if name == "" {
+		return nil
+	}
+	for _, h := range hosts {
+		if h.Name == name || h.Host == name {
+			return h
+		}
+	}
+	return nil
+}
+
+// parseAnswers merges the answers file with the key=value answers given on
+// the command line, the latter taking precedence.
+func parseAnswers(path string, kvs []string) (map[string]string, error) {
+	answers := map[string]string{}
+	if path != "" {
+		var err error
+		if answers, err = cliui.LoadAnswers(path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, kv := range kvs {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid --answer %q, expected key=value", kv)
+		}
+		answers[strings.TrimSpace(key)] = value
 	}
+	return answers, nil
 }
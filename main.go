@@ -5,22 +5,11 @@
 package main
 
 import (
-	"log"
 	"os"
 
 	"github.com/vmware/etcd-recovery/commands"
 )
 
-const (
-	exitError = 1
-)
-
 func main() {
-	rootCmd := commands.RootCmd()
-	if err := rootCmd.Execute(); err != nil {
-		if rootCmd.SilenceErrors {
-			log.Printf("Error: %v\n", err)
-		}
-		os.Exit(exitError)
-	}
+	os.Exit(commands.Execute())
 }
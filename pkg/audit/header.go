@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/vmware/etcd-recovery/pkg/redact"
+	"github.com/vmware/etcd-recovery/version"
+)
+
+// Header is the first record of a transcript, describing the run.
+type Header struct {
+	// User is the user running the tool, and the user who invoked sudo
+	// if any.
+	User     string
+	Hostname string
+	Version  string
+	GitSHA   string
+	Args     []string
+	// HostsFile is the path of the hosts config file, and HostsSHA256
+	// the checksum of its content.
+	HostsFile   string
+	HostsSHA256 string
+}
+
+// NewHeader returns the header of the current run, which uses the hosts
+// config file at hostsFile.
+func NewHeader(hostsFile string) (Header, error) {
+	h := Header{
+		Version:   version.Version,
+		GitSHA:    version.GitSHA,
+		Args:      os.Args,
+		HostsFile: hostsFile,
+	}
+
+	if u, err := user.Current(); err == nil {
+		h.User = u.Username
+	}
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		h.User = fmt.Sprintf("%s (sudo by %s)", h.User, sudoUser)
+	}
+	h.Hostname, _ = os.Hostname()
+
+	data, err := os.ReadFile(hostsFile)
+	if err != nil {
+		return Header{}, fmt.Errorf("failed to read hosts config file: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	h.HostsSHA256 = hex.EncodeToString(sum[:])
+	return h, nil
+}
+
+func (h Header) record(now time.Time, r *redact.Redactor) *record {
+	args := make([]string, 0, len(h.Args))
+	for _, a := range h.Args {
+		args = append(args, r.String(a))
+	}
+	return &record{
+		Time:        now,
+		Type:        "run",
+		User:        h.User,
+		Hostname:    h.Hostname,
+		Version:     h.Version,
+		GitSHA:      h.GitSHA,
+		Args:        args,
+		HostsFile:   h.HostsFile,
+		HostsSHA256: h.HostsSHA256,
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+// Package audit writes the transcript of a run: every command run on the
+// hosts with its exit code, duration and output, every file copied with
+// its checksum and every manifest change, for post-incident reviews.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware/etcd-recovery/pkg/diff"
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/redact"
+)
+
+// Format is the format of a transcript.
+type Format string
+
+const (
+	// FormatJSON writes one JSON object per line.
+	FormatJSON Format = "json"
+	// FormatText writes a human readable log.
+	FormatText Format = "text"
+)
+
+// Transcript is an event.Observer appending the events of a run to a
+// file. Every run gets its own file, which is only ever appended to.
+type Transcript struct {
+	mu       sync.Mutex
+	f        *os.File
+	format   Format
+	redactor *redact.Redactor
+	err      error
+}
+
+var _ event.Observer = (*Transcript)(nil)
+
+// Open creates the transcript of a new run in dir, and writes the header
+// to it. The output of the commands, the commands themselves and the
+// errors are masked by r.
+func Open(dir string, format Format, header Header, r *redact.Redactor) (*Transcript, error) {
+	ext := "jsonl"
+	switch format {
+	case FormatJSON:
+	case FormatText:
+		ext = "log"
+	default:
+		return nil, fmt.Errorf("invalid transcript format %q, valid formats are: [%s %s]", format, FormatJSON, FormatText)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("etcd-recovery-%s-%d.%s", now.UTC().Format("20060102T150405Z"), os.Getpid(), ext)
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transcript: %w", err)
+	}
+
+	t := &Transcript{f: f, format: format, redactor: r}
+	t.write(header.record(now, r))
+	if t.err != nil {
+		f.Close()
+		return nil, t.err
+	}
+	return t, nil
+}
+
+// Path returns the path of the transcript file.
+func (t *Transcript) Path() string {
+	return t.f.Name()
+}
+
+// OnEvent appends ev to the transcript. Events which aren't about the
+// actions taken on the hosts are ignored.
+func (t *Transcript) OnEvent(ev event.Event) {
+	rec := t.record(ev)
+	if rec == nil {
+		return
+	}
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.write(rec)
+}
+
+// Close flushes the transcript to disk, and returns the first error that
+// occurred while writing it.
+func (t *Transcript) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.f.Sync(); err != nil && t.err == nil {
+		t.err = err
+	}
+	if err := t.f.Close(); err != nil && t.err == nil {
+		t.err = err
+	}
+	return t.err
+}
+
+func (t *Transcript) write(rec *record) {
+	if t.err != nil {
+		return
+	}
+
+	var data []byte
+	if t.format == FormatText {
+		data = []byte(rec.text())
+	} else {
+		var err error
+		if data, err = json.Marshal(rec); err != nil {
+			t.err = fmt.Errorf("failed to encode transcript record: %w", err)
+			return
+		}
+		data = append(data, '\n')
+	}
+
+	// Every record is a single write, so that records are never
+	// interleaved, nor partially lost if the tool is interrupted.
+	if _, err := t.f.Write(data); err != nil {
+		t.err = fmt.Errorf("failed to write transcript: %w", err)
+	}
+}
+
+func (t *Transcript) record(ev event.Event) *record {
+	m := ev.Metadata()
+	rec := &record{Time: m.Time, Plan: m.Plan, Step: m.Step, Host: m.Host}
+
+	switch ev := ev.(type) {
+	case *event.PlanStarted:
+		rec.Type = "plan_started"
+		rec.Steps = ev.Steps
+	case *event.PlanFinished:
+		rec.Type = "plan_finished"
+		rec.setDuration(ev.Duration)
+		rec.setErr(ev.Err, t.redactor)
+	case *event.StepStarted:
+		rec.Type = "step_started"
+	case *event.StepFinished:
+		rec.Type = "step_finished"
+		rec.Status = ev.Status
+		rec.setDuration(ev.Duration)
+		rec.setErr(ev.Err, t.redactor)
+	case *event.CommandRun:
+		rec.Type = "command"
+		rec.Command = t.redactor.String(ev.Command)
+		rec.ExitCode = &ev.ExitCode
+		rec.Stdout = t.redactor.Bytes(ev.Stdout)
+		rec.Stderr = t.redactor.Bytes(ev.Stderr)
+		rec.setDuration(ev.Duration)
+		rec.setErr(ev.Err, t.redactor)
+	case *event.FileTransferred:
+		rec.Type = "file_" + ev.Direction
+		rec.LocalPath = ev.LocalPath
+		rec.RemotePath = ev.RemotePath
+		rec.SHA256 = ev.SHA256
+		rec.Size = ev.Size
+		rec.setDuration(ev.Duration)
+		rec.setErr(ev.Err, t.redactor)
+	case *event.ManifestChanged:
+		rec.Type = "manifest_changed"
+		rec.RemotePath = ev.Path
+		rec.Reason = ev.Reason
+		rec.Diff = t.redactor.String(diff.Unified(string(ev.Old), string(ev.New)))
+	case *event.Retry:
+		rec.Type = "retry"
+		rec.Description = ev.Description
+		rec.Attempt = ev.Attempt
+		rec.MaxAttempts = ev.MaxAttempts
+		rec.setErr(ev.Err, t.redactor)
+	case *event.SeedStarted:
+		rec.Type = "seed_started"
+	case *event.MemberAdded:
+		rec.Type = "member_added"
+		rec.MemberID = fmt.Sprintf("%x", ev.MemberID)
+		rec.Name = ev.Name
+		rec.PeerURL = ev.PeerURL
+		rec.IsLearner = ev.IsLearner
+	case *event.MemberRemoved:
+		rec.Type = "member_removed"
+		rec.MemberID = fmt.Sprintf("%x", ev.MemberID)
+	case *event.LearnerPromoted:
+		rec.Type = "learner_promoted"
+		rec.MemberID = fmt.Sprintf("%x", ev.MemberID)
+	case *event.Warning:
+		rec.Type = "warning"
+		rec.Description = t.redactor.String(ev.Message)
+	default:
+		return nil
+	}
+	return rec
+}
+
+// record is a line of the transcript. Only the fields relevant to its
+// type are set.
+type record struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+	Plan string    `json:"plan,omitempty"`
+	Step string    `json:"step,omitempty"`
+	Host string    `json:"host,omitempty"`
+
+	// run
+	User        string   `json:"user,omitempty"`
+	Hostname    string   `json:"hostname,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	GitSHA      string   `json:"git_sha,omitempty"`
+	Args        []string `json:"args,omitempty"`
+	HostsFile   string   `json:"hosts_file,omitempty"`
+	HostsSHA256 string   `json:"hosts_sha256,omitempty"`
+
+	Steps  []string `json:"steps,omitempty"`
+	Status string   `json:"status,omitempty"`
+
+	// command
+	Command  string `json:"command,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+
+	// file transfer and manifest
+	LocalPath  string `json:"local_path,omitempty"`
+	RemotePath string `json:"remote_path,omitempty"`
+	SHA256     string `json:"sha256,omitempty"`
+	Size       int64  `json:"size,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Diff       string `json:"diff,omitempty"`
+
+	// retry
+	Description string `json:"description,omitempty"`
+	Attempt     int    `json:"attempt,omitempty"`
+	MaxAttempts int    `json:"max_attempts,omitempty"`
+
+	// membership
+	MemberID  string `json:"member_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	PeerURL   string `json:"peer_url,omitempty"`
+	IsLearner bool   `json:"is_learner,omitempty"`
+
+	Duration string `json:"duration,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func (r *record) setDuration(d time.Duration) {
+	if d > 0 {
+		r.Duration = d.String()
+	}
+}
+
+func (r *record) setErr(err error, redactor *redact.Redactor) {
+	if err != nil {
+		r.Error = redactor.String(err.Error())
+	}
+}
+
+// text renders the record as a line of key=value pairs, followed by the
+// multi-line fields indented.
+func (r *record) text() string {
+	var sb strings.Builder
+	sb.WriteString(r.Time.Format(time.RFC3339Nano) + " " + r.Type)
+
+	field := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, " %s=%q", key, value)
+		}
+	}
+	field("plan", r.Plan)
+	field("step", r.Step)
+	field("host", r.Host)
+	field("user", r.User)
+	field("hostname", r.Hostname)
+	field("version", r.Version)
+	field("git_sha", r.GitSHA)
+	if len(r.Args) > 0 {
+		field("args", strings.Join(r.Args, " "))
+	}
+	field("hosts_file", r.HostsFile)
+	field("hosts_sha256", r.HostsSHA256)
+	if len(r.Steps) > 0 {
+		field("steps", strings.Join(r.Steps, ","))
+	}
+	field("status", r.Status)
+	field("command", r.Command)
+	if r.ExitCode != nil {
+		field("exit_code", fmt.Sprint(*r.ExitCode))
+	}
+	field("local_path", r.LocalPath)
+	field("remote_path", r.RemotePath)
+	field("sha256", r.SHA256)
+	if r.Size > 0 {
+		field("size", fmt.Sprint(r.Size))
+	}
+	field("reason", r.Reason)
+	field("description", r.Description)
+	if r.Attempt > 0 {
+		field("attempt", fmt.Sprintf("%d/%d", r.Attempt, r.MaxAttempts))
+	}
+	field("member_id", r.MemberID)
+	field("name", r.Name)
+	field("peer_url", r.PeerURL)
+	if r.IsLearner {
+		field("is_learner", "true")
+	}
+	field("duration", r.Duration)
+	field("error", r.Error)
+	sb.WriteString("\n")
+
+	block := func(name, content string) {
+		if content == "" {
+			return
+		}
+		sb.WriteString("  " + name + ":\n")
+		for _, l := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+			sb.WriteString("    " + l + "\n")
+		}
+	}
+	block("stdout", r.Stdout)
+	block("stderr", r.Stderr)
+	block("diff", r.Diff)
+	return sb.String()
+}
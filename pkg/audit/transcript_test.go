@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/redact"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+func readRecords(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var records []map[string]any
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal(s.Bytes(), &rec))
+		records = append(records, rec)
+	}
+	require.NoError(t, s.Err())
+	return records
+}
+
+func TestTranscriptJSON(t *testing.T) {
+	dir := t.TempDir()
+	hostsFile := filepath.Join(dir, "hosts.json")
+	require.NoError(t, os.WriteFile(hostsFile, []byte(`[{"name": "etcd-vm1", "password": "changeme"}]`), 0o600))
+
+	header, err := NewHeader(hostsFile)
+	require.NoError(t, err)
+	header.Args = []string{"etcd-recovery", "exec", "-e", "echo changeme"}
+	assert.Len(t, header.HostsSHA256, 64)
+
+	tr, err := Open(filepath.Join(dir, "transcripts"), FormatJSON, header, redact.New("changeme"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(tr.Path(), ".jsonl"))
+
+	// Copy a file through the event transport to get its checksum.
+	src := filepath.Join(dir, "etcd.yaml")
+	require.NoError(t, os.WriteFile(src, []byte("hello"), 0o600))
+	client := event.NewTransport(transport.NewLocal(), "10.0.0.1", tr.OnEvent)
+	require.NoError(t, client.Upload(src, filepath.Join(dir, "copy.yaml")))
+
+	tr.OnEvent(&event.CommandRun{
+		Meta:     event.Meta{Plan: "create", Step: "seed", Host: "10.0.0.1"},
+		Command:  "echo changeme | sudo -S crictl ps",
+		ExitCode: 1,
+		Stderr:   []byte("permission denied\n"),
+		Duration: 1500 * time.Millisecond,
+		Err:      errors.New("exit status 1"),
+	})
+	tr.OnEvent(&event.ManifestChanged{
+		Meta: event.Meta{Host: "10.0.0.1"},
+		Path: "/etc/kubernetes/manifests/etcd.yaml",
+		Old:  []byte("- etcd\n- --force-new-cluster\n"),
+		New:  []byte("- etcd\n"),
+	})
+	tr.OnEvent(&event.StepStarted{})
+	require.NoError(t, tr.Close())
+
+	records := readRecords(t, tr.Path())
+	require.Len(t, records, 5)
+
+	assert.Equal(t, "run", records[0]["type"])
+	assert.Equal(t, header.HostsSHA256, records[0]["hosts_sha256"])
+	assert.Equal(t, []any{"etcd-recovery", "exec", "-e", "echo ******"}, records[0]["args"])
+
+	assert.Equal(t, "file_upload", records[1]["type"])
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", records[1]["sha256"])
+	assert.EqualValues(t, 5, records[1]["size"])
+
+	assert.Equal(t, "command", records[2]["type"])
+	assert.Equal(t, "echo ****** | sudo -S crictl ps", records[2]["command"])
+	assert.EqualValues(t, 1, records[2]["exit_code"])
+	assert.Equal(t, "permission denied\n", records[2]["stderr"])
+	assert.Equal(t, "1.5s", records[2]["duration"])
+
+	assert.Equal(t, "manifest_changed", records[3]["type"])
+	assert.Equal(t, " - etcd\n-- --force-new-cluster\n", records[3]["diff"])
+
+	assert.Equal(t, "step_started", records[4]["type"])
+}
+
+func TestTranscriptText(t *testing.T) {
+	dir := t.TempDir()
+	tr, err := Open(dir, FormatText, Header{User: "root", Version: "0.1.0"}, nil)
+	require.NoError(t, err)
+	tr.OnEvent(&event.CommandRun{
+		Meta:    event.Meta{Host: "10.0.0.1"},
+		Command: "sudo crictl ps",
+		Stdout:  []byte("CONTAINER\nabc123\n"),
+	})
+	require.NoError(t, tr.Close())
+
+	data, err := os.ReadFile(tr.Path())
+	require.NoError(t, err)
+	lines := strings.Split(string(data), "\n")
+	assert.Contains(t, lines[0], ` run user="root" version="0.1.0"`)
+	assert.Contains(t, lines[1], ` command host="10.0.0.1" command="sudo crictl ps" exit_code="0"`)
+	assert.Equal(t, []string{"  stdout:", "    CONTAINER", "    abc123", ""}, lines[2:])
+}
+
+func TestOpenInvalidFormat(t *testing.T) {
+	_, err := Open(t.TempDir(), "yaml", Header{}, nil)
+	require.EqualError(t, err, `invalid transcript format "yaml", valid formats are: [json text]`)
+}
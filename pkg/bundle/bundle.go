@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+// Package bundle writes support bundles: gzipped tarballs of the
+// artifacts collected from the hosts, with their secrets masked.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/vmware/etcd-recovery/pkg/redact"
+)
+
+// Writer adds files to a support bundle. The files are under a single
+// root directory, so that extracting the bundle doesn't scatter them.
+type Writer struct {
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	root     string
+	redactor *redact.Redactor
+	now      time.Time
+}
+
+// NewWriter returns a writer of a bundle to w, whose files are under
+// root and masked by r.
+func NewWriter(w io.Writer, root string, r *redact.Redactor) *Writer {
+	gz := gzip.NewWriter(w)
+	return &Writer{gz: gz, tw: tar.NewWriter(gz), root: root, redactor: r, now: time.Now()}
+}
+
+// Add adds data to the bundle at name, relative to the root directory,
+// with the secrets masked.
+func (b *Writer) Add(name string, data []byte) error {
+	content := []byte(b.redactor.Bytes(data))
+	hdr := &tar.Header{
+		Name:    path.Join(b.root, name),
+		Mode:    0o644,
+		Size:    int64(len(content)),
+		ModTime: b.now,
+	}
+	if err := b.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to add %s to the bundle: %w", name, err)
+	}
+	if _, err := b.tw.Write(content); err != nil {
+		return fmt.Errorf("failed to add %s to the bundle: %w", name, err)
+	}
+	return nil
+}
+
+// Close writes the end of the bundle. It doesn't close the underlying
+// writer.
+func (b *Writer) Close() error {
+	if err := b.tw.Close(); err != nil {
+		return fmt.Errorf("failed to write the bundle: %w", err)
+	}
+	if err := b.gz.Close(); err != nil {
+		return fmt.Errorf("failed to write the bundle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	promptStyle = lipgloss.NewStyle().MarginLeft(2).Bold(true)
+	choiceStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("170")).Underline(true)
+	hintStyle   = lipgloss.NewStyle().MarginLeft(2).Foreground(lipgloss.Color("241"))
+)
+
+// Confirm asks a yes/no question. The answer is toggled with the arrow or
+// tab keys and accepted with enter, or given directly with 'y' or 'n'.
+//
+// Example usage:
+//
+//	ok, err := cliui.Confirm("delete-data-dir.etcd-vm2", "Delete /var/lib/etcd/member on etcd-vm2?", false)
+//	if err != nil || !ok {
+//	    return
+//	}
+func Confirm(key, question string, defaultYes bool) (bool, error) {
+	return current().Confirm(key, question, defaultYes)
+}
+
+// Confirm shows Yes and No side by side.
+func (TerminalPrompter) Confirm(_, question string, defaultYes bool) (bool, error) {
+	m := &confirmModel{question: question, yes: defaultYes}
+	if err := runPrompt(m); err != nil {
+		return false, fmt.Errorf("error showing confirmation: %w", err)
+	}
+	if m.cancelled {
+		return false, ErrUserCancelled
+	}
+	return m.yes, nil
+}
+
+type confirmModel struct {
+	question  string
+	yes       bool
+	done      bool
+	cancelled bool
+}
+
+func (m *confirmModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *confirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case isCancelKey(key):
+		m.cancelled = true
+		return m, tea.Quit
+	case key.Type == tea.KeyEnter:
+		m.done = true
+		return m, tea.Quit
+	case key.Type == tea.KeyLeft, key.Type == tea.KeyRight, key.Type == tea.KeyTab:
+		m.yes = !m.yes
+	}
+
+	switch key.String() {
+	case "y", "Y":
+		m.yes, m.done = true, true
+		return m, tea.Quit
+	case "n", "N":
+		m.yes, m.done = false, true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *confirmModel) View() string {
+	if m.done || m.cancelled {
+		return ""
+	}
+
+	yes, no := "Yes", "No"
+	if m.yes {
+		yes = choiceStyle.Render(yes)
+	} else {
+		no = choiceStyle.Render(no)
+	}
+	return fmt.Sprintf("\n%s\n\n    %s    %s\n\n%s\n",
+		promptStyle.Render(m.question), yes, no,
+		hintStyle.Render("←/→ toggle • enter confirm • y/n answer • esc cancel"))
+}
@@ -0,0 +1,426 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/logging"
+)
+
+// Phase is the progress of a host through the repair.
+type Phase string
+
+const (
+	// PhasePending is the phase of the hosts nothing happened to yet,
+	// e.g. the healthy members of a replace or a reconcile.
+	PhasePending  Phase = "pending"
+	PhaseRunning  Phase = "running"
+	PhaseStopped  Phase = "stopped"
+	PhaseSeed     Phase = "seed"
+	PhaseLearner  Phase = "learner"
+	PhaseSyncing  Phase = "syncing"
+	PhasePromoted Phase = "promoted"
+	PhaseFailed   Phase = "failed"
+)
+
+var (
+	dashboardTitleStyle = lipgloss.NewStyle().Bold(true)
+	dashboardDimStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	phaseStyles         = map[Phase]lipgloss.Style{
+		PhasePending:  lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
+		PhaseRunning:  lipgloss.NewStyle().Foreground(lipgloss.Color("75")),
+		PhaseStopped:  lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
+		PhaseSeed:     lipgloss.NewStyle().Foreground(lipgloss.Color("39")),
+		PhaseLearner:  lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		PhaseSyncing:  lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		PhasePromoted: lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+		PhaseFailed:   lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+	}
+)
+
+// DashboardHost is a host shown on the dashboard.
+type DashboardHost struct {
+	Name    string
+	Address string
+}
+
+// Dashboard is a full-screen view of the progress of a plan: the phase
+// of every host, the running steps, the retries and a scrollable log
+// pane. It is an event.Observer, and an io.Writer receiving the logs.
+type Dashboard struct {
+	model   *dashboardModel
+	program *tea.Program
+	done    chan struct{}
+	logOut  io.Writer
+	stopped sync.Once
+	// interrupted is closed when ctrl+c is pressed.
+	interrupted chan struct{}
+}
+
+var _ event.Observer = (*Dashboard)(nil)
+
+// NewDashboard returns a dashboard for hosts, which aren't shown until
+// Start is called.
+func NewDashboard(title string, hosts []DashboardHost) *Dashboard {
+	m := newDashboardModel(title, hosts, time.Now())
+	return &Dashboard{
+		model:   m,
+		program: tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion()),
+		done:    make(chan struct{}),
+
+		interrupted: make(chan struct{}),
+	}
+}
+
+// Start shows the dashboard and redirects the logs to its log pane until
+// Stop is called. Pressing ctrl+c closes the dashboard, prints the logs as
+// they are from then on, and closes Interrupted.
+func (d *Dashboard) Start() {
+	d.logOut = logging.Output()
+	logging.SetOutput(d)
+	setActiveDashboard(d)
+
+	go func() {
+		defer close(d.done)
+		final, err := d.program.Run()
+		if err != nil {
+			logging.SetOutput(d.logOut)
+			slog.Error("Dashboard failed", "err", err)
+			return
+		}
+		if final.(*dashboardModel).interrupted {
+			setActiveDashboard(nil)
+			logging.SetOutput(d.logOut)
+			slog.Warn("Interrupted, waiting for the running steps to finish, press ctrl+c again to exit now")
+			close(d.interrupted)
+		}
+	}()
+}
+
+// Interrupted is closed when ctrl+c is pressed on the dashboard.
+func (d *Dashboard) Interrupted() <-chan struct{} {
+	return d.interrupted
+}
+
+// Stop closes the dashboard, restores the output of the logs and prints
+// the final state of the hosts.
+func (d *Dashboard) Stop() {
+	d.stopped.Do(func() {
+		d.program.Send(doneMsg{})
+		<-d.done
+		setActiveDashboard(nil)
+		logging.SetOutput(d.logOut)
+		fmt.Print(d.model.summary())
+	})
+}
+
+// OnEvent updates the dashboard with ev.
+func (d *Dashboard) OnEvent(ev event.Event) {
+	d.program.Send(eventMsg{ev})
+}
+
+// Write adds log lines to the log pane.
+func (d *Dashboard) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		d.program.Send(logMsg(line))
+	}
+	return len(p), nil
+}
+
+var (
+	activeMu        sync.Mutex
+	activeDashboard *Dashboard
+)
+
+func setActiveDashboard(d *Dashboard) {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	activeDashboard = d
+}
+
+// suspendDashboard gives the terminal back while a prompt is shown on top
+// of a running dashboard. The returned function resumes the dashboard.
+func suspendDashboard() func() {
+	activeMu.Lock()
+	d := activeDashboard
+	activeMu.Unlock()
+
+	if d == nil {
+		return func() {}
+	}
+	_ = d.program.ReleaseTerminal()
+	logging.SetOutput(d.logOut)
+	return func() {
+		logging.SetOutput(d)
+		_ = d.program.RestoreTerminal()
+	}
+}
+
+type (
+	eventMsg struct{ ev event.Event }
+	logMsg   string
+	tickMsg  time.Time
+	doneMsg  struct{}
+)
+
+type hostState struct {
+	DashboardHost
+	phase   Phase
+	retries int
+}
+
+type runningStep struct {
+	name  string
+	host  string
+	start time.Time
+}
+
+type dashboardModel struct {
+	title   string
+	hosts   []*hostState
+	running map[string]runningStep
+	spinner spinner.Model
+	logs    viewport.Model
+	lines   []string
+
+	start       time.Time
+	now         time.Time
+	width       int
+	height      int
+	finished    bool
+	err         error
+	interrupted bool
+}
+
+func newDashboardModel(title string, hosts []DashboardHost, start time.Time) *dashboardModel {
+	m := &dashboardModel{
+		title:   title,
+		running: map[string]runningStep{},
+		spinner: spinner.New(spinner.WithSpinner(spinner.Dot)),
+		logs:    viewport.New(80, 10),
+		start:   start,
+		now:     start,
+		width:   80,
+		height:  24,
+	}
+	for _, h := range hosts {
+		m.hosts = append(m.hosts, &hostState{DashboardHost: h, phase: PhasePending})
+	}
+	return m
+}
+
+func (m *dashboardModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, tick())
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+func (m *dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.resizeLogs()
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			m.interrupted = true
+			return m, tea.Quit
+		}
+		var cmd tea.Cmd
+		m.logs, cmd = m.logs.Update(msg)
+		return m, cmd
+
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m.logs, cmd = m.logs.Update(msg)
+		return m, cmd
+
+	case tickMsg:
+		m.now = time.Time(msg)
+		return m, tick()
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case logMsg:
+		m.appendLog(string(msg))
+		return m, nil
+
+	case eventMsg:
+		m.handleEvent(msg.ev)
+		return m, nil
+
+	case doneMsg:
+		m.now = time.Now()
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *dashboardModel) host(addr string) *hostState {
+	for _, h := range m.hosts {
+		if h.Address == addr {
+			return h
+		}
+	}
+	return nil
+}
+
+func (m *dashboardModel) handleEvent(ev event.Event) {
+	meta := ev.Metadata()
+	if !meta.Time.IsZero() && meta.Time.After(m.now) {
+		m.now = meta.Time
+	}
+	h := m.host(meta.Host)
+
+	switch ev := ev.(type) {
+	case *event.StepStarted:
+		m.running[meta.Step] = runningStep{name: meta.Step, host: meta.Host, start: meta.Time}
+		m.resizeLogs()
+	case *event.StepFinished:
+		delete(m.running, meta.Step)
+		m.resizeLogs()
+		if ev.Status == "failed" && h != nil {
+			h.phase = PhaseFailed
+		}
+		if ev.Err != nil {
+			m.appendLog(fmt.Sprintf("[%s] step %s %s: %v", meta.Host, meta.Step, ev.Status, ev.Err))
+		} else {
+			m.appendLog(fmt.Sprintf("[%s] step %s %s in %v", meta.Host, meta.Step, ev.Status, ev.Duration.Round(time.Second)))
+		}
+	case *event.PlanFinished:
+		m.finished = true
+		m.err = ev.Err
+	case *event.Retry:
+		if h != nil {
+			h.retries++
+		}
+	case *event.SeedStarted:
+		if h != nil {
+			h.phase = PhaseSeed
+		}
+		m.appendLog(fmt.Sprintf("[%s] etcd started as the single member of the cluster", meta.Host))
+	case *event.ManifestChanged:
+		// Removing the manifest stops etcd, writing one (re)starts it.
+		if h != nil {
+			switch {
+			case ev.New == nil:
+				h.phase = PhaseStopped
+			case h.phase == PhaseLearner:
+				h.phase = PhaseSyncing
+			case h.phase == PhasePending || h.phase == PhaseStopped:
+				h.phase = PhaseRunning
+			}
+		}
+		m.appendLog(fmt.Sprintf("[%s] manifest changed: %s", meta.Host, ev.Reason))
+	case *event.MemberAdded:
+		if h != nil {
+			h.phase = PhaseLearner
+		}
+		m.appendLog(fmt.Sprintf("[%s] member %x added, learner: %v", meta.Host, ev.MemberID, ev.IsLearner))
+	case *event.LearnerPromoted:
+		if h != nil {
+			h.phase = PhasePromoted
+		}
+		m.appendLog(fmt.Sprintf("[%s] learner %x promoted", meta.Host, ev.MemberID))
+	case *event.MemberRemoved:
+		m.appendLog(fmt.Sprintf("[%s] member %x removed", meta.Host, ev.MemberID))
+	}
+}
+
+func (m *dashboardModel) appendLog(line string) {
+	atBottom := m.logs.AtBottom()
+	m.lines = append(m.lines, line)
+	m.logs.SetContent(strings.Join(m.lines, "\n"))
+	if atBottom {
+		m.logs.GotoBottom()
+	}
+}
+
+// resizeLogs gives the log pane the lines left by the host table.
+func (m *dashboardModel) resizeLogs() {
+	m.logs.Width = m.width
+	m.logs.Height = max(m.height-len(m.hosts)-len(m.running)-6, 3)
+}
+
+func (m *dashboardModel) View() string {
+	var sb strings.Builder
+
+	elapsed := m.now.Sub(m.start).Round(time.Second)
+	sb.WriteString(dashboardTitleStyle.Render(m.title))
+	sb.WriteString(dashboardDimStyle.Render(fmt.Sprintf("  elapsed %v", elapsed)))
+	sb.WriteString("\n\n")
+	sb.WriteString(m.hostTable())
+	sb.WriteString("\n")
+
+	names := make([]string, 0, len(m.running))
+	for name := range m.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := m.running[name]
+		fmt.Fprintf(&sb, "%s %s on %s (%v)\n", m.spinner.View(), s.name, s.host, m.now.Sub(s.start).Round(time.Second))
+	}
+	if m.finished {
+		if m.err != nil {
+			sb.WriteString(phaseStyles[PhaseFailed].Render("Failed: " + m.err.Error()))
+		} else {
+			sb.WriteString(phaseStyles[PhasePromoted].Render("Done."))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(dashboardDimStyle.Render(strings.Repeat("─", 4) + " logs (↑/↓ to scroll) " + strings.Repeat("─", 4)))
+	sb.WriteString("\n")
+	sb.WriteString(m.logs.View())
+	return sb.String()
+}
+
+// summary is the final state of the hosts, printed once the dashboard is
+// closed.
+func (m *dashboardModel) summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s finished in %v\n", m.title, m.now.Sub(m.start).Round(time.Second))
+	sb.WriteString(m.hostTable())
+	if m.err != nil {
+		fmt.Fprintf(&sb, "Failed: %v\n", m.err)
+	}
+	return sb.String()
+}
+
+func (m *dashboardModel) hostTable() string {
+	nameWidth, addrWidth := len("HOST"), len("ADDRESS")
+	for _, h := range m.hosts {
+		nameWidth = max(nameWidth, len(h.Name))
+		addrWidth = max(addrWidth, len(h.Address))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-*s  %-*s  %-8s  %s\n", nameWidth, "HOST", addrWidth, "ADDRESS", "PHASE", "RETRIES")
+	for _, h := range m.hosts {
+		phase := phaseStyles[h.phase].Render(fmt.Sprintf("%-8s", h.phase))
+		fmt.Fprintf(&sb, "%-*s  %-*s  %s  %d\n", nameWidth, h.Name, addrWidth, h.Address, phase, h.retries)
+	}
+	return sb.String()
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/etcd-recovery/pkg/event"
+)
+
+func newTestDashboardModel() (*dashboardModel, time.Time) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	m := newDashboardModel("Repair etcd cluster", []DashboardHost{
+		{Name: "vm1", Address: "10.0.0.1"},
+		{Name: "vm2", Address: "10.0.0.2"},
+		{Name: "vm3", Address: "10.0.0.3"},
+	}, start)
+	return m, start
+}
+
+func send(m *dashboardModel, evs ...event.Event) {
+	for _, ev := range evs {
+		m.Update(eventMsg{ev})
+	}
+}
+
+func at(start time.Time, sec int, host, step string) event.Meta {
+	return event.Meta{Time: start.Add(time.Duration(sec) * time.Second), Host: host, Step: step}
+}
+
+func TestDashboardPhases(t *testing.T) {
+	m, start := newTestDashboardModel()
+
+	send(m,
+		&event.StepStarted{Meta: at(start, 1, "10.0.0.1", "create-seed")},
+		&event.ManifestChanged{Meta: at(start, 2, "10.0.0.1", "create-seed"), Reason: "add --force-new-cluster", New: []byte("new")},
+		&event.SeedStarted{Meta: at(start, 2, "10.0.0.1", "create-seed")},
+		&event.ManifestChanged{Meta: at(start, 3, "10.0.0.1", "create-seed"), Reason: "remove --force-new-cluster", New: []byte("new")},
+		&event.StepFinished{Meta: at(start, 4, "10.0.0.1", "create-seed"), Status: "succeeded"},
+		&event.StepStarted{Meta: at(start, 5, "10.0.0.2", "add-vm2")},
+		&event.MemberAdded{Meta: at(start, 6, "10.0.0.2", "add-vm2"), MemberID: 0x1001, IsLearner: true},
+	)
+	assert.Equal(t, PhaseSeed, m.host("10.0.0.1").phase)
+	assert.Equal(t, PhaseLearner, m.host("10.0.0.2").phase)
+	assert.Equal(t, PhasePending, m.host("10.0.0.3").phase)
+
+	send(m,
+		&event.ManifestChanged{Meta: at(start, 7, "10.0.0.2", "add-vm2"), Reason: "join the cluster as learner", New: []byte("new")},
+		&event.Retry{Meta: at(start, 8, "10.0.0.1", "add-vm2"), Attempt: 1},
+		&event.Retry{Meta: at(start, 9, "10.0.0.2", "add-vm2"), Attempt: 1},
+		&event.Retry{Meta: at(start, 10, "10.0.0.2", "add-vm2"), Attempt: 2},
+	)
+	assert.Equal(t, PhaseSyncing, m.host("10.0.0.2").phase)
+	assert.Equal(t, 2, m.host("10.0.0.2").retries)
+
+	view := m.View()
+	assert.Contains(t, view, "elapsed 10s")
+	assert.Contains(t, view, "add-vm2 on 10.0.0.2 (5s)")
+	assert.Contains(t, view, "member 1001 added")
+
+	send(m,
+		&event.LearnerPromoted{Meta: at(start, 11, "10.0.0.2", "add-vm2"), MemberID: 0x1001},
+		&event.StepFinished{Meta: at(start, 12, "10.0.0.2", "add-vm2"), Status: "succeeded"},
+		&event.StepStarted{Meta: at(start, 13, "10.0.0.3", "add-vm3")},
+		&event.StepFinished{Meta: at(start, 14, "10.0.0.3", "add-vm3"), Status: "failed", Err: errors.New("learner did not start")},
+		&event.PlanFinished{Meta: at(start, 15, "", ""), Err: errors.New("learner did not start")},
+	)
+	assert.Equal(t, PhasePromoted, m.host("10.0.0.2").phase)
+	assert.Equal(t, PhaseFailed, m.host("10.0.0.3").phase)
+	assert.Empty(t, m.running)
+
+	summary := m.summary()
+	assert.Contains(t, summary, "Repair etcd cluster finished in 15s")
+	assert.Contains(t, summary, "Failed: learner did not start")
+}
+
+func TestDashboardReplacePhases(t *testing.T) {
+	m, start := newTestDashboardModel()
+
+	// vm3 is replaced through the healthy vm1.
+	send(m,
+		&event.StepStarted{Meta: at(start, 1, "10.0.0.1", "remove-member-vm3")},
+		&event.MemberRemoved{Meta: at(start, 2, "10.0.0.3", "remove-member-vm3"), MemberID: 0x1003},
+		&event.StepFinished{Meta: at(start, 3, "10.0.0.1", "remove-member-vm3"), Status: "succeeded"},
+		&event.StepStarted{Meta: at(start, 4, "10.0.0.3", "stop-etcd-vm3")},
+		&event.ManifestChanged{Meta: at(start, 5, "10.0.0.3", "stop-etcd-vm3"), Reason: "stop the stale member", Old: []byte("old")},
+		&event.StepFinished{Meta: at(start, 6, "10.0.0.3", "stop-etcd-vm3"), Status: "succeeded"},
+	)
+	assert.Equal(t, PhaseStopped, m.host("10.0.0.3").phase)
+	assert.Equal(t, PhasePending, m.host("10.0.0.1").phase)
+
+	send(m,
+		&event.StepStarted{Meta: at(start, 7, "10.0.0.1", "add-member-vm3")},
+		&event.MemberAdded{Meta: at(start, 8, "10.0.0.3", "add-member-vm3"), MemberID: 0x1004, IsLearner: true},
+		&event.ManifestChanged{Meta: at(start, 9, "10.0.0.3", "add-member-vm3"), Reason: "join the cluster as learner", New: []byte("new")},
+	)
+	assert.Equal(t, PhaseSyncing, m.host("10.0.0.3").phase)
+
+	send(m,
+		&event.LearnerPromoted{Meta: at(start, 10, "10.0.0.3", "add-member-vm3"), MemberID: 0x1004},
+		&event.StepFinished{Meta: at(start, 11, "10.0.0.1", "add-member-vm3"), Status: "succeeded"},
+	)
+	assert.Equal(t, PhasePromoted, m.host("10.0.0.3").phase)
+	for _, h := range m.hosts {
+		assert.NotEqual(t, PhaseSeed, h.phase, h.Name)
+	}
+}
+
+func TestDashboardReconcilePhases(t *testing.T) {
+	m, start := newTestDashboardModel()
+
+	// The peer URL of vm2 is updated through vm1, and its manifest
+	// rewritten; a learner of vm3 which failed is stopped.
+	send(m,
+		&event.StepStarted{Meta: at(start, 1, "10.0.0.1", "update-peer-url-vm2")},
+		&event.ManifestChanged{Meta: at(start, 2, "10.0.0.2", "update-peer-url-vm2"), Reason: "point the URLs to the new address", Old: []byte("old"), New: []byte("new")},
+		&event.StepFinished{Meta: at(start, 3, "10.0.0.1", "update-peer-url-vm2"), Status: "succeeded"},
+		&event.StepStarted{Meta: at(start, 4, "10.0.0.1", "add-member-vm3")},
+		&event.MemberAdded{Meta: at(start, 5, "10.0.0.3", "add-member-vm3"), MemberID: 0x1004, IsLearner: true},
+		&event.ManifestChanged{Meta: at(start, 6, "10.0.0.3", "add-member-vm3"), Reason: "stop the learner which failed to join", Old: []byte("old")},
+	)
+	assert.Equal(t, PhasePending, m.host("10.0.0.1").phase)
+	assert.Equal(t, PhaseRunning, m.host("10.0.0.2").phase)
+	assert.Equal(t, PhaseStopped, m.host("10.0.0.3").phase)
+	for _, h := range m.hosts {
+		assert.NotEqual(t, PhaseSeed, h.phase, h.Name)
+	}
+}
+
+func TestDashboardLogPane(t *testing.T) {
+	m, _ := newTestDashboardModel()
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 12})
+	require.Equal(t, 3, m.logs.Height)
+
+	for _, line := range []string{"one", "two", "three", "four", "five"} {
+		m.Update(logMsg(line))
+	}
+	assert.Contains(t, m.View(), "five")
+	assert.NotContains(t, m.View(), "one")
+
+	// scrolling up stops following new lines
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m.Update(logMsg("six"))
+	assert.Contains(t, m.View(), "three")
+	assert.NotContains(t, m.View(), "six")
+}
+
+func TestDashboardInterrupt(t *testing.T) {
+	m, _ := newTestDashboardModel()
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	require.NotNil(t, cmd)
+	assert.Equal(t, tea.QuitMsg{}, cmd())
+	assert.True(t, m.interrupted)
+}
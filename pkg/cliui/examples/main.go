@@ -12,11 +12,23 @@ import (
 )
 
 func main() {
-	index, choice, err := cliui.Select("Please select one of the VMs:", []string{"etcd-vm1", "etcd-vm2", "etcd-vm3"})
+	index, choice, err := cliui.Select("vm", "Please select one of the VMs:", []string{"etcd-vm1", "etcd-vm2", "etcd-vm3"})
 
 	if err != nil {
 		log.Fatalf("Error occurred during selection: %v", err)
 	}
 
 	fmt.Printf("Index: %d, Choice: %s\n", index, choice)
+
+	learners, err := cliui.MultiSelect("learners", "Please select the learners:", []string{"etcd-vm2", "etcd-vm3"})
+	if err != nil {
+		log.Fatalf("Error occurred during selection: %v", err)
+	}
+	fmt.Printf("Learners: %v\n", learners)
+
+	ok, err := cliui.Confirm("delete-data-dir", "Delete the data directory?", false)
+	if err != nil {
+		log.Fatalf("Error occurred during confirmation: %v", err)
+	}
+	fmt.Printf("Confirmed: %v\n", ok)
 }
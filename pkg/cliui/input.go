@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var errorStyle = lipgloss.NewStyle().MarginLeft(2).Foreground(lipgloss.Color("196"))
+
+// Input reads a line of text. If validate is not nil, enter only accepts
+// a value for which it returns no error, and the error is shown otherwise.
+//
+// Example usage:
+//
+//	name, err := cliui.Input("member-name", "Member name:", "etcd-vm4", nil)
+func Input(key, prompt, placeholder string, validate func(string) error) (string, error) {
+	return current().Input(key, prompt, placeholder, validate)
+}
+
+// Input shows a bubbletea text input.
+func (TerminalPrompter) Input(_, prompt, placeholder string, validate func(string) error) (string, error) {
+	ti := textinput.New()
+	ti.Placeholder = placeholder
+	ti.Focus()
+
+	m := &inputModel{prompt: prompt, input: ti, validate: validate}
+	if err := runPrompt(m); err != nil {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+	if m.cancelled {
+		return "", ErrUserCancelled
+	}
+	return m.input.Value(), nil
+}
+
+type inputModel struct {
+	prompt    string
+	input     textinput.Model
+	validate  func(string) error
+	err       error
+	done      bool
+	cancelled bool
+}
+
+func (m *inputModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *inputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case isCancelKey(key):
+			m.cancelled = true
+			return m, tea.Quit
+		case key.Type == tea.KeyEnter:
+			if m.validate != nil {
+				if m.err = m.validate(m.input.Value()); m.err != nil {
+					return m, nil
+				}
+			}
+			m.done = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *inputModel) View() string {
+	if m.done || m.cancelled {
+		return ""
+	}
+
+	view := fmt.Sprintf("\n%s\n\n  %s\n", promptStyle.Render(m.prompt), m.input.View())
+	if m.err != nil {
+		view += "\n" + errorStyle.Render(m.err.Error()) + "\n"
+	}
+	return view + "\n" + hintStyle.Render("enter confirm • esc cancel") + "\n"
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LinePrompter asks the questions as plain lines of text, for when there
+// is no terminal to run bubbletea on. Options are numbered and answered
+// by number or by name.
+type LinePrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+var _ Prompter = (*LinePrompter)(nil)
+
+// NewLinePrompter returns a LinePrompter reading the answers from in and
+// writing the questions to out.
+func NewLinePrompter(in io.Reader, out io.Writer) *LinePrompter {
+	return &LinePrompter{in: bufio.NewReader(in), out: out}
+}
+
+// Select prints the numbered options and reads one choice.
+func (p *LinePrompter) Select(_, title string, options []string) (int, error) {
+	if len(options) == 0 {
+		return -1, errors.New("no options provided")
+	}
+
+	p.printOptions(title, options)
+	for {
+		line, err := p.ask("Enter a number: ")
+		if err != nil {
+			return -1, err
+		}
+		idx, err := parseChoice(line, options)
+		if err == nil {
+			return idx, nil
+		}
+		fmt.Fprintln(p.out, err)
+	}
+}
+
+// MultiSelect prints the numbered options and reads a comma-separated
+// list of choices, "all" or "none".
+func (p *LinePrompter) MultiSelect(_, title string, options []string) ([]int, error) {
+	if len(options) == 0 {
+		return nil, errors.New("no options provided")
+	}
+
+	p.printOptions(title, options)
+	for {
+		line, err := p.ask("Enter numbers separated by commas, 'all' or 'none': ")
+		if err != nil {
+			return nil, err
+		}
+		idx, err := parseChoices(line, options)
+		if err == nil {
+			return idx, nil
+		}
+		fmt.Fprintln(p.out, err)
+	}
+}
+
+// Confirm reads y or n. An empty line gives the default answer.
+func (p *LinePrompter) Confirm(_, question string, defaultYes bool) (bool, error) {
+	hint := "[y/N]"
+	if defaultYes {
+		hint = "[Y/n]"
+	}
+	for {
+		line, err := p.ask(fmt.Sprintf("%s %s ", question, hint))
+		if err != nil {
+			return false, err
+		}
+		if line == "" {
+			return defaultYes, nil
+		}
+		yes, err := parseYesNo(line)
+		if err == nil {
+			return yes, nil
+		}
+		fmt.Fprintln(p.out, err)
+	}
+}
+
+// Input reads a line, and asks again as long as validate rejects it.
+func (p *LinePrompter) Input(_, prompt, placeholder string, validate func(string) error) (string, error) {
+	if placeholder != "" {
+		prompt = fmt.Sprintf("%s (e.g. %s)", prompt, placeholder)
+	}
+	for {
+		line, err := p.ask(prompt + " ")
+		if err != nil {
+			return "", err
+		}
+		if validate == nil {
+			return line, nil
+		}
+		if err := validate(line); err != nil {
+			fmt.Fprintln(p.out, err)
+			continue
+		}
+		return line, nil
+	}
+}
+
+// Review prints the content and asks whether to continue.
+func (p *LinePrompter) Review(key, title, content string) (bool, error) {
+	fmt.Fprintf(p.out, "\n%s\n\n%s\n", title, content)
+	return p.Confirm(key, "Continue?", true)
+}
+
+func (p *LinePrompter) printOptions(title string, options []string) {
+	fmt.Fprintf(p.out, "\n%s\n", title)
+	for i, option := range options {
+		fmt.Fprintf(p.out, "  %d. %s\n", i+1, option)
+	}
+}
+
+// ask prints the prompt and returns the next line without the surrounding
+// spaces.
+func (p *LinePrompter) ask(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	line, err := p.in.ReadString('\n')
+	switch {
+	case errors.Is(err, io.EOF) && line == "":
+		fmt.Fprintln(p.out)
+		return "", fmt.Errorf("no answer provided: %w", io.ErrUnexpectedEOF)
+	case err != nil && !errors.Is(err, io.EOF):
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// parseChoice returns the index of the option given by its number or by
+// its name. The name of an option is its text before " (", so that
+// "etcd-vm1" chooses "etcd-vm1 (10.0.0.1)".
+func parseChoice(answer string, options []string) (int, error) {
+	answer = strings.TrimSpace(answer)
+	if n, err := strconv.Atoi(answer); err == nil {
+		if n < 1 || n > len(options) {
+			return -1, fmt.Errorf("choice %d out of range [1, %d]", n, len(options))
+		}
+		return n - 1, nil
+	}
+	for i, option := range options {
+		name, _, _ := strings.Cut(option, " (")
+		if answer == option || answer == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("invalid choice %q", answer)
+}
+
+// parseChoices parses a comma-separated list of choices, "all" or "none".
+func parseChoices(answer string, options []string) ([]int, error) {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "all":
+		idx := make([]int, len(options))
+		for i := range idx {
+			idx[i] = i
+		}
+		return idx, nil
+	case "none", "":
+		return nil, nil
+	}
+
+	checked := make([]bool, len(options))
+	for _, choice := range strings.Split(answer, ",") {
+		i, err := parseChoice(choice, options)
+		if err != nil {
+			return nil, err
+		}
+		checked[i] = true
+	}
+	var idx []int
+	for i, c := range checked {
+		if c {
+			idx = append(idx, i)
+		}
+	}
+	return idx, nil
+}
+
+func parseYesNo(answer string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "true":
+		return true, nil
+	case "n", "no", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid answer %q, expected yes or no", answer)
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// MultiSelect displays a list of options of which any number can be
+// checked with space, 'a' checks or unchecks all of them, and enter
+// accepts the selection.
+//
+// Returns:
+//   - []int: The zero-based indexes of the checked options, in the order of `options`.
+//   - error: An error if no option is provided or the selection is canceled by the user.
+//
+// Example usage:
+//
+//	idx, err := cliui.MultiSelect("learners", "Select the learners to add:", []string{"etcd-vm2", "etcd-vm3"})
+func MultiSelect(key, title string, options []string) ([]int, error) {
+	return current().MultiSelect(key, title, options)
+}
+
+// MultiSelect shows the options as a list of checkboxes.
+func (TerminalPrompter) MultiSelect(_, title string, options []string) ([]int, error) {
+	if len(options) == 0 {
+		return nil, errors.New("no options provided")
+	}
+
+	m := &multiSelectModel{
+		title:   title,
+		options: options,
+		checked: make([]bool, len(options)),
+	}
+	if err := runPrompt(m); err != nil {
+		return nil, fmt.Errorf("error selecting from CLI menu: %w", err)
+	}
+	if m.cancelled {
+		return nil, ErrUserCancelled
+	}
+	return m.selected(), nil
+}
+
+type multiSelectModel struct {
+	title     string
+	options   []string
+	checked   []bool
+	cursor    int
+	done      bool
+	cancelled bool
+}
+
+func (m *multiSelectModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if isCancelKey(key) {
+		m.cancelled = true
+		return m, tea.Quit
+	}
+
+	switch key.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.options)-1 {
+			m.cursor++
+		}
+	case " ", "x":
+		m.checked[m.cursor] = !m.checked[m.cursor]
+	case "a":
+		all := len(m.selected()) == len(m.options)
+		for i := range m.checked {
+			m.checked[i] = !all
+		}
+	case "enter":
+		m.done = true
+		return m, tea.Quit
+	case "q":
+		m.cancelled = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *multiSelectModel) selected() []int {
+	var idx []int
+	for i, c := range m.checked {
+		if c {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+func (m *multiSelectModel) View() string {
+	if m.done || m.cancelled {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n" + promptStyle.Render(m.title) + "\n\n")
+	for i, option := range m.options {
+		box := "[ ]"
+		if m.checked[i] {
+			box = "[x]"
+		}
+		line := fmt.Sprintf("%s %d. %s", box, i+1, option)
+		if i == m.cursor {
+			sb.WriteString(selectedItemStyle.Render("> "+line) + "\n")
+		} else {
+			sb.WriteString(itemStyle.Render(line) + "\n")
+		}
+	}
+	sb.WriteString("\n" + hintStyle.Render("↑/↓ move • space check • a all • enter confirm • esc cancel") + "\n")
+	return sb.String()
+}
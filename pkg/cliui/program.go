@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrUserCancelled is returned when the user quits a prompt without
+// answering, or declines to go on.
+var ErrUserCancelled = errors.New("user cancelled")
+
+// TerminalPrompter shows the prompts with bubbletea.
+type TerminalPrompter struct{}
+
+var _ Prompter = TerminalPrompter{}
+
+// programOptions are passed to every prompt. Tests set them to feed the
+// key presses and to discard the output.
+var programOptions []tea.ProgramOption
+
+// runPrompt runs the model of a prompt until it quits, on top of the
+// dashboard if one is shown.
+func runPrompt(m tea.Model) error {
+	resume := suspendDashboard()
+	defer resume()
+
+	_, err := tea.NewProgram(m, programOptions...).Run()
+	return err
+}
+
+// isCancelKey returns true for the keys quitting a prompt without an
+// answer.
+func isCancelKey(msg tea.KeyMsg) bool {
+	return msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyEscape
+}
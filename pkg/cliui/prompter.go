@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+)
+
+// Prompter asks questions to the user. Every question has a key which
+// names it in an answers file, e.g. "seed" or "host-key.10.0.0.1".
+type Prompter interface {
+	Select(key, title string, options []string) (int, error)
+	MultiSelect(key, title string, options []string) ([]int, error)
+	Confirm(key, question string, defaultYes bool) (bool, error)
+	Input(key, prompt, placeholder string, validate func(string) error) (string, error)
+	Review(key, title, content string) (bool, error)
+}
+
+// MissingAnswerError is returned when a question must be answered but
+// there is nobody to ask, e.g. in unattended mode.
+type MissingAnswerError struct {
+	Key      string
+	Question string
+}
+
+func (e *MissingAnswerError) Error() string {
+	return fmt.Sprintf("no answer provided for prompt %q (%s)", e.Key, e.Question)
+}
+
+var (
+	prompterMu sync.Mutex
+	prompter   Prompter
+
+	stdinPrompter *LinePrompter
+	stdinFile     *os.File
+)
+
+// SetPrompter sets the prompter used by the functions of this package. If
+// p is nil, DefaultPrompter is used.
+func SetPrompter(p Prompter) {
+	prompterMu.Lock()
+	defer prompterMu.Unlock()
+	prompter = p
+}
+
+// DefaultPrompter returns a TerminalPrompter if both stdin and stdout are
+// terminals, and a LinePrompter reading stdin otherwise, e.g. when the
+// input is piped or the tool runs under `script` or in a container.
+func DefaultPrompter() Prompter {
+	if isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd()) {
+		return TerminalPrompter{}
+	}
+
+	prompterMu.Lock()
+	defer prompterMu.Unlock()
+	// Keep the same reader as long as stdin is the same file, so that the
+	// lines buffered for the next prompts aren't lost.
+	if stdinPrompter == nil || stdinFile != os.Stdin {
+		stdinPrompter = NewLinePrompter(os.Stdin, os.Stdout)
+		stdinFile = os.Stdin
+	}
+	return stdinPrompter
+}
+
+func current() Prompter {
+	prompterMu.Lock()
+	p := prompter
+	prompterMu.Unlock()
+
+	if p != nil {
+		return p
+	}
+	return DefaultPrompter()
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var hosts = []string{"etcd-vm1 (10.0.0.1)", "etcd-vm2 (10.0.0.2)", "etcd-vm3 (10.0.0.3)"}
+
+func TestLinePrompterSelect(t *testing.T) {
+	var out strings.Builder
+	p := NewLinePrompter(strings.NewReader("4\netcd-vm2\n"), &out)
+
+	idx, err := p.Select("seed", "Select the seed member:", hosts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx)
+	assert.Contains(t, out.String(), "  3. etcd-vm3 (10.0.0.3)\n")
+	assert.Contains(t, out.String(), "choice 4 out of range [1, 3]")
+
+	_, err = p.Select("seed", "Select the seed member:", hosts)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestLinePrompterMultiSelect(t *testing.T) {
+	p := NewLinePrompter(strings.NewReader("3, 1\nall\nnone\n"), io.Discard)
+
+	idx, err := p.MultiSelect("learners", "Select the learners:", hosts)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, idx)
+
+	idx, err = p.MultiSelect("learners", "Select the learners:", hosts)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, idx)
+
+	idx, err = p.MultiSelect("learners", "Select the learners:", hosts)
+	require.NoError(t, err)
+	assert.Empty(t, idx)
+}
+
+func TestLinePrompterConfirm(t *testing.T) {
+	p := NewLinePrompter(strings.NewReader("\nmaybe\nyes\n\n"), io.Discard)
+
+	ok, err := p.Confirm("delete-data-dir", "Delete the data directory?", false)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = p.Confirm("delete-data-dir", "Delete the data directory?", false)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = p.Review("remove-members", "Members to remove", "etcd-vm4 (10.0.0.4)\n")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestLinePrompterInput(t *testing.T) {
+	var out strings.Builder
+	p := NewLinePrompter(strings.NewReader("vm4\netcd-vm4"), &out)
+	validate := func(s string) error {
+		if !strings.HasPrefix(s, "etcd-") {
+			return errors.New("the name must start with etcd-")
+		}
+		return nil
+	}
+
+	got, err := p.Input("member-name", "Member name:", "", validate)
+	require.NoError(t, err)
+	assert.Equal(t, "etcd-vm4", got)
+	assert.Contains(t, out.String(), "the name must start with etcd-")
+}
+
+func TestScriptedPrompter(t *testing.T) {
+	p := NewScriptedPrompter(map[string]string{
+		"seed":            "etcd-vm1",
+		"learners":        "2,3",
+		"delete-data-dir": "yes",
+		"member-name":     "etcd-vm4",
+	}, nil)
+
+	idx, err := p.Select("seed", "Select the seed member:", hosts)
+	require.NoError(t, err)
+	assert.Equal(t, 0, idx)
+
+	learners, err := p.MultiSelect("learners", "Select the learners:", hosts)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, learners)
+
+	ok, err := p.Confirm("delete-data-dir", "Delete the data directory?", false)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	name, err := p.Input("member-name", "Member name:", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "etcd-vm4", name)
+
+	_, err = p.Confirm("host-key.10.0.0.1", "Trust the host key?", false)
+	var missing *MissingAnswerError
+	require.ErrorAs(t, err, &missing)
+	assert.Equal(t, "host-key.10.0.0.1", missing.Key)
+	assert.EqualError(t, err, `no answer provided for prompt "host-key.10.0.0.1" (Trust the host key?)`)
+
+	_, err = NewScriptedPrompter(map[string]string{"seed": "etcd-vm9"}, nil).Select("seed", "Select the seed member:", hosts)
+	assert.EqualError(t, err, `invalid answer for prompt "seed": invalid choice "etcd-vm9"`)
+}
+
+func TestScriptedPrompterFallback(t *testing.T) {
+	fallback := NewLinePrompter(strings.NewReader("2\n"), io.Discard)
+	p := NewScriptedPrompter(map[string]string{"learners": "none"}, fallback)
+
+	idx, err := p.Select("seed", "Select the seed member:", hosts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx)
+
+	learners, err := p.MultiSelect("learners", "Select the learners:", hosts)
+	require.NoError(t, err)
+	assert.Empty(t, learners)
+}
+
+func TestLoadAnswers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"seed": "etcd-vm1", "learners": "all"}`), 0o600))
+
+	answers, err := LoadAnswers(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"seed": "etcd-vm1", "learners": "all"}, answers)
+
+	require.NoError(t, os.WriteFile(path, []byte(`["etcd-vm1"]`), 0o600))
+	_, err = LoadAnswers(path)
+	require.ErrorContains(t, err, "failed to parse answers file")
+}
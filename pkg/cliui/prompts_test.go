@@ -0,0 +1,146 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	keyDown  = "\x1b[B"
+	keyEnter = "\r"
+	keyCtrlC = "\x03"
+)
+
+// withKeys feeds keys to the next prompts instead of the terminal.
+func withKeys(t *testing.T, keys ...string) {
+	t.Helper()
+	SetPrompter(TerminalPrompter{})
+	programOptions = []tea.ProgramOption{
+		tea.WithInput(strings.NewReader(strings.Join(keys, ""))),
+		tea.WithOutput(io.Discard),
+		tea.WithoutSignalHandler(),
+	}
+	t.Cleanup(func() {
+		SetPrompter(nil)
+		programOptions = nil
+	})
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys       []string
+		defaultYes bool
+		want       bool
+		wantErr    error
+	}{
+		{name: "default no", keys: []string{keyEnter}, want: false},
+		{name: "default yes", keys: []string{keyEnter}, defaultYes: true, want: true},
+		{name: "toggle", keys: []string{"\t", keyEnter}, want: true},
+		{name: "answer y", keys: []string{"y"}, want: true},
+		{name: "answer n", keys: []string{"n"}, defaultYes: true, want: false},
+		{name: "cancel", keys: []string{keyCtrlC}, wantErr: ErrUserCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withKeys(t, tt.keys...)
+			got, err := Confirm("delete-data-dir", "Delete the data directory?", tt.defaultYes)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMultiSelect(t *testing.T) {
+	options := []string{"etcd-vm2", "etcd-vm3", "etcd-vm4"}
+
+	withKeys(t, " ", keyDown, keyDown, " ", keyEnter)
+	idx, err := MultiSelect("learners", "Select the learners:", options)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, idx)
+
+	withKeys(t, "a", keyEnter)
+	idx, err = MultiSelect("learners", "Select the learners:", options)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, idx)
+
+	withKeys(t, "a", "a", keyEnter)
+	idx, err = MultiSelect("learners", "Select the learners:", options)
+	require.NoError(t, err)
+	assert.Empty(t, idx)
+
+	withKeys(t, "q")
+	_, err = MultiSelect("learners", "Select the learners:", options)
+	require.ErrorIs(t, err, ErrUserCancelled)
+
+	_, err = MultiSelect("learners", "Select the learners:", nil)
+	require.EqualError(t, err, "no options provided")
+}
+
+func TestMultiSelectView(t *testing.T) {
+	m := &multiSelectModel{title: "Select the learners:", options: []string{"etcd-vm2", "etcd-vm3"}, checked: []bool{false, true}}
+	view := m.View()
+	assert.Contains(t, view, "> [ ] 1. etcd-vm2")
+	assert.Contains(t, view, "[x] 2. etcd-vm3")
+}
+
+func TestInput(t *testing.T) {
+	withKeys(t, "etcd-vm4", keyEnter)
+	got, err := Input("member-name", "Member name:", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "etcd-vm4", got)
+
+	// enter is ignored until the value is valid
+	validate := func(s string) error {
+		if !strings.HasPrefix(s, "etcd-") {
+			return errors.New("the name must start with etcd-")
+		}
+		return nil
+	}
+	withKeys(t, "vm4", keyEnter, "\x7f\x7f\x7fetcd-vm4", keyEnter)
+	got, err = Input("member-name", "Member name:", "", validate)
+	require.NoError(t, err)
+	assert.Equal(t, "etcd-vm4", got)
+
+	withKeys(t, "vm4", keyCtrlC)
+	_, err = Input("member-name", "Member name:", "", nil)
+	require.ErrorIs(t, err, ErrUserCancelled)
+}
+
+func TestInputShowsValidationError(t *testing.T) {
+	m := &inputModel{validate: func(string) error { return errors.New("name is required") }}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, m.done)
+	assert.Contains(t, m.View(), "name is required")
+}
+
+func TestReview(t *testing.T) {
+	withKeys(t, keyDown, keyEnter)
+	ok, err := Review("remove-members", "Members to remove", "etcd-vm4 (10.0.0.4)\n")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	withKeys(t, "n")
+	ok, err = Diff("manifest", "Manifest changes", "a\nb\n", "a\nc\n")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	withKeys(t, keyCtrlC)
+	_, err = Review("remove-members", "Members to remove", "etcd-vm4 (10.0.0.4)\n")
+	require.ErrorIs(t, err, ErrUserCancelled)
+}
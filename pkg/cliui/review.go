@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/vmware/etcd-recovery/pkg/diff"
+)
+
+var (
+	addedLineStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	removedLineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+// Review shows content, e.g. the changes about to be applied, in a
+// scrollable read-only pane. It returns true if the user accepts with
+// enter or 'y', and false if the user rejects with 'n'.
+//
+// Example usage:
+//
+//	ok, err := cliui.Review("remove-members", "Members to remove", "etcd-vm4 (10.0.0.4)\n")
+func Review(key, title, content string) (bool, error) {
+	return current().Review(key, title, content)
+}
+
+// Review shows content in a bubbletea viewport.
+func (TerminalPrompter) Review(_, title, content string) (bool, error) {
+	m := newReviewModel(title, content)
+	if err := runPrompt(m); err != nil {
+		return false, fmt.Errorf("error showing review: %w", err)
+	}
+	if m.cancelled {
+		return false, ErrUserCancelled
+	}
+	return m.accepted, nil
+}
+
+// Diff is the same as Review, showing the line by line changes from
+// before to after.
+func Diff(key, title, before, after string) (bool, error) {
+	return Review(key, title, RenderDiff(before, after))
+}
+
+// RenderDiff returns the lines of before and after prefixed with '-' if
+// they were removed, '+' if they were added and ' ' otherwise.
+func RenderDiff(before, after string) string {
+	var sb strings.Builder
+	for _, l := range diff.Lines(before, after) {
+		switch l[0] {
+		case '+':
+			sb.WriteString(addedLineStyle.Render(l))
+		case '-':
+			sb.WriteString(removedLineStyle.Render(l))
+		default:
+			sb.WriteString(l)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+type reviewModel struct {
+	title     string
+	pane      viewport.Model
+	accepted  bool
+	done      bool
+	cancelled bool
+}
+
+func newReviewModel(title, content string) *reviewModel {
+	pane := viewport.New(defaultWidth*4, listHeight)
+	pane.SetContent(content)
+	return &reviewModel{title: title, pane: pane}
+}
+
+func (m *reviewModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *reviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.pane.Width = msg.Width
+		m.pane.Height = max(msg.Height-6, 3)
+		return m, nil
+
+	case tea.KeyMsg:
+		if isCancelKey(msg) {
+			m.cancelled = true
+			return m, tea.Quit
+		}
+		switch msg.String() {
+		case "enter", "y":
+			m.accepted, m.done = true, true
+			return m, tea.Quit
+		case "n":
+			m.done = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.pane, cmd = m.pane.Update(msg)
+	return m, cmd
+}
+
+func (m *reviewModel) View() string {
+	if m.done || m.cancelled {
+		return ""
+	}
+	return fmt.Sprintf("\n%s\n\n%s\n\n%s\n",
+		promptStyle.Render(m.title), m.pane.View(),
+		hintStyle.Render("↑/↓ scroll • enter/y accept • n reject • esc cancel"))
+}
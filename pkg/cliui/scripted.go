@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ScriptedPrompter answers the questions from a map of key to answer, and
+// asks the fallback prompter the questions it has no answer for. If the
+// fallback is nil, a missing answer is a MissingAnswerError.
+//
+// The answers are written the way they would be typed in a LinePrompter:
+//   - Select: the number or the name of the option, e.g. "etcd-vm1".
+//   - MultiSelect: a comma-separated list of options, "all" or "none".
+//   - Confirm and Review: "yes" or "no".
+//   - Input: the text itself.
+type ScriptedPrompter struct {
+	answers  map[string]string
+	fallback Prompter
+}
+
+var _ Prompter = (*ScriptedPrompter)(nil)
+
+// NewScriptedPrompter returns a ScriptedPrompter with the given answers.
+func NewScriptedPrompter(answers map[string]string, fallback Prompter) *ScriptedPrompter {
+	return &ScriptedPrompter{answers: answers, fallback: fallback}
+}
+
+// LoadAnswers reads a JSON object of key to answer, e.g.
+//
+//	{"seed": "etcd-vm1", "learners": "all"}
+func LoadAnswers(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers file: %w", err)
+	}
+	answers := map[string]string{}
+	if err := json.Unmarshal(data, &answers); err != nil {
+		return nil, fmt.Errorf("failed to parse answers file %s: %w", path, err)
+	}
+	return answers, nil
+}
+
+func (p *ScriptedPrompter) Select(key, title string, options []string) (int, error) {
+	answer, ok := p.answers[key]
+	if !ok {
+		if p.fallback == nil {
+			return -1, &MissingAnswerError{Key: key, Question: title}
+		}
+		return p.fallback.Select(key, title, options)
+	}
+	idx, err := parseChoice(answer, options)
+	if err != nil {
+		return -1, fmt.Errorf("invalid answer for prompt %q: %w", key, err)
+	}
+	return idx, nil
+}
+
+func (p *ScriptedPrompter) MultiSelect(key, title string, options []string) ([]int, error) {
+	answer, ok := p.answers[key]
+	if !ok {
+		if p.fallback == nil {
+			return nil, &MissingAnswerError{Key: key, Question: title}
+		}
+		return p.fallback.MultiSelect(key, title, options)
+	}
+	idx, err := parseChoices(answer, options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid answer for prompt %q: %w", key, err)
+	}
+	return idx, nil
+}
+
+func (p *ScriptedPrompter) Confirm(key, question string, defaultYes bool) (bool, error) {
+	answer, ok := p.answers[key]
+	if !ok {
+		if p.fallback == nil {
+			return false, &MissingAnswerError{Key: key, Question: question}
+		}
+		return p.fallback.Confirm(key, question, defaultYes)
+	}
+	yes, err := parseYesNo(answer)
+	if err != nil {
+		return false, fmt.Errorf("invalid answer for prompt %q: %w", key, err)
+	}
+	return yes, nil
+}
+
+func (p *ScriptedPrompter) Input(key, prompt, placeholder string, validate func(string) error) (string, error) {
+	answer, ok := p.answers[key]
+	if !ok {
+		if p.fallback == nil {
+			return "", &MissingAnswerError{Key: key, Question: prompt}
+		}
+		return p.fallback.Input(key, prompt, placeholder, validate)
+	}
+	if validate != nil {
+		if err := validate(answer); err != nil {
+			return "", fmt.Errorf("invalid answer for prompt %q: %w", key, err)
+		}
+	}
+	return answer, nil
+}
+
+func (p *ScriptedPrompter) Review(key, title, content string) (bool, error) {
+	if _, ok := p.answers[key]; !ok && p.fallback != nil {
+		return p.fallback.Review(key, title, content)
+	}
+	return p.Confirm(key, title, true)
+}
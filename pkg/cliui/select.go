@@ -6,10 +6,9 @@ package cliui
 
 import (
 	"errors"
-	"log"
+	"fmt"
 
 	"github.com/charmbracelet/bubbles/list"
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -28,6 +27,7 @@ var (
 // and a list of options, allowing the user to choose one of them.
 //
 // Parameters:
+//   - key: The name of the question in an answers file.
 //   - title: A string that will be displayed as the heading for the menu.
 //   - options: A slice of strings representing the selectable options in the menu.
 //
@@ -39,20 +39,29 @@ var (
 // Example usage:
 //
 //	options := []string{"etcd-vm1", "etcd-vm2", "etcd-vm3"}
-//	idx, choice, err := cliui.Select("Please choose one VM:", options)
+//	idx, choice, err := cliui.Select("vm", "Please choose one VM:", options)
 //	if err != nil {
 //	    fmt.Println("Selection canceled or failed:", err)
 //	    return
 //	}
 //	fmt.Printf("You selected option %d: %s\n", idx, choice)
-func Select(title string, options []string) (int, string, error) {
+func Select(key, title string, options []string) (int, string, error) {
+	idx, err := current().Select(key, title, options)
+	if err != nil {
+		return -1, "", err
+	}
+	return idx, options[idx], nil
+}
+
+// Select shows the options in a bubbletea list.
+func (TerminalPrompter) Select(_, title string, options []string) (int, error) {
 	var items []list.Item
 	for _, option := range options {
 		items = append(items, item(option))
 	}
 
 	if len(items) == 0 {
-		return -1, "", errors.New("no options provided")
+		return -1, errors.New("no options provided")
 	}
 
 	l := list.New(items, itemDelegate{}, defaultWidth, listHeight)
@@ -68,13 +77,13 @@ func Select(title string, options []string) (int, string, error) {
 		index: -1,
 	}
 
-	if _, err := tea.NewProgram(m).Run(); err != nil {
-		log.Fatalf("error selecting from CLI menu: %v", err)
+	if err := runPrompt(m); err != nil {
+		return -1, fmt.Errorf("error selecting from CLI menu: %w", err)
 	}
 
 	if m.quitting {
-		return -1, "", errors.New("user cancelled")
+		return -1, ErrUserCancelled
 	}
 
-	return m.index, m.choice, nil
+	return m.index, nil
 }
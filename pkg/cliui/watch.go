@@ -0,0 +1,183 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var changedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+
+// WatchCell is a cell of the table shown by Watch.
+type WatchCell struct {
+	Text string
+	// Changed highlights the cell, e.g. if it changed since the previous
+	// refresh.
+	Changed bool
+}
+
+// WatchView is the content shown by Watch after a refresh.
+type WatchView struct {
+	Header []string
+	Rows   [][]WatchCell
+	// Changes are highlighted below the table.
+	Changes []string
+	// Notes are dimmed below the changes, e.g. the errors.
+	Notes []string
+}
+
+// Watch shows the view returned by refresh in full screen, calling
+// refresh again interval after the previous refresh returned, until the
+// user quits with 'q', esc or ctrl+c.
+//
+// Example usage:
+//
+//	err := cliui.Watch("etcd status", 2*time.Second, func() cliui.WatchView {
+//		return cliui.WatchView{Header: []string{"HOST"}, Rows: [][]cliui.WatchCell{{{Text: "vm1"}}}}
+//	})
+func Watch(title string, interval time.Duration, refresh func() WatchView) error {
+	m := newWatchModel(title, interval, refresh)
+	opts := append([]tea.ProgramOption{tea.WithAltScreen()}, programOptions...)
+	if _, err := tea.NewProgram(m, opts...).Run(); err != nil {
+		return fmt.Errorf("error showing watch view: %w", err)
+	}
+	return nil
+}
+
+type (
+	watchRefreshedMsg struct {
+		view WatchView
+		at   time.Time
+	}
+	watchTickMsg struct{}
+)
+
+type watchModel struct {
+	title      string
+	interval   time.Duration
+	refresh    func() WatchView
+	view       WatchView
+	refreshed  time.Time
+	refreshing bool
+}
+
+func newWatchModel(title string, interval time.Duration, refresh func() WatchView) *watchModel {
+	return &watchModel{title: title, interval: interval, refresh: refresh}
+}
+
+func (m *watchModel) Init() tea.Cmd {
+	return m.refreshCmd()
+}
+
+// refreshCmd refreshes the view outside of the update loop, as it may
+// take a while to connect to the hosts.
+func (m *watchModel) refreshCmd() tea.Cmd {
+	m.refreshing = true
+	return func() tea.Msg {
+		view := m.refresh()
+		return watchRefreshedMsg{view: view, at: time.Now()}
+	}
+}
+
+func (m *watchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if isCancelKey(msg) || msg.String() == "q" {
+			return m, tea.Quit
+		}
+
+	case watchRefreshedMsg:
+		m.view = msg.view
+		m.refreshed = msg.at
+		m.refreshing = false
+		return m, tea.Tick(m.interval, func(time.Time) tea.Msg { return watchTickMsg{} })
+
+	case watchTickMsg:
+		return m, m.refreshCmd()
+	}
+	return m, nil
+}
+
+func (m *watchModel) View() string {
+	var sb strings.Builder
+
+	sb.WriteString(dashboardTitleStyle.Render(m.title))
+	status := fmt.Sprintf("  every %v, q to quit", m.interval)
+	if !m.refreshed.IsZero() {
+		status = fmt.Sprintf("  refreshed at %s, every %v, q to quit", m.refreshed.Format(time.TimeOnly), m.interval)
+	}
+	if m.refreshing {
+		status += ", refreshing..."
+	}
+	sb.WriteString(dashboardDimStyle.Render(status))
+	sb.WriteString("\n\n")
+	sb.WriteString(renderWatchTable(m.view.Header, m.view.Rows))
+
+	if len(m.view.Changes) > 0 {
+		sb.WriteString("\n")
+		for _, c := range m.view.Changes {
+			sb.WriteString(changedStyle.Render(c))
+			sb.WriteString("\n")
+		}
+	}
+	if len(m.view.Notes) > 0 {
+		sb.WriteString("\n")
+		for _, n := range m.view.Notes {
+			sb.WriteString(dashboardDimStyle.Render(n))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// renderWatchTable aligns the columns before styling the changed cells,
+// as the escape sequences would be counted in the width otherwise.
+func renderWatchTable(header []string, rows [][]WatchCell) string {
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = len(h)
+	}
+	for _, row := range rows {
+		for i, c := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], len(c.Text))
+			}
+		}
+	}
+
+	var sb strings.Builder
+	for i, h := range header {
+		writeWatchCell(&sb, WatchCell{Text: h}, widths[i], i == len(header)-1)
+	}
+	for _, row := range rows {
+		for i, c := range row {
+			if i < len(widths) {
+				writeWatchCell(&sb, c, widths[i], i == len(row)-1)
+			}
+		}
+	}
+	return sb.String()
+}
+
+func writeWatchCell(sb *strings.Builder, c WatchCell, width int, last bool) {
+	text := c.Text
+	if !last {
+		text = fmt.Sprintf("%-*s", width, text)
+	}
+	if c.Changed {
+		text = changedStyle.Render(text)
+	}
+	sb.WriteString(text)
+	if last {
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString("  ")
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package cliui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchRefresh(t *testing.T) {
+	refreshes := 0
+	m := newWatchModel("etcd status", 2*time.Second, func() WatchView {
+		refreshes++
+		return WatchView{
+			Header:  []string{"HOST", "LEADER"},
+			Rows:    [][]WatchCell{{{Text: "vm1"}, {Text: "1000", Changed: true}}},
+			Changes: []string{"new leader 1000"},
+			Notes:   []string{"vm2: connection refused"},
+		}
+	})
+
+	cmd := m.Init()
+	require.NotNil(t, cmd)
+	assert.True(t, m.refreshing)
+	assert.Contains(t, m.View(), "refreshing...")
+
+	msg := cmd()
+	assert.Equal(t, 1, refreshes)
+	_, cmd = m.Update(msg)
+	require.NotNil(t, cmd)
+	assert.False(t, m.refreshing)
+
+	view := m.View()
+	assert.Contains(t, view, "refreshed at")
+	assert.Contains(t, view, "HOST  LEADER")
+	assert.Contains(t, view, "new leader 1000")
+	assert.Contains(t, view, "vm2: connection refused")
+
+	_, cmd = m.Update(watchTickMsg{})
+	require.NotNil(t, cmd)
+	cmd()
+	assert.Equal(t, 2, refreshes)
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	require.NotNil(t, cmd)
+	assert.Equal(t, tea.QuitMsg{}, cmd())
+}
+
+func TestRenderWatchTable(t *testing.T) {
+	out := renderWatchTable([]string{"HOST", "LEADER", "INDEX"}, [][]WatchCell{
+		{{Text: "etcd-vm1"}, {Text: "1000"}, {Text: "100"}},
+		{{Text: "vm2"}, {Text: "1000 (self)"}, {Text: "99"}},
+	})
+	assert.Equal(t, []string{
+		"HOST      LEADER       INDEX",
+		"etcd-vm1  1000         100",
+		"vm2       1000 (self)  99",
+		"",
+	}, strings.Split(out, "\n"))
+}
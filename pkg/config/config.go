@@ -6,15 +6,21 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/vmware/etcd-recovery/pkg/ssh"
+	"github.com/vmware/etcd-recovery/pkg/transport"
 )
 
 const DefaultConfigFilename = "hosts.json"
 
+// ErrInvalidConfig is returned when the hosts config file can't be read,
+// or is invalid.
+var ErrInvalidConfig = errors.New("invalid hosts config")
+
 type Host struct {
 	Name             string `json:"name"`
 	MemberName       string `json:"member_name,omitempty"`
@@ -24,17 +30,21 @@ type Host struct {
 	PrivateKey       string `json:"private_key,omitempty"`
 	Passphrase       string `json:"passphrase,omitempty"`
 	BackedupManifest string `json:"backedup_manifest"`
+
+	// Local is set when the host is the machine etcd-recovery is
+	// running on, in which case it is driven without SSH.
+	Local bool `json:"-"`
 }
 
 func ParseHostFromFile(path string) ([]*Host, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("read file failed: %w", err)
+		return nil, fmt.Errorf("%w: read file failed: %w", ErrInvalidConfig, err)
 	}
 
 	var hosts []*Host
 	if err := json.Unmarshal(data, &hosts); err != nil {
-		return nil, fmt.Errorf("unmarshal json failed: %w", err)
+		return nil, fmt.Errorf("%w: unmarshal json failed: %w", ErrInvalidConfig, err)
 	}
 
 	return hosts, nil
@@ -47,6 +57,29 @@ func (h *Host) FetchMemberName() (string, error) {
 		return h.MemberName, nil
 	}
 
+	client, err := h.Connect()
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to host %s to fetch hostname: %w", h.Host, err)
+	}
+	defer client.Close()
+
+	res, err := client.Exec("hostname", transport.ExecOptions{})
+	if err == nil {
+		err = res.Err()
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch hostname of remote machine: %w", err)
+	}
+	return strings.TrimSpace(string(res.Stdout)), nil
+}
+
+// Connect returns a transport to the host. A local transport is returned
+// if the host is marked as Local, otherwise an SSH connection is made.
+func (h *Host) Connect() (transport.Transport, error) {
+	if h.Local {
+		return transport.NewLocal(), nil
+	}
+
 	client, err := ssh.NewClient(&ssh.Config{
 		User:                 h.Username,
 		Host:                 h.Host,
@@ -55,13 +88,7 @@ func (h *Host) FetchMemberName() (string, error) {
 		PrivateKeyPassphrase: h.Passphrase,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to connect to host %s to fetch hostname: %w", h.Host, err)
-	}
-	defer client.Close()
-
-	out, err := client.Run("hostname")
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch hostname of remote machine: %w", err)
+		return nil, err
 	}
-	return strings.TrimSpace(string(out)), nil
+	return client, nil
 }
@@ -79,3 +79,15 @@ func TestParseHostFromFile(t *testing.T) {
 
 	require.Equal(t, want, got)
 }
+
+func TestConnectLocalHost(t *testing.T) {
+	h := &Host{Name: "etcd-vm1", Host: "127.0.0.1", Local: true}
+
+	client, err := h.Connect()
+	require.NoError(t, err)
+	defer client.Close()
+
+	out, err := client.Run("echo ok")
+	require.NoError(t, err)
+	require.Equal(t, "ok\n", string(out))
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	before := "- etcd\n- --data-dir=/var/lib/etcd\n- --force-new-cluster\n- --name=vm1\n"
+	after := "- etcd\n- --data-dir=/var/lib/etcd\n- --name=vm1\n- --initial-cluster-state=existing\n"
+
+	assert.Equal(t, []string{
+		" - etcd",
+		" - --data-dir=/var/lib/etcd",
+		"-- --force-new-cluster",
+		" - --name=vm1",
+		"+- --initial-cluster-state=existing",
+	}, Lines(before, after))
+
+	assert.Equal(t, []string{"+a"}, Lines("", "a"))
+	assert.Equal(t, []string{"-a"}, Lines("a\n", ""))
+	assert.Equal(t, " a\n-b\n+c\n", Unified("a\nb\n", "a\nc\n"))
+}
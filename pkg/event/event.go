@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+// Package event defines the events emitted while an execution plan runs,
+// and the observers receiving them. The plan emits the step events, the
+// transport handed to the tasks emits a CommandRun for every command, and
+// the tasks emit the events about the cluster they change.
+package event
+
+import "time"
+
+// Meta is common to all events. The plan fills in the fields that the
+// emitter leaves empty.
+type Meta struct {
+	Time time.Time
+	Plan string
+	Step string
+	// Host is the address of the host the event is about.
+	Host string
+}
+
+// Metadata returns the common fields of the event.
+func (m *Meta) Metadata() *Meta {
+	return m
+}
+
+// Event is one of the event types of this package.
+type Event interface {
+	Metadata() *Meta
+}
+
+// PlanStarted is emitted before the first step of a plan starts.
+type PlanStarted struct {
+	Meta
+	Steps []string
+}
+
+// PlanFinished is emitted once all the steps of a plan are done.
+type PlanFinished struct {
+	Meta
+	Duration time.Duration
+	Err      error
+}
+
+// StepStarted is emitted when a step starts on its host.
+type StepStarted struct {
+	Meta
+}
+
+// StepFinished is emitted when a step succeeded, failed or was skipped.
+type StepFinished struct {
+	Meta
+	Status   string
+	Duration time.Duration
+	Err      error
+}
+
+// CommandRun is emitted after a command ran on a host.
+type CommandRun struct {
+	Meta
+	Command string
+	// ExitCode is -1 if the command couldn't be run to the end.
+	ExitCode int
+	Stdout   []byte
+	Stderr   []byte
+	Duration time.Duration
+	Err      error
+}
+
+// FileTransferred is emitted after a file was uploaded to or downloaded
+// from a host.
+type FileTransferred struct {
+	Meta
+	// Direction is either "upload" or "download".
+	Direction  string
+	LocalPath  string
+	RemotePath string
+	// SHA256 is the hex encoded checksum of the local file, empty if the
+	// transfer failed or the file couldn't be read afterwards.
+	SHA256   string
+	Size     int64
+	Duration time.Duration
+	Err      error
+}
+
+// Retry is emitted when a command or a check is tried again.
+type Retry struct {
+	Meta
+	Description string
+	// Attempt is the number of the attempt that failed, starting at 1.
+	Attempt int
+	// MaxAttempts is zero if the number of attempts is only bounded by a
+	// timeout.
+	MaxAttempts int
+	Wait        time.Duration
+	Err         error
+}
+
+// ManifestChanged is emitted after the etcd static pod manifest of a
+// host was replaced.
+type ManifestChanged struct {
+	Meta
+	Path   string
+	Reason string
+	// Old is the manifest the new one was derived from.
+	Old []byte
+	New []byte
+}
+
+// SeedStarted is emitted when etcd runs, or was started with
+// --force-new-cluster, as the single member of the cluster on the seed.
+type SeedStarted struct {
+	Meta
+}
+
+// MemberAdded is emitted after a member was added to the cluster.
+type MemberAdded struct {
+	Meta
+	MemberID  uint64
+	Name      string
+	PeerURL   string
+	IsLearner bool
+}
+
+// MemberRemoved is emitted after a member was removed from the cluster.
+type MemberRemoved struct {
+	Meta
+	MemberID uint64
+}
+
+// LearnerPromoted is emitted after a learner became a voting member.
+type LearnerPromoted struct {
+	Meta
+	MemberID uint64
+}
+
+// Warning is emitted when a task works around an unexpected state of the
+// cluster, e.g. by removing a learner that isn't in the hosts config.
+type Warning struct {
+	Meta
+	Message string
+}
+
+// ContainerLogs is emitted with the last lines of the logs of an etcd
+// container, captured when etcd failed to start or to become healthy.
+type ContainerLogs struct {
+	Meta
+	ContainerID string
+	// Reason is the failure the logs were captured for.
+	Reason string
+	Lines  []string
+}
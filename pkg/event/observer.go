@@ -0,0 +1,33 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package event
+
+// Observer receives the events of a plan. The plan delivers the events
+// one at a time, so observers don't need to be safe for concurrent use,
+// but they should return quickly since the steps wait for them.
+type Observer interface {
+	OnEvent(ev Event)
+}
+
+// ObserverFunc adapts a function to the Observer interface.
+type ObserverFunc func(ev Event)
+
+func (f ObserverFunc) OnEvent(ev Event) {
+	f(ev)
+}
+
+// Emitter is implemented by the transports handed to the tasks by a
+// plan, so that the tasks can emit events without knowing the observers.
+type Emitter interface {
+	Emit(ev Event)
+}
+
+// Emit sends ev through t if it is an Emitter, and drops it otherwise,
+// e.g. when a task is run outside of a plan.
+func Emit(t any, ev Event) {
+	if e, ok := t.(Emitter); ok {
+		e.Emit(ev)
+	}
+}
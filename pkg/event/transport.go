@@ -0,0 +1,139 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package event
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"time"
+
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// Transport wraps the transport of a step. It emits a CommandRun event
+// for every command run through it and a FileTransferred event for every
+// file copied, and passes the events emitted by the
+// tasks to the observers of the plan.
+type Transport struct {
+	transport.Transport
+
+	host string
+	emit func(ev Event)
+}
+
+var (
+	_ transport.Transport = (*Transport)(nil)
+	_ Emitter             = (*Transport)(nil)
+)
+
+// NewTransport wraps client, which is connected to host.
+func NewTransport(client transport.Transport, host string, emit func(ev Event)) *Transport {
+	return &Transport{Transport: client, host: host, emit: emit}
+}
+
+// Attach returns client wrapped to emit events to the same observers as
+// parent, or client itself if parent isn't observed. Tasks use it for
+// the extra connections they open to other hosts.
+func Attach(parent, client transport.Transport, host string) transport.Transport {
+	if p, ok := parent.(*Transport); ok {
+		return NewTransport(client, host, p.emit)
+	}
+	return client
+}
+
+// Host returns the address of the host the transport is connected to.
+func (t *Transport) Host() string {
+	return t.host
+}
+
+// Emit sends ev to the observers. The host defaults to the host of the
+// transport.
+func (t *Transport) Emit(ev Event) {
+	if m := ev.Metadata(); m.Host == "" {
+		m.Host = t.host
+	}
+	t.emit(ev)
+}
+
+func (t *Transport) Run(cmd string) ([]byte, error) {
+	start := time.Now()
+	out, err := t.Transport.Run(cmd)
+	code, _ := transport.ExitStatus(err)
+	t.Emit(&CommandRun{
+		Command:  cmd,
+		ExitCode: code,
+		Stdout:   out,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+	return out, err
+}
+
+func (t *Transport) Exec(cmd string, opts transport.ExecOptions) (*transport.Result, error) {
+	start := time.Now()
+	res, err := t.Transport.Exec(cmd, opts)
+	ev := &CommandRun{
+		Command:  cmd,
+		ExitCode: -1,
+		Duration: time.Since(start),
+		Err:      err,
+	}
+	if err == nil {
+		ev.ExitCode = res.ExitCode
+		ev.Stdout = res.Stdout
+		ev.Stderr = res.Stderr
+		ev.Err = res.Err()
+	}
+	t.Emit(ev)
+	return res, err
+}
+
+func (t *Transport) Upload(localPath string, remotePath string) error {
+	start := time.Now()
+	err := t.Transport.Upload(localPath, remotePath)
+	t.emitTransfer("upload", localPath, remotePath, start, err)
+	return err
+}
+
+func (t *Transport) Download(remotePath string, localPath string) error {
+	start := time.Now()
+	err := t.Transport.Download(remotePath, localPath)
+	t.emitTransfer("download", localPath, remotePath, start, err)
+	return err
+}
+
+func (t *Transport) emitTransfer(direction, localPath, remotePath string, start time.Time, err error) {
+	ev := &FileTransferred{
+		Direction:  direction,
+		LocalPath:  localPath,
+		RemotePath: remotePath,
+		Duration:   time.Since(start),
+		Err:        err,
+	}
+	if err == nil {
+		if sum, size, cErr := checksum(localPath); cErr == nil {
+			ev.SHA256, ev.Size = sum, size
+		}
+	}
+	t.Emit(ev)
+}
+
+// checksum returns the SHA-256 and the size of the file at path.
+func checksum(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
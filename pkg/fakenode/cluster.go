@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package fakenode
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// Cluster is the etcd membership shared by all fake nodes. It answers
+// the etcdctl member, endpoint and alarm commands run inside any running etcd
+// container of its nodes.
+type Cluster struct {
+	mu sync.Mutex
+
+	id      uint64
+	nextID  uint64
+	members []*etcdserverpb.Member
+
+	// PromoteNotInSync is the number of `member promote` calls that are
+	// rejected because the learner is not in sync yet, before a
+	// promotion succeeds.
+	PromoteNotInSync int
+	// Alarms are the active alarms, e.g. NOSPACE, raised by the first
+	// member.
+	Alarms []string
+}
+
+// NewCluster returns an empty cluster.
+func NewCluster() *Cluster {
+	return &Cluster{
+		id:     0xc1a55,
+		nextID: 0x1000,
+	}
+}
+
+// AddNode creates a node attached to the cluster. The node has no etcd
+// container and isn't a member until it is added with AddMember or its
+// manifest is uploaded.
+func (c *Cluster) AddNode(name, ip string) *Node {
+	return newNode(c, name, ip)
+}
+
+// AddMember adds the node as a member of the cluster, as if it had been
+// a member before the quorum was lost. It returns the member ID.
+func (c *Cluster) AddMember(n *Node, isLearner bool) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.newMember(n.IP, isLearner)
+	m.Name = n.Name
+	m.ClientURLs = []string{fmt.Sprintf("https://%s:2379", n.IP)}
+	return m.ID
+}
+
+// AddUnstartedLearner adds a learner with the given peer IP that was
+// added to the cluster but never started, i.e. it has no name yet.
+func (c *Cluster) AddUnstartedLearner(ip string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.newMember(ip, true).ID
+}
+
+// Members returns a copy of the current member list.
+func (c *Cluster) Members() []*etcdserverpb.Member {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	members := make([]*etcdserverpb.Member, 0, len(c.members))
+	for _, m := range c.members {
+		cp := *m
+		members = append(members, &cp)
+	}
+	return members
+}
+
+// Member returns the member whose peer URL points to ip, or nil.
+func (c *Cluster) Member(ip string) *etcdserverpb.Member {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if m := c.memberByIP(ip); m != nil {
+		cp := *m
+		return &cp
+	}
+	return nil
+}
+
+func (c *Cluster) newMember(ip string, isLearner bool) *etcdserverpb.Member {
+	c.nextID++
+	m := &etcdserverpb.Member{
+		ID:        c.nextID,
+		PeerURLs:  []string{fmt.Sprintf("https://%s:2380", ip)},
+		IsLearner: isLearner,
+	}
+	c.members = append(c.members, m)
+	return m
+}
+
+func (c *Cluster) memberByIP(ip string) *etcdserverpb.Member {
+	for _, m := range c.members {
+		for _, peerURL := range m.PeerURLs {
+			if u, err := url.Parse(peerURL); err == nil && u.Hostname() == ip {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) memberByID(id uint64) *etcdserverpb.Member {
+	for _, m := range c.members {
+		if m.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+// forceNewCluster drops every member except the one of node n, the
+// same as etcd does when started with --force-new-cluster.
+func (c *Cluster) forceNewCluster(n *Node) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.memberByIP(n.IP)
+	if m == nil {
+		m = c.newMember(n.IP, false)
+	}
+	m.Name = n.Name
+	m.IsLearner = false
+	m.ClientURLs = []string{fmt.Sprintf("https://%s:2379", n.IP)}
+	c.members = []*etcdserverpb.Member{m}
+}
+
+// join starts the member of node n that was previously added to the
+// cluster. It returns false if the cluster doesn't know the node, in
+// which case etcd would exit.
+func (c *Cluster) join(n *Node) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.memberByIP(n.IP)
+	if m == nil {
+		return false
+	}
+	m.Name = n.Name
+	m.ClientURLs = []string{fmt.Sprintf("https://%s:2379", n.IP)}
+	return true
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package fakenode
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+type endpointStatus struct {
+	Endpoint string                       `json:"Endpoint"`
+	Status   *etcdserverpb.StatusResponse `json:"Status"`
+}
+
+// etcdctl answers the etcdctl command run on node n. The endpoint and
+// TLS flags must already be stripped from args.
+func (c *Cluster) etcdctl(n *Node, args []string) response {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cmd := strings.Join(args, " ")
+	switch {
+	case strings.HasPrefix(cmd, "member list"):
+		return c.memberList(n)
+	case strings.HasPrefix(cmd, "member add ") && len(args) > 2:
+		return c.memberAdd(n, args[2:])
+	case strings.HasPrefix(cmd, "member promote ") && len(args) > 2:
+		return c.memberPromote(args[2])
+	case strings.HasPrefix(cmd, "member remove ") && len(args) > 2:
+		return c.memberRemove(args[2])
+	case strings.HasPrefix(cmd, "member update ") && len(args) > 3:
+		return c.memberUpdate(args[2], args[3:])
+	case cmd == "alarm list":
+		var lines []string
+		for _, a := range c.Alarms {
+			lines = append(lines, fmt.Sprintf("memberID:%x alarm:%s\n", c.members[0].ID, a))
+		}
+		return success([]byte(strings.Join(lines, "")))
+	case strings.HasPrefix(cmd, "endpoint status"):
+		return c.endpointStatus(n, slices.Contains(args, "--cluster"))
+	case strings.HasPrefix(cmd, "endpoint health"):
+		return c.endpointHealth(n, slices.Contains(args, "--cluster"))
+	}
+
+	return failure(1, fmt.Sprintf("Error: %v: %s\n", errNotSupported, cmd))
+}
+
+func (c *Cluster) header(n *Node) *etcdserverpb.ResponseHeader {
+	h := &etcdserverpb.ResponseHeader{ClusterId: c.id}
+	if m := c.memberByIP(n.IP); m != nil {
+		h.MemberId = m.ID
+	}
+	return h
+}
+
+func (c *Cluster) memberList(n *Node) response {
+	return jsonResponse(&etcdserverpb.MemberListResponse{
+		Header:  c.header(n),
+		Members: c.members,
+	})
+}
+
+func (c *Cluster) memberAdd(n *Node, args []string) response {
+	var peerURL string
+	isLearner := false
+	for _, a := range args[1:] {
+		if strings.HasPrefix(a, "--peer-urls=") {
+			peerURL = strings.TrimPrefix(a, "--peer-urls=")
+		}
+		if a == "--learner" {
+			isLearner = true
+		}
+	}
+
+	for _, m := range c.members {
+		if slices.Contains(m.PeerURLs, peerURL) {
+			return failure(1, "Error: etcdserver: Peer URLs already exists\n")
+		}
+	}
+
+	c.nextID++
+	m := &etcdserverpb.Member{
+		ID:        c.nextID,
+		PeerURLs:  []string{peerURL},
+		IsLearner: isLearner,
+	}
+	c.members = append(c.members, m)
+
+	return jsonResponse(&etcdserverpb.MemberAddResponse{
+		Header:  c.header(n),
+		Member:  m,
+		Members: c.members,
+	})
+}
+
+func (c *Cluster) memberPromote(hexID string) response {
+	id, err := strconv.ParseUint(hexID, 16, 64)
+	if err != nil {
+		return failure(1, fmt.Sprintf("Error: bad member ID arg (%v), expecting ID in Hex\n", err))
+	}
+
+	m := c.memberByID(id)
+	if m == nil {
+		return failure(1, "Error: etcdserver: member not found\n")
+	}
+	if !m.IsLearner {
+		return failure(1, "Error: etcdserver: can only promote a learner member\n")
+	}
+	if m.Name == "" || c.PromoteNotInSync > 0 {
+		if c.PromoteNotInSync > 0 {
+			c.PromoteNotInSync--
+		}
+		return failure(1, "Error: etcdserver: can only promote a learner member which is in sync with leader\n")
+	}
+
+	m.IsLearner = false
+	return success([]byte(fmt.Sprintf("Member %x promoted in cluster %x\n", m.ID, c.id)))
+}
+
+func (c *Cluster) memberUpdate(hexID string, args []string) response {
+	id, err := strconv.ParseUint(hexID, 16, 64)
+	if err != nil {
+		return failure(1, fmt.Sprintf("Error: bad member ID arg (%v), expecting ID in Hex\n", err))
+	}
+
+	m := c.memberByID(id)
+	if m == nil {
+		return failure(1, "Error: etcdserver: member not found\n")
+	}
+	for _, a := range args {
+		if strings.HasPrefix(a, "--peer-urls=") {
+			m.PeerURLs = strings.Split(strings.TrimPrefix(a, "--peer-urls="), ",")
+		}
+	}
+	return success([]byte(fmt.Sprintf("Member %x updated in cluster %x\n", id, c.id)))
+}
+
+func (c *Cluster) memberRemove(hexID string) response {
+	id, err := strconv.ParseUint(hexID, 16, 64)
+	if err != nil {
+		return failure(1, fmt.Sprintf("Error: bad member ID arg (%v), expecting ID in Hex\n", err))
+	}
+
+	for i, m := range c.members {
+		if m.ID == id {
+			c.members = slices.Delete(c.members, i, i+1)
+			return success([]byte(fmt.Sprintf("Member %x removed from cluster %x\n", id, c.id)))
+		}
+	}
+	return failure(1, "Error: etcdserver: Member not found\n")
+}
+
+// startedMembers returns the members queried by an endpoint command,
+// either the local member only or every member that has started.
+func (c *Cluster) startedMembers(n *Node, cluster bool) []*etcdserverpb.Member {
+	if !cluster {
+		if m := c.memberByIP(n.IP); m != nil {
+			return []*etcdserverpb.Member{m}
+		}
+		return nil
+	}
+
+	var members []*etcdserverpb.Member
+	for _, m := range c.members {
+		if m.Name != "" {
+			members = append(members, m)
+		}
+	}
+	return members
+}
+
+func (c *Cluster) endpointStatus(n *Node, cluster bool) response {
+	var leader uint64
+	for _, m := range c.members {
+		if !m.IsLearner {
+			leader = m.ID
+			break
+		}
+	}
+
+	var statuses []endpointStatus
+	for _, m := range c.startedMembers(n, cluster) {
+		statuses = append(statuses, endpointStatus{
+			Endpoint: fmt.Sprintf("https://%s:2379", extractIP(m.PeerURLs[0])),
+			Status: &etcdserverpb.StatusResponse{
+				Header:    &etcdserverpb.ResponseHeader{ClusterId: c.id, MemberId: m.ID},
+				Version:   "3.6.7",
+				DbSize:    20480,
+				Leader:    leader,
+				RaftIndex: 100,
+				RaftTerm:  2,
+				IsLearner: m.IsLearner,
+			},
+		})
+	}
+	if len(statuses) == 0 {
+		return failure(1, "Error: no endpoints available\n")
+	}
+	return jsonResponse(statuses)
+}
+
+func (c *Cluster) endpointHealth(n *Node, cluster bool) response {
+	var lines []string
+	for _, m := range c.startedMembers(n, cluster) {
+		lines = append(lines, fmt.Sprintf("https://%s:2379 is healthy: successfully committed proposal: took = 1.5ms", extractIP(m.PeerURLs[0])))
+	}
+	if len(lines) == 0 {
+		return failure(1, "Error: unhealthy cluster\n")
+	}
+	return success([]byte(strings.Join(lines, "\n") + "\n"))
+}
+
+func jsonResponse(v any) response {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return failure(1, fmt.Sprintf("Error: %v\n", err))
+	}
+	return success(data)
+}
+
+func extractIP(peerURL string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(peerURL, "https://"), "http://")
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		return host[:i]
+	}
+	return host
+}
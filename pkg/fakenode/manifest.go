@@ -0,0 +1,36 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package fakenode
+
+import "fmt"
+
+// Manifest returns a minimal etcd static pod manifest for the node, in
+// the shape kubeadm generates it. Extra flags are appended to the etcd
+// command.
+func (n *Node) Manifest(extraFlags ...string) []byte {
+	manifest := fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: etcd
+  namespace: kube-system
+spec:
+  containers:
+  - name: etcd
+    image: registry.k8s.io/etcd:3.6.7-0
+    command:
+    - etcd
+    - --name=%[1]s
+    - --data-dir=/var/lib/etcd
+    - --advertise-client-urls=https://%[2]s:2379
+    - --initial-advertise-peer-urls=https://%[2]s:2380
+    - --listen-peer-urls=https://%[2]s:2380
+    - --initial-cluster=%[1]s=https://%[2]s:2380
+`, n.Name, n.IP)
+
+	for _, f := range extraFlags {
+		manifest += fmt.Sprintf("    - %s\n", f)
+	}
+	return []byte(manifest)
+}
@@ -0,0 +1,499 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+// Package fakenode simulates control plane VMs for offline tests of the
+// repair workflows. A Node implements transport.Transport and keeps the
+// files, the etcd data directory and the etcd containers in memory. It
+// reacts to uploads of the etcd static pod manifest the way kubelet
+// would, and answers crictl and etcdctl commands from a Cluster shared
+// by all nodes.
+package fakenode
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// ManifestPath is the etcd static pod manifest watched by kubelet.
+const ManifestPath = "/etc/kubernetes/manifests/etcd.yaml"
+
+// Container is an etcd container known to the container runtime.
+type Container struct {
+	ID      string
+	Running bool
+	// Logs are printed by crictl logs, as is.
+	Logs []string
+}
+
+// Node is a fake control plane VM.
+type Node struct {
+	mu sync.Mutex
+
+	Name string
+	IP   string
+
+	cluster       *Cluster
+	files         map[string][]byte
+	dataDir       bool
+	containers    []*Container
+	nextContainer int
+	commands      []string
+	kubeletLogs   []string
+
+	// FailToStart makes every etcd container started by kubelet exit
+	// immediately, e.g. because the bbolt file is corrupt.
+	FailToStart bool
+	// Unreachable makes every operation fail as if the VM was down.
+	Unreachable bool
+	// StartupLogs are logged by every etcd container started by kubelet,
+	// e.g. why it exited when FailToStart is set.
+	StartupLogs []string
+	// SudoNeedsPassword makes sudo -n fail, as without passwordless sudo.
+	SudoNeedsPassword bool
+	// MissingBinaries aren't found by command -v, run through sudo.
+	MissingBinaries []string
+	// DataDirFree is the free space of the data-dir filesystem reported
+	// by df, 100 GiB if zero.
+	DataDirFree int64
+	// ClockOffset is added to the time reported by date.
+	ClockOffset time.Duration
+}
+
+var _ transport.Transport = (*Node)(nil)
+
+func newNode(c *Cluster, name, ip string) *Node {
+	return &Node{
+		Name:    name,
+		IP:      ip,
+		cluster: c,
+		files:   map[string][]byte{},
+	}
+}
+
+// SetFile stores a file on the node, e.g. the backed up manifest.
+// Writing ManifestPath doesn't trigger kubelet, use StartEtcd for that.
+func (n *Node) SetFile(path string, data []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.files[path] = append([]byte(nil), data...)
+}
+
+// File returns the content of a file on the node.
+func (n *Node) File(path string) ([]byte, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	data, ok := n.files[path]
+	return data, ok
+}
+
+// SetDataDir sets whether /var/lib/etcd/member exists.
+func (n *Node) SetDataDir(exists bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.dataDir = exists
+}
+
+// DataDir returns whether /var/lib/etcd/member exists.
+func (n *Node) DataDir() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.dataDir
+}
+
+// StartEtcd installs the manifest and starts an etcd container without
+// changing the cluster membership, as for a VM that was already running
+// etcd before the test started.
+func (n *Node) StartEtcd(manifest []byte) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.files[ManifestPath] = append([]byte(nil), manifest...)
+	n.dataDir = true
+	return n.startContainer()
+}
+
+// RunningContainer returns the ID of the running etcd container, or an
+// empty string.
+func (n *Node) RunningContainer() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.runningContainer()
+}
+
+// Containers returns a copy of all etcd containers, including exited ones.
+func (n *Node) Containers() []Container {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	containers := make([]Container, 0, len(n.containers))
+	for _, c := range n.containers {
+		cp := *c
+		cp.Logs = append([]string(nil), c.Logs...)
+		containers = append(containers, cp)
+	}
+	return containers
+}
+
+// AppendContainerLogs adds lines to the logs of the container id.
+func (n *Node) AppendContainerLogs(id string, lines ...string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, c := range n.containers {
+		if c.ID == id {
+			c.Logs = append(c.Logs, lines...)
+		}
+	}
+}
+
+// AppendKubeletLogs adds lines to the kubelet journal.
+func (n *Node) AppendKubeletLogs(lines ...string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.kubeletLogs = append(n.kubeletLogs, lines...)
+}
+
+// Commands returns all commands run on the node.
+func (n *Node) Commands() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]string(nil), n.commands...)
+}
+
+// response is the outcome of a simulated command.
+type response struct {
+	stdout []byte
+	stderr []byte
+	code   int
+}
+
+func success(stdout []byte) response {
+	return response{stdout: stdout}
+}
+
+func failure(code int, stderr string) response {
+	return response{stderr: []byte(stderr), code: code}
+}
+
+// Run executes a supported command against the in-memory state and
+// returns its combined output. Unsupported commands exit with status 127.
+func (n *Node) Run(cmd string) ([]byte, error) {
+	resp, err := n.exec(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	out := append(append([]byte(nil), resp.stdout...), resp.stderr...)
+	if resp.code != 0 {
+		return out, &transport.ExitError{Status: resp.code}
+	}
+	return out, nil
+}
+
+// Exec is the same as Run, but returns stdout and stderr separately.
+func (n *Node) Exec(cmd string, opts transport.ExecOptions) (*transport.Result, error) {
+	resp, err := n.exec(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Stdout != nil {
+		_, _ = opts.Stdout.Write(resp.stdout)
+	}
+	if opts.Stderr != nil {
+		_, _ = opts.Stderr.Write(resp.stderr)
+	}
+	return &transport.Result{
+		Stdout:   resp.stdout,
+		Stderr:   resp.stderr,
+		ExitCode: resp.code,
+	}, nil
+}
+
+func (n *Node) exec(cmd string) (response, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.Unreachable {
+		return response{}, fmt.Errorf("dial tcp %s:22: connect: no route to host", n.IP)
+	}
+
+	n.commands = append(n.commands, cmd)
+	fields := strings.Fields(cmd)
+
+	switch {
+	case strings.HasPrefix(cmd, "sudo crictl ps"):
+		return success(n.crictlPs(cmd)), nil
+	case strings.HasPrefix(cmd, "sudo crictl exec ") && len(fields) > 4 && fields[4] == "etcdctl":
+		if fields[3] != n.runningContainer() {
+			return failure(1, fmt.Sprintf("container %q is not running\n", fields[3])), nil
+		}
+		return n.cluster.etcdctl(n, etcdctlArgs(fields[5:])), nil
+	case strings.HasPrefix(cmd, "sudo crictl logs "):
+		id := strings.TrimSuffix(cmd, " 2>&1")
+		id = id[strings.LastIndex(id, " ")+1:]
+		for _, c := range n.containers {
+			if c.ID == id {
+				logs := c.Logs
+				for _, f := range fields {
+					var tail int
+					if _, err := fmt.Sscanf(f, "--tail=%d", &tail); err == nil && tail < len(logs) {
+						logs = logs[len(logs)-tail:]
+					}
+				}
+				return success(joinLines(logs)), nil
+			}
+		}
+		return failure(1, fmt.Sprintf("container %q not found\n", id)), nil
+	case strings.HasPrefix(cmd, "sudo journalctl -u kubelet "):
+		return success(joinLines(n.kubeletLogs)), nil
+	case strings.HasPrefix(cmd, "sudo test -d "):
+		if strings.TrimSuffix(fields[3], "/") == "/var/lib/etcd/member" && n.dataDir {
+			return success(nil), nil
+		}
+		return failure(1, ""), nil
+	case strings.HasPrefix(cmd, "sudo -i rm -rf "):
+		if strings.HasPrefix("/var/lib/etcd/member", strings.TrimSuffix(fields[4], "/")) {
+			n.dataDir = false
+		}
+		return success(nil), nil
+	case strings.HasPrefix(cmd, "sudo rm -f ") && len(fields) == 4:
+		// kubelet stops the static pod of a removed manifest
+		delete(n.files, fields[3])
+		if fields[3] == ManifestPath {
+			n.stopContainer(n.runningContainer())
+		}
+		return success(nil), nil
+	case cmd == "sudo -n true":
+		if n.SudoNeedsPassword {
+			return failure(1, "sudo: a password is required\n"), nil
+		}
+		return success(nil), nil
+	case strings.HasPrefix(cmd, "sudo -n sh -c 'command -v ") && len(fields) == 7:
+		if n.SudoNeedsPassword {
+			return failure(1, "sudo: a password is required\n"), nil
+		}
+		bin := strings.TrimSuffix(fields[6], "'")
+		if slices.Contains(n.MissingBinaries, bin) {
+			return failure(1, ""), nil
+		}
+		return success([]byte("/usr/bin/" + bin + "\n")), nil
+	case strings.HasPrefix(cmd, "df -P -B1 "):
+		free := n.DataDirFree
+		if free == 0 {
+			free = 100 << 30
+		}
+		const size = 200 << 30
+		return success([]byte(fmt.Sprintf("Filesystem 1-blocks Used Available Capacity Mounted on\n/dev/sda1 %d %d %d %d%% /\n",
+			int64(size), size-free, free, (size-free)*100/size))), nil
+	case cmd == "date +%s.%N":
+		now := time.Now().Add(n.ClockOffset)
+		return success([]byte(fmt.Sprintf("%d.%09d\n", now.Unix(), now.Nanosecond()))), nil
+	case cmd == "hostname":
+		return success([]byte(n.Name + "\n")), nil
+	}
+
+	return failure(127, fmt.Sprintf("sh: %s: command not found\n", fields[0])), nil
+}
+
+// Upload stores the local file on the node. Uploading ManifestPath
+// restarts the etcd container the way kubelet does.
+func (n *Node) Upload(localPath string, remotePath string) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.Unreachable {
+		return fmt.Errorf("dial tcp %s:22: connect: no route to host", n.IP)
+	}
+
+	n.files[remotePath] = data
+	if remotePath == ManifestPath {
+		return n.syncPod(data)
+	}
+	return nil
+}
+
+// Download writes the node file to localPath.
+func (n *Node) Download(remotePath string, localPath string) error {
+	n.mu.Lock()
+	data, ok := n.files[remotePath]
+	unreachable := n.Unreachable
+	n.mu.Unlock()
+
+	if unreachable {
+		return fmt.Errorf("dial tcp %s:22: connect: no route to host", n.IP)
+	}
+	if !ok {
+		return &fs.PathError{Op: "open", Path: remotePath, Err: fs.ErrNotExist}
+	}
+	return os.WriteFile(localPath, data, 0o644)
+}
+
+// Stat returns the file info of a node file.
+func (n *Node) Stat(remotePath string) (os.FileInfo, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	data, ok := n.files[remotePath]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: remotePath, Err: fs.ErrNotExist}
+	}
+	return fileInfo{name: remotePath, size: int64(len(data))}, nil
+}
+
+// Close is a no-op.
+func (n *Node) Close() error {
+	return nil
+}
+
+func (n *Node) crictlPs(cmd string) []byte {
+	all := strings.Contains(cmd, " -a")
+	var ids []string
+	for i := len(n.containers) - 1; i >= 0; i-- {
+		if all || n.containers[i].Running {
+			ids = append(ids, n.containers[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if strings.Contains(cmd, "head -n 1") {
+		ids = ids[:1]
+	}
+	return []byte(strings.Join(ids, "\n") + "\n")
+}
+
+func joinLines(lines []string) []byte {
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func (n *Node) runningContainer() string {
+	for _, c := range n.containers {
+		if c.Running {
+			return c.ID
+		}
+	}
+	return ""
+}
+
+func (n *Node) startContainer() string {
+	for _, c := range n.containers {
+		c.Running = false
+	}
+	n.nextContainer++
+	c := &Container{
+		ID:      fmt.Sprintf("%s-etcd-%d", n.Name, n.nextContainer),
+		Running: !n.FailToStart,
+		Logs:    append([]string(nil), n.StartupLogs...),
+	}
+	n.containers = append(n.containers, c)
+	return c.ID
+}
+
+// syncPod mimics kubelet reacting to a new static pod manifest: the old
+// container is stopped and a new one is started with the new flags.
+func (n *Node) syncPod(manifest []byte) error {
+	var pod corev1.Pod
+	if err := yaml.Unmarshal(manifest, &pod); err != nil {
+		// kubelet ignores manifests it can't parse
+		return nil
+	}
+
+	var args []string
+	for _, c := range pod.Spec.Containers {
+		if c.Name == "etcd" {
+			args = c.Command
+		}
+	}
+
+	id := n.startContainer()
+	if n.FailToStart {
+		return nil
+	}
+
+	switch {
+	case hasArg(args, "--force-new-cluster") || hasArg(args, "--force-new-cluster=true"):
+		n.cluster.forceNewCluster(n)
+	case hasArg(args, "--initial-cluster-state=existing"):
+		if !n.cluster.join(n) {
+			n.stopContainer(id)
+			return nil
+		}
+	}
+	n.dataDir = true
+	return nil
+}
+
+func (n *Node) stopContainer(id string) {
+	for _, c := range n.containers {
+		if c.ID == id {
+			c.Running = false
+		}
+	}
+}
+
+func hasArg(args []string, arg string) bool {
+	for _, a := range args {
+		if strings.TrimSpace(a) == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// etcdctlArgs strips the endpoint, TLS and timeout flags from an etcdctl
+// command.
+func etcdctlArgs(fields []string) []string {
+	var args []string
+	for i := 0; i < len(fields); i++ {
+		switch {
+		case strings.HasPrefix(fields[i], "--endpoints"), strings.HasPrefix(fields[i], "--command-timeout"):
+		case fields[i] == "--cert" || fields[i] == "--key" || fields[i] == "--cacert":
+			i++
+		default:
+			args = append(args, fields[i])
+		}
+	}
+	return args
+}
+
+type fileInfo struct {
+	name string
+	size int64
+}
+
+func (f fileInfo) Name() string       { return f.name }
+func (f fileInfo) Size() int64        { return f.size }
+func (f fileInfo) Mode() os.FileMode  { return 0o644 }
+func (f fileInfo) ModTime() time.Time { return time.Time{} }
+func (f fileInfo) IsDir() bool        { return false }
+func (f fileInfo) Sys() any           { return nil }
+
+var errNotSupported = errors.New("etcdctl command not supported by fakenode")
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+// Package logging configures the slog logger of the tool: its level, its
+// format, where it writes to, and the secrets it masks.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/vmware/etcd-recovery/pkg/redact"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configures the logger.
+type Options struct {
+	// Level is the minimum level of the records written.
+	Level slog.Level
+	// Format is either FormatText or FormatJSON.
+	Format string
+	// Output defaults to the output set with SetOutput, standard error
+	// unless changed.
+	Output io.Writer
+	// Redactor masks the secrets in the messages and the attributes. A
+	// nil Redactor only masks the values that look like secrets.
+	Redactor *redact.Redactor
+}
+
+// ParseLevel parses one of debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q, valid levels are: [debug info warn error]", s)
+	}
+	return l, nil
+}
+
+// New returns a logger configured with opts.
+func New(opts Options) (*slog.Logger, error) {
+	out := opts.Output
+	if out == nil {
+		out = output
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	var h slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case FormatText, "":
+		h = slog.NewTextHandler(out, handlerOpts)
+	case FormatJSON:
+		h = slog.NewJSONHandler(out, handlerOpts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, valid formats are: [%s %s]", opts.Format, FormatText, FormatJSON)
+	}
+	return slog.New(&redactHandler{Handler: h, redactor: opts.Redactor}), nil
+}
+
+// Setup makes the logger configured with opts the default one. The
+// standard logger writes to it as well.
+func Setup(opts Options) error {
+	l, err := New(opts)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(l)
+	return nil
+}
+
+// Host returns the attributes identifying a host in the records.
+func Host(name, address string) slog.Attr {
+	return slog.Group("host", slog.String("name", name), slog.String("address", address))
+}
+
+var output = &switchWriter{w: os.Stderr}
+
+// Output returns the writer the logs are currently written to.
+func Output() io.Writer {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+	return output.w
+}
+
+// SetOutput changes the writer of the loggers created without an
+// Output, e.g. to show the logs in a dashboard.
+func SetOutput(w io.Writer) {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+	output.w = w
+}
+
+// switchWriter writes to a writer which can be changed while logging.
+type switchWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *switchWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/etcd-recovery/pkg/redact"
+)
+
+func TestNewRedactsSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(Options{
+		Level:    slog.LevelDebug,
+		Format:   FormatJSON,
+		Output:   &buf,
+		Redactor: redact.New("changeme"),
+	})
+	require.NoError(t, err)
+
+	l.With("user", "root:changeme").Debug("Running command",
+		Host("etcd-vm1", "10.0.0.1"),
+		"command", "echo changeme | sudo -S true",
+		"err", errors.New("auth failed with --password=changeme"),
+		"output", []byte("token: abc"),
+		"attempt", 2,
+	)
+
+	var rec map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+	assert.Equal(t, "DEBUG", rec["level"])
+	assert.Equal(t, "root:******", rec["user"])
+	assert.Equal(t, map[string]any{"name": "etcd-vm1", "address": "10.0.0.1"}, rec["host"])
+	assert.Equal(t, "echo ****** | sudo -S true", rec["command"])
+	assert.Equal(t, "auth failed with --password=******", rec["err"])
+	assert.Equal(t, "token: ******", rec["output"])
+	assert.EqualValues(t, 2, rec["attempt"])
+	assert.NotContains(t, buf.String(), "changeme")
+}
+
+func TestNewLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(Options{Level: slog.LevelInfo, Output: &buf})
+	require.NoError(t, err)
+
+	l.Debug("hidden")
+	l.Info("shown", "password", "hunter2")
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), `level=INFO msg=shown password=******`)
+}
+
+func TestParseLevel(t *testing.T) {
+	l, err := ParseLevel("warn")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, l)
+
+	_, err = ParseLevel("verbose")
+	require.EqualError(t, err, `invalid log level "verbose", valid levels are: [debug info warn error]`)
+
+	_, err = New(Options{Format: "yaml"})
+	require.EqualError(t, err, `invalid log format "yaml", valid formats are: [text json]`)
+}
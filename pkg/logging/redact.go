@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/vmware/etcd-recovery/pkg/redact"
+)
+
+// redactHandler masks the secrets in the message and the attributes of
+// the records before passing them to the wrapped handler.
+type redactHandler struct {
+	slog.Handler
+	redactor *redact.Redactor
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, h.redactor.String(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redacted = append(redacted, h.attr(a))
+	}
+	return &redactHandler{Handler: h.Handler.WithAttrs(redacted), redactor: h.redactor}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{Handler: h.Handler.WithGroup(name), redactor: h.redactor}
+}
+
+// attr masks the value of a if it is named like a secret, and the secrets
+// in it otherwise. Values other than strings, groups, errors and byte
+// slices, e.g. numbers, are left as they are.
+func (h *redactHandler) attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup && redact.IsSecretKey(a.Key) {
+		return slog.String(a.Key, redact.Mask)
+	}
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.redactor.String(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, 0, len(group))
+		for _, ga := range group {
+			redacted = append(redacted, h.attr(ga))
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return slog.String(a.Key, h.redactor.String(x.Error()))
+		case []byte:
+			return slog.String(a.Key, h.redactor.Bytes(x))
+		case fmt.Stringer:
+			return slog.String(a.Key, h.redactor.String(x.String()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -5,29 +5,401 @@
 package plan
 
 import (
-	"github.com/vmware/etcd-recovery/pkg/ssh"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/transport"
 )
 
+// ErrCancelled is returned when steps were skipped because the plan was
+// cancelled.
+var ErrCancelled = errors.New("plan cancelled")
+
+// Execute runs the plan and returns the errors of the failed steps.
 func (p *ExecutionPlan) Execute() error {
-	for _, session := range p.Sessions {
-		client, err := ssh.NewClient(&ssh.Config{
-			User:                 session.Host.Username,
-			Host:                 session.Host.Host,
-			Password:             session.Host.Password,
-			PrivateKeyPath:       session.Host.PrivateKey,
-			PrivateKeyPassphrase: session.Host.Passphrase,
-		})
-		if err != nil {
-			return err
+	_, err := p.Run()
+	return err
+}
+
+// Run runs the plan and returns the summary of all steps. The error
+// joins the errors of the failed steps, or reports an invalid plan in
+// which case no step is run and the summary is nil.
+func (p *ExecutionPlan) Run() (*Summary, error) {
+	steps := p.allSteps()
+	if err := validate(steps); err != nil {
+		return nil, fmt.Errorf("invalid plan %q: %w", p.Name, err)
+	}
+
+	names := make([]string, 0, len(steps))
+	for _, s := range steps {
+		names = append(names, s.Name)
+	}
+	p.emit("", &event.PlanStarted{Steps: names})
+
+	start := time.Now()
+	results := p.schedule(steps)
+	summary := &Summary{
+		Plan:     p.Name,
+		Duration: time.Since(start),
+		Steps:    results,
+	}
+	err := summary.Err()
+	if summary.cancelled() {
+		err = errors.Join(ErrCancelled, err)
+	}
+	p.emit("", &event.PlanFinished{Duration: summary.Duration, Err: err})
+	return summary, err
+}
+
+// emit delivers ev to the observers, one event at a time.
+func (p *ExecutionPlan) emit(step string, ev event.Event) {
+	if len(p.Observers) == 0 {
+		return
+	}
+
+	m := ev.Metadata()
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+	m.Plan = p.Name
+	if m.Step == "" {
+		m.Step = step
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, o := range p.Observers {
+		o.OnEvent(ev)
+	}
+}
+
+// allSteps returns the sessions chained one after another, followed by
+// the steps. The first step without dependencies waits for the last
+// session.
+func (p *ExecutionPlan) allSteps() []*Step {
+	var steps []*Step
+	prev := ""
+	for i, s := range p.Sessions {
+		step := &Step{
+			Name:  fmt.Sprintf("%s#%d", p.Name, i+1),
+			Host:  s.Host,
+			Tasks: s.Tasks,
+		}
+		if prev != "" {
+			step.DependsOn = []string{prev}
 		}
-		defer client.Close()
+		steps = append(steps, step)
+		prev = step.Name
+	}
+
+	for _, s := range p.Steps {
+		if prev != "" && len(s.DependsOn) == 0 {
+			cp := *s
+			cp.DependsOn = []string{prev}
+			s = &cp
+		}
+		steps = append(steps, s)
+	}
+	return steps
+}
 
-		for _, task := range session.Tasks {
-			// Run task
-			if _, err := task.Run(client); err != nil {
+func validate(steps []*Step) error {
+	byName := map[string]*Step{}
+	for _, s := range steps {
+		if s.Name == "" {
+			return errors.New("step without a name")
+		}
+		if s.Host == nil {
+			return fmt.Errorf("step %q has no host", s.Name)
+		}
+		if _, ok := byName[s.Name]; ok {
+			return fmt.Errorf("duplicate step %q", s.Name)
+		}
+		byName[s.Name] = s
+	}
+
+	for _, s := range steps {
+		for _, dep := range s.dependencies() {
+			if _, ok := byName[dep]; !ok {
+				return fmt.Errorf("step %q depends on unknown step %q", s.Name, dep)
+			}
+		}
+	}
+
+	// Depth-first search for cycles: 1 is in progress, 2 is done.
+	state := map[string]int{}
+	var visit func(s *Step) error
+	visit = func(s *Step) error {
+		switch state[s.Name] {
+		case 1:
+			return fmt.Errorf("dependency cycle through step %q", s.Name)
+		case 2:
+			return nil
+		}
+		state[s.Name] = 1
+		for _, dep := range s.dependencies() {
+			if err := visit(byName[dep]); err != nil {
 				return err
 			}
 		}
+		state[s.Name] = 2
+		return nil
+	}
+	for _, s := range steps {
+		if err := visit(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type stepDone struct {
+	index  int
+	result *StepResult
+}
+
+// schedule runs the steps as soon as their dependencies have succeeded
+// and there is a free slot on their host.
+func (p *ExecutionPlan) schedule(steps []*Step) []*StepResult {
+	perHost := p.MaxParallelPerHost
+	if perHost <= 0 {
+		perHost = 1
+	}
+
+	results := make([]*StepResult, len(steps))
+	index := map[string]int{}
+	for i, s := range steps {
+		index[s.Name] = i
+		results[i] = &StepResult{Name: s.Name, Host: s.Host.Host, Status: StepPending}
+	}
+
+	skip := func(i int, err error) {
+		results[i].Status = StepSkipped
+		results[i].Err = err
+		p.emit(steps[i].Name, &event.StepFinished{
+			Meta:   event.Meta{Host: steps[i].target().Host},
+			Status: string(StepSkipped),
+			Err:    err,
+		})
+	}
+
+	var (
+		done    = make(chan stepDone)
+		running = map[string]int{}
+		started = make([]bool, len(steps))
+		active  = 0
+		failed  = false
+	)
+
+	for {
+		// A step can be skipped because of a step declared after it, so
+		// scan again until nothing changes.
+		for changed := true; changed; {
+			changed = false
+			for i, s := range steps {
+				if started[i] {
+					continue
+				}
+				if p.cancelled() {
+					started[i] = true
+					skip(i, fmt.Errorf("skipped: %w", ErrCancelled))
+					continue
+				}
+				if failed && !p.ContinueOnError {
+					started[i] = true
+					skip(i, errors.New("skipped after an earlier step failed"))
+					continue
+				}
+
+				ready := true
+				for _, dep := range s.DependsOn {
+					switch results[index[dep]].Status {
+					case StepFailed, StepSkipped:
+						if !started[i] {
+							started[i] = true
+							skip(i, fmt.Errorf("skipped because step %q didn't succeed", dep))
+							changed = true
+						}
+					case StepPending:
+						ready = false
+					}
+				}
+				for _, dep := range s.After {
+					if results[index[dep]].Status == StepPending {
+						ready = false
+					}
+				}
+				if started[i] || !ready {
+					continue
+				}
+				if running[s.Host.Host] >= perHost || (p.MaxParallel > 0 && active >= p.MaxParallel) {
+					continue
+				}
+
+				started[i] = true
+				running[s.Host.Host]++
+				active++
+				go func(i int, s *Step) {
+					done <- stepDone{index: i, result: p.runStep(s)}
+				}(i, s)
+			}
+		}
+
+		if active == 0 {
+			break
+		}
+
+		d := <-done
+		results[d.index] = d.result
+		running[steps[d.index].Host.Host]--
+		active--
+		if d.result.Status == StepFailed {
+			failed = true
+		}
+	}
+
+	return results
+}
+
+// cancelled reports whether Cancel is closed.
+func (p *ExecutionPlan) cancelled() bool {
+	select {
+	case <-p.Cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *ExecutionPlan) runStep(s *Step) *StepResult {
+	res := &StepResult{Name: s.Name, Host: s.Host.Host, Start: time.Now()}
+	p.emit(s.Name, &event.StepStarted{Meta: event.Meta{Host: s.target().Host}})
+
+	res.Err = p.runTasks(s)
+	res.Duration = time.Since(res.Start)
+	if res.Err != nil {
+		res.Status = StepFailed
+	} else {
+		res.Status = StepSucceeded
+	}
+
+	p.emit(s.Name, &event.StepFinished{
+		Meta:     event.Meta{Host: s.target().Host},
+		Status:   string(res.Status),
+		Duration: res.Duration,
+		Err:      res.Err,
+	})
+	return res
+}
+
+// dependencies returns the steps this step waits for.
+func (s *Step) dependencies() []string {
+	return append(append([]string{}, s.DependsOn...), s.After...)
+}
+
+func (s *Step) target() *config.Host {
+	if s.Target != nil {
+		return s.Target
+	}
+	return s.Host
+}
+
+func (p *ExecutionPlan) runTasks(s *Step) error {
+	connect := p.Connect
+	if connect == nil {
+		connect = (*config.Host).Connect
+	}
+
+	conn, err := connect(s.Host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := transport.Transport(conn)
+	if len(p.Observers) > 0 {
+		client = event.NewTransport(conn, s.Host.Host, func(ev event.Event) {
+			p.emit(s.Name, ev)
+		})
+	}
+
+	for _, task := range s.Tasks {
+		if _, err := task.Run(client); err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+// Err joins the errors of the failed steps. Skipped steps aren't
+// reported since their cause is already part of the error.
+func (s *Summary) Err() error {
+	var errs []error
+	for _, r := range s.Steps {
+		if r.Status == StepFailed {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cancelled reports whether steps were skipped because the plan was
+// cancelled.
+func (s *Summary) cancelled() bool {
+	for _, r := range s.Steps {
+		if r.Status == StepSkipped && errors.Is(r.Err, ErrCancelled) {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the summary as a table. The errors spanning several
+// lines, e.g. joined errors or errors carrying container logs, only have
+// their first line in the table, and are listed in full under it.
+func (s *Summary) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Plan %s finished in %v\n", s.Plan, s.Duration.Round(time.Millisecond))
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STEP\tHOST\tSTATUS\tDURATION\tERROR")
+	var multiline []*StepResult
+	for _, r := range s.Steps {
+		errMsg := FirstLine(r.Err)
+		if r.Err != nil && errMsg != r.Err.Error() {
+			multiline = append(multiline, r)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\n", r.Name, r.Host, r.Status, r.Duration.Round(time.Millisecond), errMsg)
+	}
+	_ = w.Flush()
+
+	for _, r := range multiline {
+		fmt.Fprintf(&sb, "\nError of step %s:\n", r.Name)
+		for _, line := range strings.Split(strings.TrimRight(r.Err.Error(), "\n"), "\n") {
+			fmt.Fprintf(&sb, "  %s\n", line)
+		}
+	}
+	return sb.String()
+}
+
+// FirstLine returns the first line of the message of err, followed by
+// " (...)" if it spans several lines, or an empty string if err is nil.
+func FirstLine(err error) string {
+	if err == nil {
+		return ""
+	}
+	msg := err.Error()
+	first, rest, ok := strings.Cut(msg, "\n")
+	if !ok {
+		return msg
+	}
+	if strings.TrimSpace(rest) == "" {
+		return first
+	}
+	return first + " (...)"
+}
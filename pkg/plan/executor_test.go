@@ -6,13 +6,19 @@ package plan
 
 import (
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/vmware/etcd-recovery/pkg/config"
-	"github.com/vmware/etcd-recovery/pkg/ssh"
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/fakenode"
 	"github.com/vmware/etcd-recovery/pkg/task"
+	"github.com/vmware/etcd-recovery/pkg/transport"
 )
 
 // MockTask implements the Task interface for testing
@@ -21,7 +27,7 @@ type mockTask struct {
 }
 
 func (m *mockTask) Name() string { return "MockTask" }
-func (m *mockTask) Run(client *ssh.Client) (string, error) {
+func (m *mockTask) Run(client transport.Transport) (string, error) {
 	if m.shouldFail {
 		return "", errors.New("mock task failure")
 	}
@@ -58,3 +64,375 @@ func TestExecute_TaskFailure(t *testing.T) {
 	err := plan.Execute()
 	require.Error(t, err)
 }
+
+// recordingTask records the order in which tasks run and the highest
+// number of tasks running at the same time.
+type recordingTask struct {
+	name       string
+	shouldFail bool
+	sleep      time.Duration
+	rec        *recorder
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	order   []string
+	running int
+	peak    int
+}
+
+func (r *recordingTask) Name() string { return r.name }
+func (r *recordingTask) Run(client transport.Transport) (string, error) {
+	r.rec.mu.Lock()
+	r.rec.order = append(r.rec.order, r.name)
+	r.rec.running++
+	r.rec.peak = max(r.rec.peak, r.rec.running)
+	r.rec.mu.Unlock()
+
+	time.Sleep(r.sleep)
+
+	r.rec.mu.Lock()
+	r.rec.running--
+	r.rec.mu.Unlock()
+
+	if r.shouldFail {
+		return "", fmt.Errorf("%s failed", r.name)
+	}
+	return "", nil
+}
+
+func connectLocal(*config.Host) (transport.Transport, error) {
+	return transport.NewLocal(), nil
+}
+
+func newStep(rec *recorder, name string, host *config.Host, fail bool, deps ...string) *Step {
+	return &Step{
+		Name:      name,
+		Host:      host,
+		Tasks:     []task.Task{&recordingTask{name: name, shouldFail: fail, sleep: 20 * time.Millisecond, rec: rec}},
+		DependsOn: deps,
+	}
+}
+
+func statuses(s *Summary) map[string]StepStatus {
+	m := map[string]StepStatus{}
+	for _, r := range s.Steps {
+		m[r.Name] = r.Status
+	}
+	return m
+}
+
+func TestRunStepsInParallelAcrossHosts(t *testing.T) {
+	vm1 := &config.Host{Name: "vm1", Host: "10.0.0.1"}
+	vm2 := &config.Host{Name: "vm2", Host: "10.0.0.2"}
+	rec := &recorder{}
+
+	p := &ExecutionPlan{
+		Name: "backup-then-create",
+		Steps: []*Step{
+			newStep(rec, "backup-vm1", vm1, false),
+			newStep(rec, "backup-vm2", vm2, false),
+			newStep(rec, "create-seed", vm1, false, "backup-vm1", "backup-vm2"),
+			newStep(rec, "add-vm2", vm1, false, "create-seed"),
+		},
+		Connect: connectLocal,
+	}
+
+	summary, err := p.Run()
+	require.NoError(t, err)
+	assert.Equal(t, 2, rec.peak)
+	assert.ElementsMatch(t, []string{"backup-vm1", "backup-vm2"}, rec.order[:2])
+	assert.Equal(t, []string{"create-seed", "add-vm2"}, rec.order[2:])
+	for _, r := range summary.Steps {
+		assert.Equal(t, StepSucceeded, r.Status, r.Name)
+		assert.Positive(t, r.Duration)
+	}
+	assert.Contains(t, summary.String(), "create-seed  10.0.0.1  succeeded")
+}
+
+func TestSummaryStringMultilineErrors(t *testing.T) {
+	summary := &Summary{Plan: "repair", Steps: []*StepResult{
+		{Name: "add-vm2", Host: "10.0.0.1", Status: StepFailed, Err: errors.Join(errors.New("learner did not start"), errors.New("failed to remove learner"))},
+		{Name: "add-vm3", Host: "10.0.0.1", Status: StepFailed, Err: errors.New("learner never synced")},
+	}}
+
+	s := summary.String()
+	assert.Contains(t, s, "add-vm2  10.0.0.1  failed  0s        learner did not start (...)\n")
+	assert.Contains(t, s, "add-vm3  10.0.0.1  failed  0s        learner never synced\n")
+	assert.Contains(t, s, "\nError of step add-vm2:\n  learner did not start\n  failed to remove learner\n")
+	assert.NotContains(t, s, "Error of step add-vm3")
+
+	assert.Empty(t, FirstLine(nil))
+	assert.Equal(t, "one", FirstLine(errors.New("one\n")))
+}
+
+func TestRunLimitsStepsPerHost(t *testing.T) {
+	vm1 := &config.Host{Name: "vm1", Host: "10.0.0.1"}
+	rec := &recorder{}
+
+	p := &ExecutionPlan{
+		Name: "limits",
+		Steps: []*Step{
+			newStep(rec, "a", vm1, false),
+			newStep(rec, "b", vm1, false),
+			newStep(rec, "c", vm1, false),
+		},
+		Connect: connectLocal,
+	}
+	_, err := p.Run()
+	require.NoError(t, err)
+	assert.Equal(t, 1, rec.peak)
+
+	rec = &recorder{}
+	for _, s := range p.Steps {
+		s.Tasks[0].(*recordingTask).rec = rec
+	}
+	p.MaxParallelPerHost = 3
+	_, err = p.Run()
+	require.NoError(t, err)
+	assert.Equal(t, 3, rec.peak)
+}
+
+func TestRunFailFast(t *testing.T) {
+	vm1 := &config.Host{Name: "vm1", Host: "10.0.0.1"}
+	vm2 := &config.Host{Name: "vm2", Host: "10.0.0.2"}
+	rec := &recorder{}
+
+	p := &ExecutionPlan{
+		Name: "fail-fast",
+		Steps: []*Step{
+			newStep(rec, "create-seed", vm1, true),
+			newStep(rec, "add-vm2", vm1, false, "create-seed"),
+			newStep(rec, "collect-vm2", vm2, false),
+		},
+		MaxParallel: 1,
+		Connect:     connectLocal,
+	}
+
+	summary, err := p.Run()
+	require.EqualError(t, err, "create-seed failed")
+	assert.Equal(t, []string{"create-seed"}, rec.order)
+	assert.Equal(t, map[string]StepStatus{
+		"create-seed": StepFailed,
+		"add-vm2":     StepSkipped,
+		"collect-vm2": StepSkipped,
+	}, statuses(summary))
+}
+
+func TestRunContinueOnError(t *testing.T) {
+	vm1 := &config.Host{Name: "vm1", Host: "10.0.0.1"}
+	vm2 := &config.Host{Name: "vm2", Host: "10.0.0.2"}
+	vm3 := &config.Host{Name: "vm3", Host: "10.0.0.3"}
+	rec := &recorder{}
+
+	p := &ExecutionPlan{
+		Name: "continue",
+		Steps: []*Step{
+			// Declared before the step it depends on on purpose.
+			newStep(rec, "promote-vm2", vm1, false, "add-vm2"),
+			newStep(rec, "add-vm2", vm1, true),
+			newStep(rec, "add-vm3", vm3, true),
+			newStep(rec, "collect-vm2", vm2, false),
+		},
+		ContinueOnError: true,
+		Connect:         connectLocal,
+	}
+
+	summary, err := p.Run()
+	require.ErrorContains(t, err, "add-vm2 failed")
+	require.ErrorContains(t, err, "add-vm3 failed")
+	assert.Equal(t, map[string]StepStatus{
+		"promote-vm2": StepSkipped,
+		"add-vm2":     StepFailed,
+		"add-vm3":     StepFailed,
+		"collect-vm2": StepSucceeded,
+	}, statuses(summary))
+	assert.ErrorContains(t, summary.Steps[0].Err, `step "add-vm2" didn't succeed`)
+}
+
+// cancelTask cancels the plan while it runs.
+type cancelTask struct {
+	cancel chan struct{}
+}
+
+func (c *cancelTask) Name() string { return "cancel" }
+func (c *cancelTask) Run(client transport.Transport) (string, error) {
+	close(c.cancel)
+	return "", nil
+}
+
+func TestRunCancel(t *testing.T) {
+	vm1 := &config.Host{Name: "vm1", Host: "10.0.0.1"}
+	rec := &recorder{}
+	cancel := make(chan struct{})
+
+	p := &ExecutionPlan{
+		Name: "cancel",
+		Steps: []*Step{
+			{Name: "create-seed", Host: vm1, Tasks: []task.Task{&cancelTask{cancel: cancel}, &recordingTask{name: "create-seed", rec: rec}}},
+			newStep(rec, "add-vm2", vm1, false, "create-seed"),
+		},
+		Connect: connectLocal,
+		Cancel:  cancel,
+	}
+
+	summary, err := p.Run()
+	require.ErrorIs(t, err, ErrCancelled)
+	// The running step finishes, the next one isn't started.
+	assert.Equal(t, []string{"create-seed"}, rec.order)
+	assert.Equal(t, map[string]StepStatus{
+		"create-seed": StepSucceeded,
+		"add-vm2":     StepSkipped,
+	}, statuses(summary))
+}
+
+func TestRunAfterFailedStep(t *testing.T) {
+	vm1 := &config.Host{Name: "vm1", Host: "10.0.0.1"}
+	rec := &recorder{}
+
+	addVM3 := newStep(rec, "add-vm3", vm1, false, "create-seed")
+	addVM3.After = []string{"add-vm2"}
+	addVM4 := newStep(rec, "add-vm4", vm1, false, "create-seed")
+	addVM4.After = []string{"add-vm3"}
+
+	p := &ExecutionPlan{
+		Name: "after",
+		Steps: []*Step{
+			addVM4,
+			addVM3,
+			newStep(rec, "add-vm2", vm1, true, "create-seed"),
+			newStep(rec, "create-seed", vm1, false),
+		},
+		MaxParallelPerHost: 3,
+		ContinueOnError:    true,
+		Connect:            connectLocal,
+	}
+
+	summary, err := p.Run()
+	require.EqualError(t, err, "add-vm2 failed")
+	assert.Equal(t, []string{"create-seed", "add-vm2", "add-vm3", "add-vm4"}, rec.order)
+	assert.Equal(t, map[string]StepStatus{
+		"create-seed": StepSucceeded,
+		"add-vm2":     StepFailed,
+		"add-vm3":     StepSucceeded,
+		"add-vm4":     StepSucceeded,
+	}, statuses(summary))
+}
+
+func TestRunSessionsBeforeSteps(t *testing.T) {
+	vm1 := &config.Host{Name: "vm1", Host: "10.0.0.1"}
+	vm2 := &config.Host{Name: "vm2", Host: "10.0.0.2"}
+	rec := &recorder{}
+
+	p := &ExecutionPlan{
+		Name: "sessions",
+		Sessions: []*RemoteSession{
+			{Host: vm1, Tasks: []task.Task{&recordingTask{name: "s1", rec: rec}}},
+			{Host: vm2, Tasks: []task.Task{&recordingTask{name: "s2", rec: rec}}},
+		},
+		Steps:   []*Step{newStep(rec, "step", vm1, false)},
+		Connect: connectLocal,
+	}
+
+	_, err := p.Run()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s1", "s2", "step"}, rec.order)
+}
+
+func TestRunInvalidPlan(t *testing.T) {
+	vm1 := &config.Host{Name: "vm1", Host: "10.0.0.1"}
+	rec := &recorder{}
+
+	tests := []struct {
+		name    string
+		steps   []*Step
+		wantErr string
+	}{
+		{
+			name:    "unknown dependency",
+			steps:   []*Step{newStep(rec, "a", vm1, false, "b")},
+			wantErr: `step "a" depends on unknown step "b"`,
+		},
+		{
+			name:    "duplicate step",
+			steps:   []*Step{newStep(rec, "a", vm1, false), newStep(rec, "a", vm1, false)},
+			wantErr: `duplicate step "a"`,
+		},
+		{
+			name:    "cycle",
+			steps:   []*Step{newStep(rec, "a", vm1, false, "b"), newStep(rec, "b", vm1, false, "a")},
+			wantErr: "dependency cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &ExecutionPlan{Name: "invalid", Steps: tt.steps, Connect: connectLocal}
+			_, err := p.Run()
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+	assert.Empty(t, rec.order)
+}
+
+func TestRunEmitsEvents(t *testing.T) {
+	cluster := fakenode.NewCluster()
+	node := cluster.AddNode("etcd-vm1", "10.0.0.1")
+	vm1 := &config.Host{Name: "vm1", Host: node.IP}
+	rec := &recorder{}
+
+	var events []event.Event
+	p := &ExecutionPlan{
+		Name: "events",
+		Steps: []*Step{
+			{
+				Name:  "hostname",
+				Host:  vm1,
+				Tasks: []task.Task{&task.CommandTask{Command: "hostname"}},
+			},
+			newStep(rec, "fails", vm1, true, "hostname"),
+			newStep(rec, "skipped", vm1, false, "fails"),
+		},
+		Connect: func(*config.Host) (transport.Transport, error) {
+			return node, nil
+		},
+		Observers: []event.Observer{event.ObserverFunc(func(ev event.Event) {
+			events = append(events, ev)
+		})},
+	}
+
+	_, err := p.Run()
+	require.Error(t, err)
+
+	var kinds []string
+	for _, ev := range events {
+		m := ev.Metadata()
+		assert.Equal(t, "events", m.Plan)
+		assert.False(t, m.Time.IsZero())
+
+		switch ev := ev.(type) {
+		case *event.PlanStarted:
+			assert.Equal(t, []string{"hostname", "fails", "skipped"}, ev.Steps)
+			kinds = append(kinds, "plan-started")
+		case *event.StepStarted:
+			kinds = append(kinds, "started:"+m.Step)
+		case *event.CommandRun:
+			assert.Equal(t, "hostname", ev.Command)
+			assert.Equal(t, "etcd-vm1\n", string(ev.Stdout))
+			assert.Equal(t, "10.0.0.1", m.Host)
+			kinds = append(kinds, "command:"+m.Step)
+		case *event.StepFinished:
+			kinds = append(kinds, ev.Status+":"+m.Step)
+		case *event.PlanFinished:
+			require.Error(t, ev.Err)
+			kinds = append(kinds, "plan-finished")
+		}
+	}
+	assert.Equal(t, []string{
+		"plan-started",
+		"started:hostname", "command:hostname", "succeeded:hostname",
+		"started:fails", "failed:fails",
+		"skipped:skipped",
+		"plan-finished",
+	}, kinds)
+}
@@ -5,16 +5,96 @@
 package plan
 
 import (
+	"sync"
+	"time"
+
 	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/event"
 	"github.com/vmware/etcd-recovery/pkg/task"
+	"github.com/vmware/etcd-recovery/pkg/transport"
 )
 
+// ExecutionPlan is a graph of steps. A step starts once all the steps it
+// depends on have succeeded; steps without dependencies between them run
+// in parallel, within the per-host and global concurrency limits.
 type ExecutionPlan struct {
-	Name     string
+	Name string
+	// Sessions are run one after another, as if each of them was a step
+	// depending on the previous one. They are run before Steps.
 	Sessions []*RemoteSession
+	Steps    []*Step
+
+	// MaxParallelPerHost is the maximum number of steps running at the
+	// same time on a host. It defaults to 1.
+	MaxParallelPerHost int
+	// MaxParallel is the maximum number of steps running at the same
+	// time across all hosts. Zero means no limit.
+	MaxParallel int
+	// ContinueOnError keeps running the steps that don't depend on a
+	// failed step. By default no new step is started after a failure.
+	ContinueOnError bool
+
+	// Connect opens the transport used by a step. It defaults to
+	// (*config.Host).Connect.
+	Connect func(h *config.Host) (transport.Transport, error)
+
+	// Observers receive the events of the plan and of its tasks.
+	Observers []event.Observer
+
+	// Cancel stops the plan once closed: the running steps finish, but
+	// the steps not started yet are skipped.
+	Cancel <-chan struct{}
+
+	mu sync.Mutex
 }
 
 type RemoteSession struct {
 	Host  *config.Host
 	Tasks []task.Task
 }
+
+// Step runs its tasks in sequence over a single connection to Host.
+type Step struct {
+	Name  string
+	Host  *config.Host
+	Tasks []task.Task
+	// Target is the host changed by the step, when it isn't Host itself,
+	// e.g. the learner of a step adding a member through the seed. The
+	// step events are about Target.
+	Target *config.Host
+	// DependsOn lists the names of the steps that must succeed before
+	// this step starts.
+	DependsOn []string
+	// After lists the names of the steps that must have finished, whether
+	// they succeeded or not, before this step starts.
+	After []string
+}
+
+type StepStatus string
+
+const (
+	StepPending   StepStatus = "pending"
+	StepSucceeded StepStatus = "succeeded"
+	StepFailed    StepStatus = "failed"
+	// StepSkipped is set on steps that didn't run because a dependency
+	// failed or the plan stopped on an earlier failure.
+	StepSkipped StepStatus = "skipped"
+)
+
+// StepResult is the outcome of a step.
+type StepResult struct {
+	Name     string
+	Host     string
+	Status   StepStatus
+	Start    time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// Summary reports the outcome of every step, in the order they are
+// declared in the plan.
+type Summary struct {
+	Plan     string
+	Duration time.Duration
+	Steps    []*StepResult
+}
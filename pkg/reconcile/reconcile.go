@@ -0,0 +1,213 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+// Package reconcile computes the changes converging the membership of a
+// cluster to the hosts config: the members which aren't in it are
+// removed, the stale peer URLs are fixed and the missing hosts are
+// added. The changes are ordered so that the cluster keeps its quorum
+// after every one of them.
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"github.com/vmware/etcd-recovery/pkg/config"
+)
+
+// ErrUnsafe is returned when the membership can't be converged without
+// losing quorum.
+var ErrUnsafe = errors.New("unsafe membership change")
+
+type ActionType string
+
+const (
+	// RemoveLearner removes a learner which isn't in the hosts config. It
+	// doesn't change the quorum.
+	RemoveLearner ActionType = "remove-learner"
+	// RemoveMember removes a voting member which isn't in the hosts
+	// config.
+	RemoveMember ActionType = "remove-member"
+	// UpdatePeerURL points the member of a host to its current address.
+	UpdatePeerURL ActionType = "update-peer-url"
+	// AddMember adds a host as a learner and promotes it, or promotes the
+	// learner already added for it.
+	AddMember ActionType = "add-member"
+)
+
+// Action is a change of the membership.
+type Action struct {
+	Type     ActionType
+	MemberID uint64
+	// Name is the name of the member, or of the host when it has none.
+	Name string
+	// Host is the host of UpdatePeerURL and AddMember.
+	Host       *config.Host
+	PeerURL    string
+	NewPeerURL string
+	Reason     string
+}
+
+func (a *Action) String() string {
+	switch a.Type {
+	case RemoveLearner, RemoveMember:
+		return fmt.Sprintf("%s %s (%x, %s): %s", a.Type, a.Name, a.MemberID, a.PeerURL, a.Reason)
+	case UpdatePeerURL:
+		return fmt.Sprintf("%s %s (%x): %s -> %s: %s", a.Type, a.Name, a.MemberID, a.PeerURL, a.NewPeerURL, a.Reason)
+	default:
+		return fmt.Sprintf("%s %s (%s): %s", a.Type, a.Name, a.Host.Host, a.Reason)
+	}
+}
+
+// PeerURL returns the peer URL of the member of h.
+func PeerURL(h *config.Host) string {
+	return fmt.Sprintf("https://%s:2380", h.Host)
+}
+
+// Plan returns the actions converging members to hosts, in the order they
+// must be applied. healthy holds the IDs of the members answering; the
+// others are dead. The actions are, in order:
+//   - the removal of the learners which aren't in hosts, as etcd accepts
+//     only one learner at a time
+//   - the removal of the dead voters which aren't in hosts, which never
+//     makes the quorum harder to reach
+//   - the peer URL updates of the members of hosts whose address changed
+//   - the addition of the hosts without a member, one after another
+//   - the removal of the healthy voters which aren't in hosts, once the
+//     hosts joined, so that they keep serving until then
+//
+// ErrUnsafe is returned if any removal leaves fewer healthy voters than
+// a quorum.
+func Plan(members []*etcdserverpb.Member, healthy map[uint64]bool, hosts []*config.Host) ([]*Action, error) {
+	var (
+		learners, dead, updates, adds, live []*Action
+		matched                             = map[uint64]bool{}
+	)
+
+	for _, h := range hosts {
+		m := matchByAddress(members, h)
+		if m == nil {
+			m = matchByName(members, h)
+		}
+		if m == nil {
+			adds = append(adds, &Action{Type: AddMember, Name: h.Name, Host: h, Reason: "host without member"})
+			continue
+		}
+
+		matched[m.ID] = true
+		switch {
+		case m.IsLearner:
+			// The learner added by an interrupted run is taken over.
+			adds = append([]*Action{{Type: AddMember, MemberID: m.ID, Name: h.Name, Host: h, Reason: "learner not promoted"}}, adds...)
+		case len(m.PeerURLs) == 0 || address(m.PeerURLs[0]) != h.Host:
+			updates = append(updates, &Action{
+				Type:       UpdatePeerURL,
+				MemberID:   m.ID,
+				Name:       m.Name,
+				Host:       h,
+				PeerURL:    strings.Join(m.PeerURLs, ","),
+				NewPeerURL: PeerURL(h),
+				Reason:     "address changed",
+			})
+		}
+	}
+
+	for _, m := range members {
+		if matched[m.ID] {
+			continue
+		}
+		a := &Action{Type: RemoveMember, MemberID: m.ID, Name: m.Name, PeerURL: strings.Join(m.PeerURLs, ",")}
+		if a.Name == "" {
+			a.Name = "unstarted"
+		}
+		switch {
+		case m.IsLearner:
+			a.Type, a.Reason = RemoveLearner, "learner not in the hosts config"
+			learners = append(learners, a)
+		case !healthy[m.ID]:
+			a.Reason = "dead voter not in the hosts config"
+			dead = append(dead, a)
+		default:
+			a.Reason = "voter not in the hosts config"
+			live = append(live, a)
+		}
+	}
+
+	actions := append(append(append(append(learners, dead...), updates...), adds...), live...)
+	if err := checkQuorum(members, healthy, actions); err != nil {
+		return actions, err
+	}
+	return actions, nil
+}
+
+// checkQuorum applies the actions to the voters of members, and returns
+// ErrUnsafe if a quorum of them isn't healthy after any removal. The hosts
+// added are expected to be healthy once promoted.
+func checkQuorum(members []*etcdserverpb.Member, healthy map[uint64]bool, actions []*Action) error {
+	voters, up := 0, 0
+	for _, m := range members {
+		if !m.IsLearner {
+			voters++
+			if healthy[m.ID] {
+				up++
+			}
+		}
+	}
+
+	for _, a := range actions {
+		switch a.Type {
+		case RemoveMember:
+			voters--
+			if healthy[a.MemberID] {
+				up--
+			}
+			if voters == 0 || up < voters/2+1 {
+				return fmt.Errorf("%w: removing %s would leave %d healthy voters of %d, %d needed", ErrUnsafe, a.Name, up, voters, voters/2+1)
+			}
+		case AddMember:
+			voters++
+			up++
+		}
+	}
+	return nil
+}
+
+func matchByAddress(members []*etcdserverpb.Member, h *config.Host) *etcdserverpb.Member {
+	for _, m := range members {
+		for _, u := range m.PeerURLs {
+			if address(u) == h.Host {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+// matchByName matches the member of h by the member name of the hosts
+// config, if it is set. Otherwise it is the hostname, only known by
+// connecting to the host.
+func matchByName(members []*etcdserverpb.Member, h *config.Host) *etcdserverpb.Member {
+	if h.MemberName == "" {
+		return nil
+	}
+	for _, m := range members {
+		if m.Name == h.MemberName {
+			return m
+		}
+	}
+	return nil
+}
+
+// address returns the host of a peer URL.
+func address(peerURL string) string {
+	u, err := url.Parse(peerURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
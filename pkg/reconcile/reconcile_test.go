@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"github.com/vmware/etcd-recovery/pkg/config"
+)
+
+func member(id uint64, name, ip string, isLearner bool) *etcdserverpb.Member {
+	return &etcdserverpb.Member{ID: id, Name: name, PeerURLs: []string{"https://" + ip + ":2380"}, IsLearner: isLearner}
+}
+
+func hosts() []*config.Host {
+	return []*config.Host{
+		{Name: "etcd-vm1", MemberName: "etcd-vm1", Host: "10.0.0.1"},
+		{Name: "etcd-vm2", MemberName: "etcd-vm2", Host: "10.0.0.2"},
+		{Name: "etcd-vm3", MemberName: "etcd-vm3", Host: "10.0.0.3"},
+	}
+}
+
+func types(actions []*Action) []string {
+	var ts []string
+	for _, a := range actions {
+		ts = append(ts, string(a.Type)+" "+a.Name)
+	}
+	return ts
+}
+
+func TestPlanInSync(t *testing.T) {
+	members := []*etcdserverpb.Member{
+		member(1, "etcd-vm1", "10.0.0.1", false),
+		member(2, "etcd-vm2", "10.0.0.2", false),
+		member(3, "etcd-vm3", "10.0.0.3", false),
+	}
+	actions, err := Plan(members, map[uint64]bool{1: true, 2: true, 3: true}, hosts())
+	require.NoError(t, err)
+	assert.Empty(t, actions)
+}
+
+func TestPlanOrder(t *testing.T) {
+	members := []*etcdserverpb.Member{
+		member(1, "etcd-vm1", "10.0.0.1", false),
+		member(2, "etcd-vm2", "10.0.0.20", false), // old address
+		member(4, "etcd-old", "10.0.0.4", false),  // dead, not in the inventory
+		member(5, "etcd-live", "10.0.0.5", false), // healthy, not in the inventory
+		member(6, "", "10.0.0.6", true),           // leftover learner
+	}
+	healthy := map[uint64]bool{1: true, 2: true, 5: true}
+
+	actions, err := Plan(members, healthy, hosts())
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"remove-learner unstarted",
+		"remove-member etcd-old",
+		"update-peer-url etcd-vm2",
+		"add-member etcd-vm3",
+		"remove-member etcd-live",
+	}, types(actions))
+
+	assert.Equal(t, "https://10.0.0.20:2380", actions[2].PeerURL)
+	assert.Equal(t, "https://10.0.0.2:2380", actions[2].NewPeerURL)
+	assert.Equal(t, "update-peer-url etcd-vm2 (2): https://10.0.0.20:2380 -> https://10.0.0.2:2380: address changed", actions[2].String())
+}
+
+func TestPlanTakesOverLearnerOfHost(t *testing.T) {
+	members := []*etcdserverpb.Member{
+		member(1, "etcd-vm1", "10.0.0.1", false),
+		member(3, "", "10.0.0.3", true),
+	}
+
+	actions, err := Plan(members, map[uint64]bool{1: true}, hosts())
+	require.NoError(t, err)
+	// The learner is promoted before the next host is added, as etcd
+	// accepts only one learner at a time.
+	assert.Equal(t, []string{"add-member etcd-vm3", "add-member etcd-vm2"}, types(actions))
+	assert.Equal(t, uint64(3), actions[0].MemberID)
+}
+
+func TestPlanUnsafe(t *testing.T) {
+	// Removing the healthy voter that isn't in the inventory would leave
+	// a single healthy voter of two, as etcd-vm2 is dead.
+	members := []*etcdserverpb.Member{
+		member(1, "etcd-vm1", "10.0.0.1", false),
+		member(2, "etcd-vm2", "10.0.0.2", false),
+		member(5, "etcd-live", "10.0.0.5", false),
+	}
+	_, err := Plan(members, map[uint64]bool{1: true, 5: true}, hosts()[:2])
+	require.ErrorIs(t, err, ErrUnsafe)
+	assert.ErrorContains(t, err, "removing etcd-live would leave 1 healthy voters of 2, 2 needed")
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+// Package redact masks secrets in the text written to logs and
+// transcripts.
+package redact
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Mask replaces every secret.
+const Mask = "******"
+
+// secretNames matches the names of flags, variables and fields holding a
+// secret.
+const secretNames = `password|passwd|passphrase|secret|token|api[_-]?key`
+
+var patterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// PEM encoded keys, e.g. the private key of a host.
+	{
+		re:   regexp.MustCompile(`-----BEGIN ([A-Z ]*)PRIVATE KEY-----[\s\S]*?-----END ([A-Z ]*)PRIVATE KEY-----`),
+		repl: "-----BEGIN ${1}PRIVATE KEY-----" + Mask + "-----END ${2}PRIVATE KEY-----",
+	},
+	// Flags, variables and fields named like a secret, e.g.
+	// --password=x, TOKEN=x, "passphrase": "x" or "Authorization: Bearer x".
+	{
+		re:   regexp.MustCompile(`(?i)((?:` + secretNames + `)["']?\s*[:= ]\s*["']?)([^\s"',]+)`),
+		repl: "${1}" + Mask,
+	},
+	{
+		re:   regexp.MustCompile(`(?i)(authorization:\s*(?:bearer|basic)\s+)([^\s"']+)`),
+		repl: "${1}" + Mask,
+	},
+}
+
+var secretKey = regexp.MustCompile(`(?i)` + secretNames)
+
+// IsSecretKey reports whether key, e.g. the name of a field, is named
+// like a secret.
+func IsSecretKey(key string) bool {
+	return secretKey.MatchString(key)
+}
+
+// Redactor masks the configured secrets, and the values that look like
+// secrets whatever they are.
+type Redactor struct {
+	secrets []string
+}
+
+// New returns a Redactor masking the given secrets, e.g. the passwords
+// and passphrases of the hosts. Empty secrets are ignored.
+func New(secrets ...string) *Redactor {
+	r := &Redactor{}
+	for _, s := range secrets {
+		if s != "" {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	// Mask the longest secrets first, in case one contains another.
+	sort.Slice(r.secrets, func(i, j int) bool { return len(r.secrets[i]) > len(r.secrets[j]) })
+	return r
+}
+
+// String returns s with the secrets masked. A nil Redactor only masks the
+// values that look like secrets.
+func (r *Redactor) String(s string) string {
+	if r != nil {
+		for _, secret := range r.secrets {
+			s = strings.ReplaceAll(s, secret, Mask)
+		}
+	}
+	for _, p := range patterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// Bytes is the same as String for a command output.
+func (r *Redactor) Bytes(b []byte) string {
+	return r.String(string(b))
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Markdown renders the report as a Markdown document.
+func (r *Report) Markdown() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString("# etcd recovery report\n\n")
+
+	result := "Succeeded"
+	if !r.Succeeded {
+		result = "Failed"
+	}
+	fmt.Fprintf(&sb, "- **Result:** %s\n", result)
+	if r.Error != "" {
+		fmt.Fprintf(&sb, "- **Error:** %s\n", r.Error)
+	}
+	fmt.Fprintf(&sb, "- **Mode:** %s\n", r.Mode)
+	fmt.Fprintf(&sb, "- **Started:** %s\n", formatTime(r.StartedAt))
+	fmt.Fprintf(&sb, "- **Finished:** %s\n", formatTime(r.FinishedAt))
+	fmt.Fprintf(&sb, "- **Duration:** %s\n", r.Duration)
+
+	if r.Seed != nil {
+		sb.WriteString("\n## Seed\n\n")
+		fmt.Fprintf(&sb, "%s (%s)", r.Seed.Name, r.Seed.Address)
+		if r.Seed.CommitIndex != nil {
+			fmt.Fprintf(&sb, ", commit index %d", *r.Seed.CommitIndex)
+		}
+		fmt.Fprintf(&sb, ": %s.\n", r.Seed.Reason)
+	}
+	if len(r.AbandonedSeeds) > 0 {
+		sb.WriteString("\nAbandoned seeds:\n\n")
+		for _, s := range r.AbandonedSeeds {
+			fmt.Fprintf(&sb, "- %s (%s): %s\n", s.Name, s.Address, s.Error)
+		}
+	}
+
+	if len(r.Hosts) > 0 {
+		sb.WriteString("\n## Hosts\n\n")
+		table(&sb, []string{"Host", "Address", "Commit index"}, len(r.Hosts), func(i int) []string {
+			h := r.Hosts[i]
+			index := "unknown (" + h.CommitIndexError + ")"
+			if h.CommitIndex != nil {
+				index = fmt.Sprint(*h.CommitIndex)
+			}
+			return []string{h.Name, h.Address, index}
+		})
+	}
+
+	sb.WriteString("\n## Members\n\n### Before\n\n")
+	members(&sb, r.MembersBefore, r.MembersBeforeError)
+	sb.WriteString("\n### After\n\n")
+	members(&sb, r.MembersAfter, r.MembersAfterError)
+
+	if len(r.MembershipChanges) > 0 {
+		sb.WriteString("\n## Membership changes\n\n")
+		table(&sb, []string{"Time", "Host", "Change", "Member ID"}, len(r.MembershipChanges), func(i int) []string {
+			c := r.MembershipChanges[i]
+			return []string{formatTime(c.Time), c.Host, c.Change, c.MemberID}
+		})
+	}
+
+	sb.WriteString("\n## Phases\n\n")
+	if len(r.Phases) == 0 {
+		sb.WriteString("No phase ran.\n")
+	} else {
+		table(&sb, []string{"Step", "Host", "Status", "Started", "Duration", "Retries", "Error"}, len(r.Phases), func(i int) []string {
+			p := r.Phases[i]
+			return []string{p.Step, p.Host, p.Status, formatTime(p.StartedAt), p.Duration, fmt.Sprint(p.Retries), p.Error}
+		})
+	}
+
+	if len(r.Manifests) > 0 {
+		sb.WriteString("\n## Manifest changes\n")
+		for _, m := range r.Manifests {
+			fmt.Fprintf(&sb, "\n### %s on %s\n\n%s, at %s.\n\n```diff\n%s```\n", m.Path, m.Host, m.Reason, formatTime(m.Time), m.Diff)
+		}
+	}
+
+	if len(r.Warnings) > 0 {
+		sb.WriteString("\n## Warnings\n\n")
+		for _, w := range r.Warnings {
+			fmt.Fprintf(&sb, "- %s %s: %s\n", formatTime(w.Time), w.Host, w.Message)
+		}
+	}
+
+	if len(r.ContainerLogs) > 0 {
+		sb.WriteString("\n## Container logs\n")
+		for _, l := range r.ContainerLogs {
+			fmt.Fprintf(&sb, "\n### %s on %s\n\nCaptured at %s after: %s\n\n```\n%s\n```\n",
+				l.ContainerID, l.Host, formatTime(l.Time), l.Reason, strings.Join(l.Lines, "\n"))
+		}
+	}
+
+	sb.WriteString("\n## Verification\n\n")
+	if len(r.Verification) == 0 {
+		sb.WriteString("The cluster wasn't verified.\n")
+	} else {
+		table(&sb, []string{"Check", "Result", "Detail"}, len(r.Verification), func(i int) []string {
+			c := r.Verification[i]
+			result := "passed"
+			if !c.Passed {
+				result = "**failed**"
+			}
+			return []string{c.Name, result, c.Detail}
+		})
+	}
+	return sb.String()
+}
+
+func members(sb *strings.Builder, members []Member, errMsg string) {
+	if errMsg != "" {
+		fmt.Fprintf(sb, "Unknown: %s\n", errMsg)
+		return
+	}
+	if len(members) == 0 {
+		sb.WriteString("None.\n")
+		return
+	}
+	table(sb, []string{"ID", "Name", "Peer URLs", "Learner"}, len(members), func(i int) []string {
+		m := members[i]
+		return []string{m.ID, m.Name, strings.Join(m.PeerURLs, ", "), fmt.Sprint(m.IsLearner)}
+	})
+}
+
+// table writes a Markdown table with n rows.
+func table(sb *strings.Builder, header []string, n int, row func(i int) []string) {
+	sb.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat("---|", len(header)) + "\n")
+	for i := 0; i < n; i++ {
+		cells := row(i)
+		for j, c := range cells {
+			cells[j] = strings.ReplaceAll(strings.ReplaceAll(c, "|", `\|`), "\n", " ")
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
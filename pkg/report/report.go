@@ -0,0 +1,283 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+// Package report builds the report of a recovery: how the seed was
+// chosen, the membership before and after, the timing of every phase,
+// the manifests changed, the warnings and the final verification. It is
+// written as Markdown for incident summaries and as JSON for tooling.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"github.com/vmware/etcd-recovery/pkg/diff"
+	"github.com/vmware/etcd-recovery/pkg/event"
+)
+
+// Report is the report of a recovery. It is an event.Observer which
+// records the phases, the manifest and membership changes and the
+// warnings of the plans it observes.
+type Report struct {
+	mu sync.Mutex
+
+	Mode       string    `json:"mode"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   string    `json:"duration"`
+	Succeeded  bool      `json:"succeeded"`
+	Error      string    `json:"error,omitempty"`
+
+	Seed           *Seed           `json:"seed,omitempty"`
+	AbandonedSeeds []AbandonedSeed `json:"abandoned_seeds,omitempty"`
+	Hosts          []*Host         `json:"hosts"`
+
+	MembersBefore      []Member `json:"members_before"`
+	MembersBeforeError string   `json:"members_before_error,omitempty"`
+	MembersAfter       []Member `json:"members_after"`
+	MembersAfterError  string   `json:"members_after_error,omitempty"`
+
+	MembershipChanges []MembershipChange `json:"membership_changes"`
+	Phases            []*Phase           `json:"phases"`
+	Manifests         []ManifestChange   `json:"manifests"`
+	Warnings          []Warning          `json:"warnings"`
+	ContainerLogs     []ContainerLogs    `json:"container_logs,omitempty"`
+	Verification      []Check            `json:"verification"`
+}
+
+var _ event.Observer = (*Report)(nil)
+
+// Seed is the member the cluster was recovered from.
+type Seed struct {
+	Name        string `json:"name"`
+	Address     string `json:"address"`
+	CommitIndex *int   `json:"commit_index,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+// AbandonedSeed is a member the single-member cluster failed to be
+// created from, before falling back to the next one.
+type AbandonedSeed struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Error   string `json:"error"`
+}
+
+// Host is a host of the hosts config and its commit index, if it could
+// be read.
+type Host struct {
+	Name             string `json:"name"`
+	Address          string `json:"address"`
+	CommitIndex      *int   `json:"commit_index,omitempty"`
+	CommitIndexError string `json:"commit_index_error,omitempty"`
+}
+
+// Member is a member of the etcd cluster.
+type Member struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	PeerURLs  []string `json:"peer_urls"`
+	IsLearner bool     `json:"is_learner"`
+}
+
+// MembershipChange is a member added, promoted or removed.
+type MembershipChange struct {
+	Time     time.Time `json:"time"`
+	Host     string    `json:"host,omitempty"`
+	Change   string    `json:"change"`
+	MemberID string    `json:"member_id"`
+}
+
+// Phase is a step of a plan.
+type Phase struct {
+	Plan      string    `json:"plan"`
+	Step      string    `json:"step"`
+	Host      string    `json:"host,omitempty"`
+	Status    string    `json:"status"`
+	StartedAt time.Time `json:"started_at,omitzero"`
+	Duration  string    `json:"duration,omitempty"`
+	Retries   int       `json:"retries,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// ManifestChange is an etcd manifest replaced on a host.
+type ManifestChange struct {
+	Time   time.Time `json:"time"`
+	Host   string    `json:"host"`
+	Path   string    `json:"path"`
+	Reason string    `json:"reason"`
+	Diff   string    `json:"diff"`
+}
+
+// Warning is an unexpected state of the cluster that was worked around.
+type Warning struct {
+	Time    time.Time `json:"time"`
+	Host    string    `json:"host,omitempty"`
+	Message string    `json:"message"`
+}
+
+// ContainerLogs are the last lines of the logs of an etcd container,
+// captured when etcd failed to start or to become healthy.
+type ContainerLogs struct {
+	Time        time.Time `json:"time"`
+	Host        string    `json:"host"`
+	ContainerID string    `json:"container_id"`
+	Reason      string    `json:"reason"`
+	Lines       []string  `json:"lines"`
+}
+
+// Check is a verification of the cluster after the recovery.
+type Check struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// New returns the report of a recovery in mode, started now.
+func New(mode string) *Report {
+	return &Report{Mode: mode, StartedAt: time.Now()}
+}
+
+// Members converts the members listed by etcdctl.
+func Members(members []*etcdserverpb.Member) []Member {
+	converted := make([]Member, 0, len(members))
+	for _, m := range members {
+		converted = append(converted, Member{
+			ID:        fmt.Sprintf("%x", m.ID),
+			Name:      m.Name,
+			PeerURLs:  m.PeerURLs,
+			IsLearner: m.IsLearner,
+		})
+	}
+	return converted
+}
+
+// OnEvent records the phases, manifest and membership changes, the
+// warnings and the logs of the containers which failed.
+func (r *Report) OnEvent(ev event.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := ev.Metadata()
+	switch ev := ev.(type) {
+	case *event.StepStarted:
+		p := r.phase(m.Plan, m.Step)
+		p.Host = m.Host
+		p.Status = "running"
+		p.StartedAt = m.Time
+	case *event.StepFinished:
+		p := r.phase(m.Plan, m.Step)
+		p.Status = ev.Status
+		if ev.Duration > 0 {
+			p.Duration = ev.Duration.Round(time.Millisecond).String()
+		}
+		if ev.Err != nil {
+			p.Error = ev.Err.Error()
+		}
+	case *event.Retry:
+		r.phase(m.Plan, m.Step).Retries++
+	case *event.ManifestChanged:
+		r.Manifests = append(r.Manifests, ManifestChange{
+			Time:   m.Time,
+			Host:   m.Host,
+			Path:   ev.Path,
+			Reason: ev.Reason,
+			Diff:   diff.Unified(string(ev.Old), string(ev.New)),
+		})
+	case *event.MemberAdded:
+		change := "added"
+		if ev.IsLearner {
+			change = "added as learner"
+		}
+		r.addChange(m, change, ev.MemberID)
+	case *event.LearnerPromoted:
+		r.addChange(m, "promoted", ev.MemberID)
+	case *event.MemberRemoved:
+		r.addChange(m, "removed", ev.MemberID)
+	case *event.Warning:
+		r.Warnings = append(r.Warnings, Warning{Time: m.Time, Host: m.Host, Message: ev.Message})
+	case *event.ContainerLogs:
+		r.ContainerLogs = append(r.ContainerLogs, ContainerLogs{
+			Time:        m.Time,
+			Host:        m.Host,
+			ContainerID: ev.ContainerID,
+			Reason:      ev.Reason,
+			Lines:       ev.Lines,
+		})
+	}
+}
+
+func (r *Report) phase(plan, step string) *Phase {
+	for _, p := range r.Phases {
+		if p.Plan == plan && p.Step == step {
+			return p
+		}
+	}
+	p := &Phase{Plan: plan, Step: step, Status: "pending"}
+	r.Phases = append(r.Phases, p)
+	return p
+}
+
+func (r *Report) addChange(m *event.Meta, change string, id uint64) {
+	r.MembershipChanges = append(r.MembershipChanges, MembershipChange{
+		Time:     m.Time,
+		Host:     m.Host,
+		Change:   change,
+		MemberID: fmt.Sprintf("%x", id),
+	})
+}
+
+// Finish records the end of the recovery, which failed if err is set.
+func (r *Report) Finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.FinishedAt = time.Now()
+	r.Duration = r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond).String()
+	r.Succeeded = err == nil
+	if err != nil {
+		r.Error = err.Error()
+	}
+	for _, c := range r.Verification {
+		if !c.Passed {
+			r.Succeeded = false
+		}
+	}
+}
+
+// JSON returns the report as indented JSON.
+func (r *Report) JSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Write writes the report to dir as recovery-report-<time>.md and
+// recovery-report-<time>.json, and returns their paths.
+func (r *Report) Write(dir string) (string, string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	base := filepath.Join(dir, "recovery-report-"+r.StartedAt.UTC().Format("20060102T150405Z"))
+	mdPath, jsonPath := base+".md", base+".json"
+	if err := os.WriteFile(mdPath, []byte(r.Markdown()), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to write report: %w", err)
+	}
+
+	data, err := r.JSON()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := os.WriteFile(jsonPath, append(data, '\n'), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to write report: %w", err)
+	}
+	return mdPath, jsonPath, nil
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"github.com/vmware/etcd-recovery/pkg/event"
+)
+
+func newTestReport() *Report {
+	r := New("both")
+	index := 42
+	r.Seed = &Seed{Name: "etcd-vm1", Address: "10.0.0.1", CommitIndex: &index, Reason: "highest commit index of the hosts"}
+	r.Hosts = []*Host{
+		{Name: "etcd-vm1", Address: "10.0.0.1", CommitIndex: &index},
+		{Name: "etcd-vm2", Address: "10.0.0.2", CommitIndexError: "connection refused"},
+	}
+	r.MembersBefore = Members([]*etcdserverpb.Member{{ID: 0xabc, Name: "etcd-vm1", PeerURLs: []string{"https://10.0.0.1:2380"}}})
+
+	meta := event.Meta{Plan: "RepairCluster", Step: "add-member-etcd-vm2", Host: "10.0.0.1", Time: time.Now()}
+	r.OnEvent(&event.StepStarted{Meta: meta})
+	r.OnEvent(&event.Retry{Meta: meta, Attempt: 1, MaxAttempts: 3})
+	r.OnEvent(&event.MemberAdded{Meta: meta, MemberID: 0xdef, IsLearner: true})
+	r.OnEvent(&event.Warning{Meta: meta, Message: "removed unknown learner 123"})
+	r.OnEvent(&event.LearnerPromoted{Meta: meta, MemberID: 0xdef})
+	r.OnEvent(&event.ManifestChanged{
+		Meta:   meta,
+		Path:   "/etc/kubernetes/manifests/etcd.yaml",
+		Reason: "join the cluster",
+		Old:    []byte("- etcd\n- --initial-cluster-state=new\n"),
+		New:    []byte("- etcd\n- --initial-cluster-state=existing\n"),
+	})
+	r.OnEvent(&event.StepFinished{Meta: meta, Status: "succeeded", Duration: 2 * time.Second})
+	r.OnEvent(&event.CommandRun{Meta: meta, Command: "ignored"})
+
+	r.MembersAfter = Members([]*etcdserverpb.Member{
+		{ID: 0xabc, Name: "etcd-vm1"},
+		{ID: 0xdef, Name: "etcd-vm2"},
+	})
+	r.Verification = []Check{{Name: "No learner left", Passed: true}}
+	return r
+}
+
+func TestReportEvents(t *testing.T) {
+	r := newTestReport()
+
+	require.Len(t, r.Phases, 1)
+	p := r.Phases[0]
+	assert.Equal(t, "add-member-etcd-vm2", p.Step)
+	assert.Equal(t, "10.0.0.1", p.Host)
+	assert.Equal(t, "succeeded", p.Status)
+	assert.Equal(t, "2s", p.Duration)
+	assert.Equal(t, 1, p.Retries)
+
+	require.Len(t, r.MembershipChanges, 2)
+	assert.Equal(t, "added as learner", r.MembershipChanges[0].Change)
+	assert.Equal(t, "def", r.MembershipChanges[0].MemberID)
+	assert.Equal(t, "promoted", r.MembershipChanges[1].Change)
+
+	require.Len(t, r.Manifests, 1)
+	assert.Equal(t, " - etcd\n-- --initial-cluster-state=new\n+- --initial-cluster-state=existing\n", r.Manifests[0].Diff)
+
+	require.Len(t, r.Warnings, 1)
+	assert.Equal(t, "removed unknown learner 123", r.Warnings[0].Message)
+}
+
+func TestReportFinish(t *testing.T) {
+	r := newTestReport()
+	r.Finish(nil)
+	assert.True(t, r.Succeeded)
+	assert.NotEmpty(t, r.Duration)
+
+	r = newTestReport()
+	r.Finish(errors.New("learner never synced"))
+	assert.False(t, r.Succeeded)
+	assert.Equal(t, "learner never synced", r.Error)
+
+	// A failed verification fails the recovery.
+	r = newTestReport()
+	r.Verification = append(r.Verification, Check{Name: "3 members", Detail: "2 members"})
+	r.Finish(nil)
+	assert.False(t, r.Succeeded)
+}
+
+func TestReportMarkdown(t *testing.T) {
+	r := newTestReport()
+	r.AbandonedSeeds = []AbandonedSeed{{Name: "etcd-vm3", Address: "10.0.0.3", Error: "etcd not running"}}
+	r.OnEvent(&event.ContainerLogs{
+		Meta:        event.Meta{Time: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), Host: "10.0.0.2"},
+		ContainerID: "3f4e5d6c",
+		Reason:      "etcd not running",
+		Lines:       []string{"member has already been bootstrapped"},
+	})
+	r.Finish(nil)
+	md := r.Markdown()
+
+	assert.Contains(t, md, "- **Result:** Succeeded\n")
+	assert.Contains(t, md, "etcd-vm1 (10.0.0.1), commit index 42: highest commit index of the hosts.\n")
+	assert.Contains(t, md, "Abandoned seeds:\n\n- etcd-vm3 (10.0.0.3): etcd not running\n")
+	assert.Contains(t, md, "| etcd-vm2 | 10.0.0.2 | unknown (connection refused) |\n")
+	assert.Contains(t, md, "| abc | etcd-vm1 | https://10.0.0.1:2380 | false |\n")
+	assert.Contains(t, md, "| add-member-etcd-vm2 | 10.0.0.1 | succeeded |")
+	assert.Contains(t, md, "```diff\n - etcd\n-- --initial-cluster-state=new\n+- --initial-cluster-state=existing\n```\n")
+	assert.Contains(t, md, ": removed unknown learner 123\n")
+	assert.Contains(t, md, "### 3f4e5d6c on 10.0.0.2\n\nCaptured at 2025-01-01T10:00:00Z after: etcd not running\n\n```\nmember has already been bootstrapped\n```\n")
+	assert.Contains(t, md, "| No learner left | passed |  |\n")
+}
+
+func TestReportWrite(t *testing.T) {
+	r := newTestReport()
+	r.Finish(nil)
+
+	dir := filepath.Join(t.TempDir(), "reports")
+	mdPath, jsonPath, err := r.Write(dir)
+	require.NoError(t, err)
+	assert.Equal(t, ".md", filepath.Ext(mdPath))
+
+	md, err := os.ReadFile(mdPath)
+	require.NoError(t, err)
+	assert.Equal(t, r.Markdown(), string(md))
+
+	data, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "both", decoded["mode"])
+	assert.Equal(t, true, decoded["succeeded"])
+	assert.Equal(t, "etcd-vm1", decoded["seed"].(map[string]any)["name"])
+	assert.Len(t, decoded["members_after"], 2)
+	assert.Len(t, decoded["phases"], 1)
+}
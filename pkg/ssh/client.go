@@ -5,6 +5,7 @@
 package ssh
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -16,6 +17,8 @@ import (
 
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
+
+	"github.com/vmware/etcd-recovery/pkg/transport"
 )
 
 // default constants
@@ -29,6 +32,8 @@ type Client struct {
 	*ssh.Client
 }
 
+var _ transport.Transport = (*Client)(nil)
+
 type Config struct {
 	User                 string
 	Host                 string
@@ -73,15 +78,37 @@ func NewClient(config *Config) (*Client, error) {
 		config.Port = DefaultPort
 	}
 
-	c.Client, err = ssh.Dial("tcp", net.JoinHostPort(config.Host, fmt.Sprint(config.Port)), &ssh.ClientConfig{
-		User:            config.User,
-		Auth:            auth,
-		HostKeyCallback: hostKeyCallback,
-		Timeout:         config.Timeout,
+	// Dial and handshake separately, to tell an unreachable host from a
+	// rejected one.
+	addr := net.JoinHostPort(config.Host, fmt.Sprint(config.Port))
+	conn, err := net.DialTimeout("tcp", addr, config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+
+	// The user is authenticated after the host key is accepted, so the
+	// handshake failing past that point is an authentication failure.
+	hostKeyAccepted := false
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User: config.User,
+		Auth: auth,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if err := hostKeyCallback(hostname, remote, key); err != nil {
+				return err
+			}
+			hostKeyAccepted = true
+			return nil
+		},
+		Timeout: config.Timeout,
 	})
 	if err != nil {
+		conn.Close()
+		if hostKeyAccepted {
+			return nil, fmt.Errorf("%w: %w", ErrAuthFailed, err)
+		}
 		return nil, err
 	}
+	c.Client = ssh.NewClient(sshConn, chans, reqs)
 	return c, nil
 }
 
@@ -99,11 +126,90 @@ func (c Client) Run(cmd string) ([]byte, error) {
 	return sess.CombinedOutput(cmd)
 }
 
+// Exec starts a new SSH session and runs the cmd, it returns stdout, stderr
+// and the exit status separately. A non-zero exit status is reported in the
+// result, err is only set if the command couldn't be run to the end, e.g.
+// the connection was lost or the timeout expired.
+func (c Client) Exec(cmd string, opts transport.ExecOptions) (*transport.Result, error) {
+	sess, err := c.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer sess.Close()
+
+	var stdout, stderr bytes.Buffer
+	sess.Stdout = teeWriter(&stdout, opts.Stdout)
+	sess.Stderr = teeWriter(&stderr, opts.Stderr)
+
+	start := time.Now()
+	if err = sess.Start(cmd); err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sess.Wait()
+	}()
+
+	var timeout <-chan time.Time
+	if opts.Timeout > 0 {
+		timer := time.NewTimer(opts.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	timedOut := false
+	select {
+	case err = <-done:
+	case <-timeout:
+		timedOut = true
+		_ = sess.Signal(ssh.SIGKILL)
+		_ = sess.Close()
+		err = <-done
+	}
+
+	res := &transport.Result{
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		Duration: time.Since(start),
+	}
+	if timedOut {
+		return res, fmt.Errorf("command %q timed out after %v", cmd, opts.Timeout)
+	}
+
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		res.ExitCode = exitErr.ExitStatus()
+		res.Signal = exitErr.Signal()
+		return res, nil
+	}
+	return res, err
+}
+
+// teeWriter returns buf, or a writer duplicating to buf and w if w is set.
+func teeWriter(buf *bytes.Buffer, w io.Writer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(buf, w)
+}
+
 // newSftp returns new sftp client and error if any.
 func (c Client) newSftp(opts ...sftp.ClientOption) (*sftp.Client, error) {
 	return sftp.NewClient(c.Client, opts...)
 }
 
+// Stat returns the file info of the remote path.
+func (c Client) Stat(remotePath string) (os.FileInfo, error) {
+	ftp, err := c.newSftp()
+	if err != nil {
+		return nil, err
+	}
+	defer ftp.Close()
+
+	return ftp.Stat(remotePath)
+}
+
 // Close client net connection.
 func (c Client) Close() error {
 	return c.Client.Close()
@@ -0,0 +1,21 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package ssh
+
+import "errors"
+
+var (
+	// ErrUnreachable is returned when no TCP connection can be made to the
+	// host.
+	ErrUnreachable = errors.New("host unreachable")
+
+	// ErrAuthFailed is returned when the host rejects the password and the
+	// private key.
+	ErrAuthFailed = errors.New("authentication failed")
+
+	// ErrHostKeyMismatch is returned when the key of the host doesn't match
+	// the one in known_hosts, and it wasn't accepted.
+	ErrHostKeyMismatch = errors.New("host key mismatch")
+)
@@ -5,7 +5,6 @@
 package ssh
 
 import (
-	"bufio"
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
@@ -14,9 +13,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/vmware/etcd-recovery/pkg/cliui"
 )
 
 // InteractiveHostKeyCallback creates a host key callback that prompts the user
@@ -83,9 +85,16 @@ func InteractiveHostKeyCallback(knownHostsPath string) (ssh.HostKeyCallback, err
 	}, nil
 }
 
+// hostKeyPromptMu serializes the host key prompts, and the writes to
+// known_hosts, of the hosts connected to concurrently.
+var hostKeyPromptMu sync.Mutex
+
 // promptAndAddHostKey prompts the user to accept or reject an unknown host key
 // and adds it to known_hosts if accepted.
 func promptAndAddHostKey(hostname string, remote net.Addr, key ssh.PublicKey, knownHostsPath string, keyErr *knownhosts.KeyError) error {
+	hostKeyPromptMu.Lock()
+	defer hostKeyPromptMu.Unlock()
+
 	// Get the fingerprint of the host key
 	fingerprint := getHostKeyFingerprint(key)
 
@@ -97,12 +106,23 @@ func promptAndAddHostKey(hostname string, remote net.Addr, key ssh.PublicKey, kn
 	} else {
 		fmt.Printf("This key is not known by any other names.\n")
 	}
-	fmt.Printf("Are you sure you want to continue connecting (yes/no/[fingerprint])? ")
 
-	// Read user input
-	reader := bufio.NewReader(os.Stdin)
-	response, err := reader.ReadString('\n')
+	// Read user input, or the answer given for this host in unattended mode
+	host := hostname
+	if h, _, err := net.SplitHostPort(hostname); err == nil {
+		host = h
+	}
+	response, err := cliui.Input(
+		"host-key."+host,
+		"Are you sure you want to continue connecting (yes/no/[fingerprint])?",
+		"",
+		nil,
+	)
+	mismatch := len(keyErr.Want) > 0
 	if err != nil {
+		if mismatch {
+			return fmt.Errorf("%w for %s: failed to read user input: %w", ErrHostKeyMismatch, hostname, err)
+		}
 		return fmt.Errorf("failed to read user input: %w", err)
 	}
 
@@ -110,7 +130,10 @@ func promptAndAddHostKey(hostname string, remote net.Addr, key ssh.PublicKey, kn
 
 	// Validate user response
 	if response != "yes" && response != "y" && response != strings.ToLower(fingerprint) {
-		return fmt.Errorf("host key verification cancelled by user")
+		if mismatch {
+			return fmt.Errorf("%w for %s: %w", ErrHostKeyMismatch, hostname, cliui.ErrUserCancelled)
+		}
+		return fmt.Errorf("%w: host key verification cancelled by user", cliui.ErrUserCancelled)
 	}
 
 	// Add the host key to known_hosts
@@ -16,8 +16,37 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/ssh"
+
+	"github.com/vmware/etcd-recovery/pkg/cliui"
 )
 
+// TestInteractiveHostKeyCallback_MismatchedKey tests that a changed host
+// key which isn't accepted is reported as a mismatch.
+func TestInteractiveHostKeyCallback_MismatchedKey(t *testing.T) {
+	knownHostsPath := filepath.Join(t.TempDir(), "known_hosts")
+	callback, err := InteractiveHostKeyCallback(knownHostsPath)
+	require.NoError(t, err)
+
+	knownKey, err := generateTestHostKey()
+	require.NoError(t, err)
+	newKey, err := generateTestHostKey()
+	require.NoError(t, err)
+
+	remoteAddr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 22}
+	require.NoError(t, addHostKeyToKnownHosts("testhost:22", remoteAddr, knownKey, knownHostsPath))
+
+	cliui.SetPrompter(cliui.NewScriptedPrompter(map[string]string{"host-key.testhost": "no"}, nil))
+	defer cliui.SetPrompter(nil)
+
+	err = callback("testhost:22", remoteAddr, newKey)
+	require.ErrorIs(t, err, ErrHostKeyMismatch)
+
+	// Without an answer, the mismatch is reported as well.
+	cliui.SetPrompter(cliui.NewScriptedPrompter(nil, nil))
+	err = callback("testhost:22", remoteAddr, newKey)
+	require.ErrorIs(t, err, ErrHostKeyMismatch)
+}
+
 // TestInteractiveHostKeyCallback_UnknownHost tests the interactive callback
 // when encountering an unknown host.
 func TestInteractiveHostKeyCallback_UnknownHost(t *testing.T) {
@@ -89,6 +118,7 @@ func TestInteractiveHostKeyCallback_UnknownHost(t *testing.T) {
 		err = callback2("testhost2", remoteAddr, hostKey)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "cancelled by user")
+		require.ErrorIs(t, err, cliui.ErrUserCancelled)
 
 		// Verify the host key was NOT added to known_hosts
 		content, err := os.ReadFile(knownHostsPath2)
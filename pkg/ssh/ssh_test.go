@@ -6,12 +6,14 @@ package ssh
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -20,6 +22,8 @@ import (
 	"github.com/pkg/sftp"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/ssh"
+
+	"github.com/vmware/etcd-recovery/pkg/transport"
 )
 
 var (
@@ -92,7 +96,7 @@ func TestSSHConnectionWithWrongPassword(t *testing.T) {
 	hostConfig.SetHostKeyCallback(ssh.FixedHostKey(hostPubKey))
 
 	// Setup mock server
-	server, err := NewServerLocal(hostConfig.User, "testpass", hostConfig.Port, "./testdata")
+	server, err := NewServerLocal(hostConfig.User, "testpass", hostConfig.Port, t.TempDir())
 	require.NoError(t, err)
 
 	// Start the server
@@ -105,7 +109,50 @@ func TestSSHConnectionWithWrongPassword(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	_, err = NewClient(hostConfig)
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrAuthFailed)
+}
+
+func TestSSHConnectionWithRejectedHostKey(t *testing.T) {
+	hostConfig := &Config{
+		User:     "testuser",
+		Host:     "127.0.0.1",
+		Port:     2022,
+		Timeout:  30 * time.Second,
+		Password: "123456",
+	}
+	errRejected := errors.New("host key rejected")
+	hostConfig.SetHostKeyCallback(func(string, net.Addr, ssh.PublicKey) error {
+		return errRejected
+	})
+
+	server, err := NewServerLocal(hostConfig.User, "testpass", hostConfig.Port, t.TempDir())
+	require.NoError(t, err)
+	err = server.Start()
+	require.NoError(t, err)
+	defer server.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = NewClient(hostConfig)
+	require.ErrorIs(t, err, errRejected)
+	require.NotErrorIs(t, err, ErrAuthFailed)
+}
+
+func TestSSHConnectionToUnreachableHost(t *testing.T) {
+	// Nothing listens on this port.
+	hostConfig := &Config{
+		User:     "testuser",
+		Host:     "127.0.0.1",
+		Port:     2021,
+		Timeout:  5 * time.Second,
+		Password: "testpass",
+	}
+	hostPubKey, _, _, _, err := ssh.ParseAuthorizedKey(serverPublicKeyBytes)
+	require.NoError(t, err)
+	hostConfig.SetHostKeyCallback(ssh.FixedHostKey(hostPubKey))
+
+	_, err = NewClient(hostConfig)
+	require.ErrorIs(t, err, ErrUnreachable)
 }
 
 func TestSSHConnectionWithCorrectPassword(t *testing.T) {
@@ -124,7 +171,7 @@ func TestSSHConnectionWithCorrectPassword(t *testing.T) {
 	hostConfig.SetHostKeyCallback(ssh.FixedHostKey(hostPubKey))
 
 	// Setup mock server
-	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, "./testdata")
+	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, t.TempDir())
 	require.NoError(t, err)
 
 	// Start the server
@@ -157,7 +204,7 @@ func TestSSHConnectionWithPrivateKey(t *testing.T) {
 	hostConfig.SetHostKeyCallback(ssh.FixedHostKey(hostPubKey))
 
 	// Setup mock server
-	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, "./testdata")
+	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, t.TempDir())
 	require.NoError(t, err)
 
 	// Start the server
@@ -190,7 +237,7 @@ func TestRunCommandOnLocalServer(t *testing.T) {
 	hostConfig.SetHostKeyCallback(ssh.FixedHostKey(hostPubKey))
 
 	// Setup mock server
-	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, "./testdata")
+	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, t.TempDir())
 	require.NoError(t, err)
 
 	// Start the server
@@ -215,6 +262,59 @@ func TestRunCommandOnLocalServer(t *testing.T) {
 	}
 }
 
+func TestExecCommandOnLocalServer(t *testing.T) {
+	hostConfig := &Config{
+		User:           "testuser",
+		Host:           "127.0.0.1",
+		Port:           2020,
+		Timeout:        30 * time.Second,
+		PrivateKeyPath: "testdata/id_test",
+	}
+
+	// prepare server config
+	hostPubKey, _, _, _, err := ssh.ParseAuthorizedKey(serverPublicKeyBytes)
+	require.NoError(t, err)
+
+	hostConfig.SetHostKeyCallback(ssh.FixedHostKey(hostPubKey))
+
+	// Setup mock server
+	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, t.TempDir())
+	require.NoError(t, err)
+
+	// Start the server
+	err = server.Start()
+	require.NoError(t, err)
+
+	defer server.Stop()
+
+	// Give the server a moment to start
+	time.Sleep(100 * time.Millisecond)
+
+	client, err := NewClient(hostConfig)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// stdout, stderr and exit status are reported separately
+	var streamed bytes.Buffer
+	res, err := client.Exec("exit 3", transport.ExecOptions{Stdout: &streamed})
+	require.NoError(t, err)
+	require.Equal(t, "to stdout\n", string(res.Stdout))
+	require.Equal(t, "to stderr\n", string(res.Stderr))
+	require.Equal(t, 3, res.ExitCode)
+	require.Equal(t, "to stdout\n", streamed.String())
+	require.ErrorContains(t, res.Err(), "Process exited with status 3: to stderr")
+
+	res, err = client.Exec("hey!!", transport.ExecOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "HI, i am handled\n", string(res.Stdout))
+	require.Equal(t, 0, res.ExitCode)
+	require.NoError(t, res.Err())
+
+	// the command is abandoned once the timeout expires
+	_, err = client.Exec("sleep 2s", transport.ExecOptions{Timeout: 100 * time.Millisecond})
+	require.ErrorContains(t, err, "timed out")
+}
+
 func TestUploadFileToLocalServer(t *testing.T) {
 	hostConfig := &Config{
 		User:           "testuser",
@@ -231,7 +331,7 @@ func TestUploadFileToLocalServer(t *testing.T) {
 	hostConfig.SetHostKeyCallback(ssh.FixedHostKey(hostPubKey))
 
 	// Setup mock server
-	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, "./testdata")
+	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, t.TempDir())
 	require.NoError(t, err)
 
 	// Start the server
@@ -282,7 +382,7 @@ func TestDownloadFileFromLocalServer(t *testing.T) {
 	hostConfig.SetHostKeyCallback(ssh.FixedHostKey(hostPubKey))
 
 	// Setup mock server
-	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, "./testdata")
+	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, t.TempDir())
 	require.NoError(t, err)
 
 	// Start the server
@@ -332,7 +432,7 @@ func TestUploadFileWithSudoFallback(t *testing.T) {
 	require.NoError(t, err)
 	hostConfig.SetHostKeyCallback(ssh.FixedHostKey(hostPubKey))
 
-	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, "./testdata")
+	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, t.TempDir())
 	require.NoError(t, err)
 
 	// Set restricted path
@@ -392,7 +492,7 @@ func TestDownloadFileWithSudoFallback(t *testing.T) {
 	require.NoError(t, err)
 	hostConfig.SetHostKeyCallback(ssh.FixedHostKey(hostPubKey))
 
-	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, "./testdata")
+	server, err := NewServerLocal(hostConfig.User, hostConfig.Password, hostConfig.Port, t.TempDir())
 	require.NoError(t, err)
 
 	restrictedPath := "restricted_download.txt"
@@ -687,8 +787,21 @@ func (s *Server) handleChannel(channel ssh.Channel, requests <-chan *ssh.Request
 				_ = os.Remove(target)
 			}
 
-			_, _ = channel.Write([]byte("HI, i am handled\n"))
 			req.Reply(true, nil)
+			if len(parts) >= 2 && parts[0] == "exit" {
+				// write to both streams and exit with the given status
+				status, _ := strconv.Atoi(parts[1])
+				_, _ = channel.Write([]byte("to stdout\n"))
+				_, _ = channel.Stderr().Write([]byte("to stderr\n"))
+				channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+				return
+			}
+			if len(parts) >= 2 && parts[0] == "sleep" {
+				d, _ := time.ParseDuration(parts[1])
+				time.Sleep(d)
+			}
+
+			_, _ = channel.Write([]byte("HI, i am handled\n"))
 			// just return error 0.
 			channel.SendRequest("exit-status", false, []byte{0, 0, 0, 0})
 			return // close session after execution
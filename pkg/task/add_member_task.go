@@ -7,10 +7,11 @@ package task
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,7 +22,9 @@ import (
 
 	"github.com/vmware/etcd-recovery/pkg/cliui"
 	"github.com/vmware/etcd-recovery/pkg/config"
-	"github.com/vmware/etcd-recovery/pkg/ssh"
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/logging"
+	"github.com/vmware/etcd-recovery/pkg/transport"
 )
 
 type AddMemberTask struct {
@@ -29,14 +32,45 @@ type AddMemberTask struct {
 	Master      *config.Host
 	Learner     *config.Host
 	AllHosts    []*config.Host
+
+	// RemoveLearnerOnFailure removes the learner from the cluster when it
+	// couldn't be started or promoted, so that the next member can be
+	// added. etcd only accepts one learner at a time.
+	RemoveLearnerOnFailure bool
+
+	// PromoteAttempts is the number of attempts to promote the learner,
+	// 50 by default.
+	PromoteAttempts int
+
+	// Connect opens a transport to the learner, it defaults to
+	// config.Host.Connect.
+	Connect func(h *config.Host) (transport.Transport, error)
+
+	// manifestUploaded is set once the manifest joining the cluster has
+	// been uploaded to the learner.
+	manifestUploaded bool
 }
 
 func (t *AddMemberTask) Name() string {
 	return "AddMemberTask"
 }
 
-func (t *AddMemberTask) Run(client *ssh.Client) (string, error) {
-	log.Printf("Starting AddMemberTask for learner %s (%s)\n", t.Learner.Name, t.Learner.Host)
+// logger returns the logger of the records about the learner.
+func (t *AddMemberTask) logger() *slog.Logger {
+	return slog.With(logging.Host(t.Learner.Name, t.Learner.Host))
+}
+
+func (t *AddMemberTask) Run(client transport.Transport) (string, error) {
+	out, err := t.run(client)
+	if err != nil && t.RemoveLearnerOnFailure {
+		t.removeFailedLearner(client)
+		t.stopFailedLearner(client)
+	}
+	return out, err
+}
+
+func (t *AddMemberTask) run(client transport.Transport) (string, error) {
+	t.logger().Info("Adding learner to the cluster")
 
 	// Add or promote learner on master node
 	promoted, err := t.addOrPromoteLearner(client)
@@ -45,7 +79,7 @@ func (t *AddMemberTask) Run(client *ssh.Client) (string, error) {
 	}
 
 	if promoted {
-		log.Printf("Learner %s (%s) was already added and promoted successfully\n", t.Learner.Name, t.Learner.Host)
+		t.logger().Info("Learner was already added and promoted")
 		return "learner already promoted", nil
 	}
 
@@ -61,10 +95,10 @@ func (t *AddMemberTask) Run(client *ssh.Client) (string, error) {
 	}
 
 	if !promoted {
-		return "", fmt.Errorf("learner was not promoted after starting")
+		return "", fmt.Errorf("learner was not promoted after starting: %w", ErrLearnerNotInSync)
 	}
 
-	log.Printf("Successfully added and promoted learner %s (%s)\n", t.Learner.Name, t.Learner.Host)
+	t.logger().Info("Learner added and promoted")
 	return "learner added and promoted successfully", nil
 }
 
@@ -72,8 +106,8 @@ func (t *AddMemberTask) Run(client *ssh.Client) (string, error) {
 // Returned values:
 //   - bool: true means a learner is promoted; false means a learner is added
 //   - error: error if any
-func (t *AddMemberTask) addOrPromoteLearner(masterClient *ssh.Client) (bool, error) {
-	log.Printf("AddOrPromoteLearner: checking cluster health and member status\n")
+func (t *AddMemberTask) addOrPromoteLearner(masterClient transport.Transport) (bool, error) {
+	t.logger().Debug("Checking cluster health and member status")
 	var member *etcdserverpb.Member
 	var memberID uint64
 	var err error
@@ -93,19 +127,19 @@ func (t *AddMemberTask) addOrPromoteLearner(masterClient *ssh.Client) (bool, err
 	}
 
 	if member != nil {
-		log.Printf("Member %s (%s) already exists in cluster (ID: %x, IsLearner: %v)\n", t.Learner.Name, t.Learner.Host, fmt.Sprintf("%x", member.ID), member.IsLearner)
+		t.logger().Info("Member already exists in cluster", "member_id", fmt.Sprintf("%x", member.ID), "is_learner", member.IsLearner)
 		if member.IsLearner {
 			if member.Name == "" {
 				// The previous repair process was canceled or interrupted after the
 				// learner was added but before the learner had actually started.
-				log.Printf("The learner %x (%v) isn't started yet", member.ID, member.PeerURLs)
+				t.logger().Info("Learner isn't started yet", "member_id", fmt.Sprintf("%x", member.ID), "peer_urls", member.PeerURLs)
 				return false, nil
 			}
-			log.Printf("Attempting to promote learner %s (%s)\n", t.Learner.Name, t.Learner.Host)
+			t.logger().Info("Promoting learner")
 			if err = t.promoteLearner(masterClient, containerID, fmt.Sprintf("%x", member.ID)); err != nil {
 				return false, fmt.Errorf("failed to promote learner: %w", err)
 			}
-			log.Printf("Successfully promoted learner %s (%s)\n", t.Learner.Name, t.Learner.Host)
+			t.logger().Info("Learner promoted")
 			return true, nil
 		}
 		return true, nil
@@ -117,16 +151,25 @@ func (t *AddMemberTask) addOrPromoteLearner(masterClient *ssh.Client) (bool, err
 		return false, err
 	}
 
-	log.Printf("Adding new member %s (%s) as learner\n", t.Learner.Name, t.Learner.Host)
+	t.logger().Info("Adding new member as learner")
 	if memberID, err = t.addMemberToCluster(masterClient, containerID, true); err != nil {
 		return false, fmt.Errorf("failed to add member %s (%s): %w", t.Learner.Name, t.Learner.Host, err)
 	}
 
-	log.Printf("Successfully added member %s with ID: %x", t.Learner.Name, fmt.Sprintf("%x", memberID))
+	if memberID != 0 {
+		event.Emit(masterClient, &event.MemberAdded{
+			Meta:      event.Meta{Host: t.Learner.Host},
+			MemberID:  memberID,
+			Name:      t.Learner.Name,
+			PeerURL:   fmt.Sprintf("https://%s:2380", t.Learner.Host),
+			IsLearner: true,
+		})
+	}
+	t.logger().Info("Member added", "member_id", fmt.Sprintf("%x", memberID))
 	return false, nil
 }
 
-func (t *AddMemberTask) handleOtherLearnersIfExists(masterClient *ssh.Client, containerID string) error {
+func (t *AddMemberTask) handleOtherLearnersIfExists(masterClient transport.Transport, containerID string) error {
 	// check for other learners if exists?
 	otherLearnerMembers := t.fetchLearnerMembers(masterClient, containerID)
 	if len(otherLearnerMembers) == 0 {
@@ -140,23 +183,99 @@ func (t *AddMemberTask) handleOtherLearnersIfExists(masterClient *ssh.Client, co
 
 	// extract learnerIP
 	learnerIP := extractIPFromPeerURL(otherLearnerMembers[0].PeerURLs[0])
-	log.Printf("Found other learner (%v) in cluster, handling...\n", learnerIP)
+	t.logger().Info("Found another learner in cluster", "learner_address", learnerIP)
 	if t.isKnownHost(otherLearnerMembers[0].PeerURLs[0]) {
 		errorMsg := fmt.Sprintf("Another learner vm (%s) has been added but not started yet. Please add it again first.", learnerIP)
-		log.Printf("WARNING: %s\n", errorMsg)
+		t.logger().Warn(errorMsg)
 		return fmt.Errorf("%s", errorMsg)
 	}
 
 	// Remove the unknown learner
-	log.Printf("Removing unknown learner %x at %s", otherLearnerMembers[0].ID, learnerIP)
+	t.logger().Warn("Removing unknown learner", "member_id", fmt.Sprintf("%x", otherLearnerMembers[0].ID), "learner_address", learnerIP)
 	if err := t.removeMember(masterClient, containerID, fmt.Sprintf("%x", otherLearnerMembers[0].ID)); err != nil {
 		return fmt.Errorf("failed to remove unknown learner %x: %w", otherLearnerMembers[0].ID, err)
 	}
-	log.Printf("Successfully removed unknown learner %x at %s", otherLearnerMembers[0].ID, learnerIP)
+	t.logger().Info("Unknown learner removed", "member_id", fmt.Sprintf("%x", otherLearnerMembers[0].ID), "learner_address", learnerIP)
+	event.Emit(masterClient, &event.Warning{
+		Message: fmt.Sprintf("Removed unknown learner %x at %s, which isn't in the hosts config", otherLearnerMembers[0].ID, learnerIP),
+	})
 
 	return nil
 }
 
+// removeFailedLearner removes the learner if it was added to the cluster
+// but not promoted. Failures are only logged, as the learner failing is
+// already reported.
+func (t *AddMemberTask) removeFailedLearner(masterClient transport.Transport) {
+	containerID, err := t.getEtcdContainerID(masterClient)
+	if err != nil {
+		t.logger().Warn("Failed to remove the learner after the failure to add it", "err", err)
+		return
+	}
+
+	member, err := t.querryMember(masterClient, containerID)
+	if err != nil {
+		t.logger().Warn("Failed to remove the learner after the failure to add it", "err", err)
+		return
+	}
+	if member == nil || !member.IsLearner {
+		return
+	}
+
+	memberID := fmt.Sprintf("%x", member.ID)
+	if err := t.removeMember(masterClient, containerID, memberID); err != nil {
+		t.logger().Warn("Failed to remove the learner after the failure to add it", "member_id", memberID, "err", err)
+		return
+	}
+	event.Emit(masterClient, &event.Warning{
+		Meta:    event.Meta{Host: t.Learner.Host},
+		Message: fmt.Sprintf("Removed learner %s of %s from the cluster after the failure to add it", memberID, t.Learner.Name),
+	})
+}
+
+// stopFailedLearner stops etcd on the learner once its manifest has been
+// uploaded, as kubelet would otherwise keep running or restarting it, and
+// adding the learner again would fail. The manifest is rebuilt from the
+// backed up one then. Failures are only logged, as removeFailedLearner.
+func (t *AddMemberTask) stopFailedLearner(masterClient transport.Transport) {
+	if !t.manifestUploaded {
+		return
+	}
+
+	learnerClient, err := t.connectLearner(masterClient)
+	if err != nil {
+		t.logger().Warn("Failed to stop etcd on the learner after the failure to add it", "err", err)
+		return
+	}
+	defer learnerClient.Close()
+
+	stop := &StopEtcdTask{Description: "Stop the failed learner", Reason: "stop the learner which failed to join"}
+	if _, err := stop.Run(learnerClient); err != nil {
+		t.logger().Warn("Failed to stop etcd on the learner after the failure to add it", "err", err)
+		return
+	}
+	t.manifestUploaded = false
+	event.Emit(masterClient, &event.Warning{
+		Meta:    event.Meta{Host: t.Learner.Host},
+		Message: fmt.Sprintf("Stopped etcd on %s by removing its manifest after the failure to add it", t.Learner.Name),
+	})
+}
+
+// connectLearner opens a transport to the learner, whose events go to the
+// observers of masterClient.
+func (t *AddMemberTask) connectLearner(masterClient transport.Transport) (transport.Transport, error) {
+	connect := t.Connect
+	if connect == nil {
+		connect = (*config.Host).Connect
+	}
+
+	conn, err := connect(t.Learner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Learner node: %w", err)
+	}
+	return event.Attach(masterClient, conn, t.Learner.Host), nil
+}
+
 func (t *AddMemberTask) isKnownHost(peerURL string) bool {
 	isKnownHost := false
 	learnerIP := extractIPFromPeerURL(peerURL)
@@ -169,34 +288,31 @@ func (t *AddMemberTask) isKnownHost(peerURL string) bool {
 	return isKnownHost
 }
 
-func (t *AddMemberTask) removeMember(client *ssh.Client, containerID string, memberID string) error {
-	log.Printf("Removing member %s", memberID)
-	out, err := t.execEtcdctl(client, containerID, "member", "remove", memberID)
+func (t *AddMemberTask) removeMember(client transport.Transport, containerID string, memberID string) error {
+	t.logger().Info("Removing member", "member_id", memberID)
+	removed, err := removeMember(client, containerID, memberID)
 	if err != nil {
-		if strings.Contains(out, "Member not found") {
-			log.Printf("Member %s already removed", memberID)
-			return nil
-		}
 		return fmt.Errorf("failed to remove member %s: %w", memberID, err)
 	}
-	log.Printf("Member %s removed successfully", memberID)
+	if !removed {
+		t.logger().Info("Member already removed", "member_id", memberID)
+		return nil
+	}
+	if id, err := strconv.ParseUint(memberID, 16, 64); err == nil {
+		event.Emit(client, &event.MemberRemoved{MemberID: id})
+	}
+	t.logger().Info("Member removed", "member_id", memberID)
 	return nil
 }
 
-func (t *AddMemberTask) startLearner(masterClient *ssh.Client) error {
-	learnerClient, err := ssh.NewClient(&ssh.Config{
-		User:                 t.Learner.Username,
-		Host:                 t.Learner.Host,
-		Password:             t.Learner.Password,
-		PrivateKeyPath:       t.Learner.PrivateKey,
-		PrivateKeyPassphrase: t.Learner.Passphrase,
-	})
+func (t *AddMemberTask) startLearner(masterClient transport.Transport) error {
+	learnerClient, err := t.connectLearner(masterClient)
 	if err != nil {
-		return fmt.Errorf("failed to connect to Learner node: %w", err)
+		return err
 	}
 	defer learnerClient.Close()
 
-	log.Printf("StartLearner: starting learner on %s (%s)\n", t.Learner.Name, t.Learner.Host)
+	t.logger().Info("Starting learner")
 
 	// Check if etcd is already running
 	checkEtcdCmd := "sudo crictl ps --label io.kubernetes.container.name=etcd -q | head -n 1"
@@ -205,28 +321,30 @@ func (t *AddMemberTask) startLearner(masterClient *ssh.Client) error {
 		return fmt.Errorf("etcd is already running on %s (container ID: %s), please stop it before adding as learner", t.Learner.Host, strings.TrimSpace(string(out)))
 	}
 
-	log.Printf("Confirmed etcd is not running on %s (%s)\n", t.Learner.Name, t.Learner.Host)
+	t.logger().Debug("Confirmed etcd is not running")
 
 	if err = t.cleanupLocalDataOnLearner(learnerClient, t.Learner, "/var/lib/etcd/member"); err != nil {
 		return fmt.Errorf("failed to cleanup data directory: %w", err)
 	}
-	log.Printf("Successfully cleaned up etcd data directory on %s (%s)\n", t.Learner.Name, t.Learner.Host)
+	t.logger().Debug("Cleaned up etcd data directory")
 
 	initialCluster, err := t.buildInitialClusterString(masterClient)
 	if err != nil {
 		return fmt.Errorf("failed to build initial-cluster string: %w", err)
 	}
-	log.Printf("Built initial-cluster string: %s\n", initialCluster)
+	t.logger().Debug("Built initial-cluster string", "initial_cluster", initialCluster)
 
-	localEtcdPath, err := t.updateManifest(learnerClient, initialCluster, "existing")
+	localEtcdPath, oldManifest, err := t.updateManifest(learnerClient, initialCluster, "existing")
 	if err != nil {
 		return fmt.Errorf("failed to update etcd manifest %w, on learner %s (%s)", err, t.Learner.Name, t.Learner.Host)
 	}
 
-	if err = learnerClient.Upload(localEtcdPath, "/etc/kubernetes/manifests/etcd.yaml"); err != nil {
+	t.manifestUploaded = true
+	if err = learnerClient.Upload(localEtcdPath, EtcdManifestPath); err != nil {
 		return fmt.Errorf("failed to upload manifest: %w, on learner %s (%s)", err, t.Learner.Name, t.Learner.Host)
 	}
-	log.Printf("Successfully uploaded etcd manifest on %s (%s)\n", t.Learner.Name, t.Learner.Host)
+	emitManifestChanged(learnerClient, localEtcdPath, "join the cluster as learner", oldManifest)
+	t.logger().Info("Uploaded etcd manifest", "path", EtcdManifestPath)
 
 	containerID, err := t.getEtcdContainerID(learnerClient)
 	if err != nil {
@@ -236,12 +354,12 @@ func (t *AddMemberTask) startLearner(masterClient *ssh.Client) error {
 	if err := t.waitForClusterOrMemberStatusHealthy(learnerClient, containerID, false); err != nil {
 		return fmt.Errorf("learner health status check failed: %w", err)
 	}
-	log.Printf("etcd container %s is running on %s (%s), as learner\n", strings.TrimSpace(containerID), t.Learner.Name, t.Learner.Host)
+	t.logger().Info("etcd is running as learner", "container_id", strings.TrimSpace(containerID))
 
 	return nil
 }
 
-func (t *AddMemberTask) querryMember(client *ssh.Client, containerID string) (member *etcdserverpb.Member, err error) {
+func (t *AddMemberTask) querryMember(client transport.Transport, containerID string) (member *etcdserverpb.Member, err error) {
 	membersResp, err := t.getMembers(client, containerID)
 	if err != nil {
 		return nil, err
@@ -255,20 +373,20 @@ func (t *AddMemberTask) querryMember(client *ssh.Client, containerID string) (me
 	for _, member := range membersResp.Members {
 		for _, peerURL := range member.PeerURLs {
 			if memberIP := extractIPFromPeerURL(peerURL); memberIP != "" && memberIP == t.Learner.Host {
-				log.Printf("Member check result for %s (%s): exists=%v, isLearner=%v, found by PeerURL", t.Learner.Host, learnerMemberName, true, member.IsLearner)
+				t.logger().Debug("Member found by peer URL", "member_name", learnerMemberName, "is_learner", member.IsLearner)
 				return member, nil
 			}
 		}
 	}
 
-	log.Printf("Member check result for %s (%s): exists=false", t.Learner.Host, learnerMemberName)
+	t.logger().Debug("Member not found", "member_name", learnerMemberName)
 	return nil, nil
 }
 
-func (t *AddMemberTask) fetchLearnerMembers(client *ssh.Client, containerID string) (members []*etcdserverpb.Member) {
+func (t *AddMemberTask) fetchLearnerMembers(client transport.Transport, containerID string) (members []*etcdserverpb.Member) {
 	membersResp, err := t.getMembers(client, containerID)
 	if err != nil {
-		log.Printf("failed to get members list: %v", err)
+		t.logger().Warn("Failed to get members list", "err", err)
 		return members
 	}
 
@@ -286,14 +404,14 @@ func (t *AddMemberTask) fetchLearnerMembers(client *ssh.Client, containerID stri
 func extractIPFromPeerURL(rawURL string) string {
 	u, err := url.Parse(rawURL)
 	if err != nil {
-		log.Printf("error parsing peerURL: %v, err: %v\n", rawURL, err)
+		slog.Warn("Failed to parse peer URL", "peer_url", rawURL, "err", err)
 		return ""
 	}
 	return u.Hostname()
 }
 
-func (t *AddMemberTask) getMembers(client *ssh.Client, containerID string) (*clientv3.MemberListResponse, error) {
-	out, err := t.execEtcdctl(client, containerID, "member", "list", "-w", "json")
+func (t *AddMemberTask) getMembers(client transport.Transport, containerID string) (*clientv3.MemberListResponse, error) {
+	out, err := Etcdctl(client, containerID, "member", "list", "-w", "json")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list members: %w", err)
 	}
@@ -302,11 +420,11 @@ func (t *AddMemberTask) getMembers(client *ssh.Client, containerID string) (*cli
 	if err := json.Unmarshal([]byte(out), &resp); err != nil {
 		return nil, fmt.Errorf("failed to parse member list: %w", err)
 	}
-	log.Printf("Current cluster has %d members", len(resp.Members))
+	t.logger().Debug("Listed cluster members", "count", len(resp.Members))
 	return &resp, nil
 }
 
-func (t *AddMemberTask) addMemberToCluster(masterClient *ssh.Client, containerID string, isLearner bool) (uint64, error) {
+func (t *AddMemberTask) addMemberToCluster(masterClient transport.Transport, containerID string, isLearner bool) (uint64, error) {
 	peerURLs := fmt.Sprintf("https://%s:2380", t.Learner.Host)
 	learnerMemberName, err := t.Learner.FetchMemberName()
 	if err != nil {
@@ -318,9 +436,9 @@ func (t *AddMemberTask) addMemberToCluster(masterClient *ssh.Client, containerID
 		args = append(args, "--learner")
 	}
 
-	out, err := t.execEtcdctl(masterClient, containerID, args...)
+	out, err := Etcdctl(masterClient, containerID, args...)
 	if err != nil {
-		if strings.Contains(out, "Error: etcdserver: Peer URLs already exists") {
+		if strings.Contains(err.Error(), "Error: etcdserver: Peer URLs already exists") {
 			return 0, nil
 		}
 		return 0, fmt.Errorf("failed to add member: %w", err)
@@ -334,7 +452,7 @@ func (t *AddMemberTask) addMemberToCluster(masterClient *ssh.Client, containerID
 	return addResponse.Member.ID, nil
 }
 
-func (t *AddMemberTask) getEtcdContainerID(client *ssh.Client) (string, error) {
+func (t *AddMemberTask) getEtcdContainerID(client transport.Transport) (string, error) {
 	waitTask := &WaitForEtcdRunningTask{
 		Description:      "Get etcd container ID",
 		TimeoutSec:       300,
@@ -349,36 +467,44 @@ func (t *AddMemberTask) getEtcdContainerID(client *ssh.Client) (string, error) {
 	return strings.TrimSpace(containerID), nil
 }
 
-func (t *AddMemberTask) waitForClusterOrMemberStatusHealthy(client *ssh.Client, containerID string, cluster bool) error {
+func (t *AddMemberTask) waitForClusterOrMemberStatusHealthy(client transport.Transport, containerID string, cluster bool) error {
 	msg := "current member"
 	args := []string{"endpoint", "status", "-w", "json"}
 	if cluster {
 		msg = "cluster"
 		args = append(args, "--cluster")
 	}
-	log.Printf("Waiting for %s to be healthy\n", msg)
+	t.logger().Info("Waiting for " + msg + " to be healthy")
 
 	maxRetries := 20
 	retryInterval := 5 * time.Second
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		out, err := t.execEtcdctl(client, containerID, args...)
+		out, err := Etcdctl(client, containerID, args...)
 		if err == nil {
 			if validateClusterStatus([]byte(out)) {
-				log.Printf("%s is healthy\n", msg)
+				t.logger().Info(msg + " is healthy")
 				return nil
 			}
-			log.Printf("%s is not healthy: %s, attempt %d/%d\n", msg, out, attempt+1, maxRetries)
+			t.logger().Debug(msg+" is not healthy", "status", out, "attempt", attempt+1, "max_attempts", maxRetries)
+			err = fmt.Errorf("%s is not healthy: %s", msg, out)
 		} else {
-			log.Printf("%s health check failed: %v, attempt %d/%d\n", msg, err, attempt+1, maxRetries)
+			t.logger().Debug(msg+" health check failed", "err", err, "attempt", attempt+1, "max_attempts", maxRetries)
 		}
+		event.Emit(client, &event.Retry{
+			Description: fmt.Sprintf("wait for %s to be healthy", msg),
+			Attempt:     attempt + 1,
+			MaxAttempts: maxRetries,
+			Wait:        retryInterval,
+			Err:         err,
+		})
 		time.Sleep(retryInterval)
 	}
 
 	return fmt.Errorf("%s did not become healthy after %d attempts", msg, maxRetries)
 }
 
-func (t *AddMemberTask) buildInitialClusterString(masterClient *ssh.Client) (string, error) {
+func (t *AddMemberTask) buildInitialClusterString(masterClient transport.Transport) (string, error) {
 	containerID, err := t.getEtcdContainerID(masterClient)
 	if err != nil {
 		return "", fmt.Errorf("failed to get etcd container ID: %w", err)
@@ -415,85 +541,94 @@ func (t *AddMemberTask) buildInitialClusterString(masterClient *ssh.Client) (str
 	return strings.Join(parts, ","), nil
 }
 
-func (t *AddMemberTask) promoteLearner(client *ssh.Client, containerID string, MemberID string) error {
+func (t *AddMemberTask) promoteLearner(client transport.Transport, containerID string, MemberID string) error {
 	maxRetries := 50
+	if t.PromoteAttempts > 0 {
+		maxRetries = t.PromoteAttempts
+	}
 	retryInterval := 5 * time.Second
 
-	log.Printf("Attempting to promote member %s\n", strings.TrimSpace(MemberID))
+	t.logger().Info("Promoting member", "member_id", strings.TrimSpace(MemberID))
 
 	var lastErr error
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		_, err := t.execEtcdctl(client, containerID, "member", "promote", strings.TrimSpace(MemberID))
+		// Each attempt runs etcdctl once, the attempts are retried here.
+		promote := &CommandTask{
+			Description: "Promote member",
+			Command:     EtcdctlCommand(containerID, "member", "promote", strings.TrimSpace(MemberID)),
+			Check:       &Check{ExpectedExitCode: 0, TimeoutSec: 30, Retry: &RetryPolicy{MaxAttempts: 1}},
+		}
+		_, err := promote.Run(client)
 		if err == nil {
-			log.Printf("Member %s promoted successfully\n", MemberID)
+			if id, err := strconv.ParseUint(strings.TrimSpace(MemberID), 16, 64); err == nil {
+				event.Emit(client, &event.LearnerPromoted{
+					Meta:     event.Meta{Host: t.Learner.Host},
+					MemberID: id,
+				})
+			}
+			t.logger().Info("Member promoted", "member_id", strings.TrimSpace(MemberID))
 			return nil
 		}
 
 		lastErr = err
 		if strings.Contains(err.Error(), "can only promote a learner member which is in sync with leader") {
-			log.Printf("Learner not in sync yet, retrying (%d/%d)...\n", attempt+1, maxRetries)
+			t.logger().Info("Learner not in sync yet, retrying", "attempt", attempt+1, "max_attempts", maxRetries)
 		} else {
-			log.Printf("Promotion failed: %v, retrying (%d/%d)...\n", err, attempt+1, maxRetries)
+			t.logger().Warn("Promotion failed, retrying", "err", err, "attempt", attempt+1, "max_attempts", maxRetries)
+		}
+		if attempt == maxRetries-1 {
+			break
 		}
+		event.Emit(client, &event.Retry{
+			Description: fmt.Sprintf("promote member %s", strings.TrimSpace(MemberID)),
+			Attempt:     attempt + 1,
+			MaxAttempts: maxRetries,
+			Wait:        retryInterval,
+			Err:         err,
+		})
 		time.Sleep(retryInterval)
 	}
 
+	if strings.Contains(lastErr.Error(), "can only promote a learner member which is in sync with leader") {
+		lastErr = fmt.Errorf("%w: %w", ErrLearnerNotInSync, lastErr)
+	}
 	return fmt.Errorf("failed to promote member after %d attempts: %w", maxRetries, lastErr)
 }
 
-func (t *AddMemberTask) cleanupLocalDataOnLearner(client *ssh.Client, learner *config.Host, dataDir string) error {
+func (t *AddMemberTask) cleanupLocalDataOnLearner(client transport.Transport, learner *config.Host, dataDir string) error {
 	dataDir = strings.TrimSuffix(dataDir, "/")
 	if dataDir == "" {
 		dataDir = "/var/lib/etcd/member"
 	}
 
-	log.Printf("Checking if etcd data directory exists: %s\n", dataDir)
+	t.logger().Debug("Checking if etcd data directory exists", "path", dataDir)
 	if _, err := client.Run(fmt.Sprintf("sudo test -d %s", dataDir)); err != nil {
-		log.Printf("Directory %s does not exist, skipping cleanup\n", dataDir)
+		t.logger().Debug("Data directory does not exist, skipping cleanup", "path", dataDir)
 		return nil
 	}
 
-	_, decision, err := cliui.Select(
+	ok, err := cliui.Confirm(
+		"delete-data-dir."+learner.Name,
 		fmt.Sprintf("The data directory (%s) must be deleted before member %s can join. Continue?", dataDir, learner.Name),
-		[]string{"yes", "no"},
+		false,
 	)
 	if err != nil {
-		return fmt.Errorf("no selection made: %w", err)
+		return fmt.Errorf("no confirmation made: %w", err)
 	}
 
-	if decision != "yes" {
-		return fmt.Errorf("user did not confirm data cleanup")
+	if !ok {
+		return fmt.Errorf("%w: data cleanup not confirmed", cliui.ErrUserCancelled)
 	}
 
-	log.Printf("Removing %s\n", dataDir)
+	t.logger().Info("Removing etcd data directory", "path", dataDir)
 	if _, err = client.Run(fmt.Sprintf("sudo -i rm -rf %s", dataDir)); err != nil {
 		return fmt.Errorf("failed to remove directory: %w", err)
 	}
 
-	log.Printf("etcd local data cleaned successfully\n")
+	t.logger().Info("etcd data directory removed", "path", dataDir)
 	return nil
 }
 
-// execEtcdctl executes etcdctl command inside the container
-func (t *AddMemberTask) execEtcdctl(client *ssh.Client, containerID string, args ...string) (string, error) {
-	cmd := fmt.Sprintf("sudo crictl exec %s etcdctl --endpoints=https://127.0.0.1:2379 "+
-		"--cert /etc/kubernetes/pki/etcd/healthcheck-client.crt "+
-		"--key /etc/kubernetes/pki/etcd/healthcheck-client.key "+
-		"--cacert /etc/kubernetes/pki/etcd/ca.crt %s",
-		strings.TrimSpace(containerID), strings.Join(args, " "))
-
-	cmdTask := &CommandTask{
-		Description: "Execute etcdctl command",
-		Command:     cmd,
-		Check: &Check{
-			ExpectedExitCode: 0,
-			TimeoutSec:       30,
-			RetryIntervalSec: 5,
-		},
-	}
-	return cmdTask.Run(client)
-}
-
 type epStatus struct {
 	Ep   string                   `json:"Endpoint"`
 	Resp *clientv3.StatusResponse `json:"Status"`
@@ -502,7 +637,7 @@ type epStatus struct {
 func validateClusterStatus(output []byte) bool {
 	var memberStatusResponse []epStatus
 	if err := json.Unmarshal(output, &memberStatusResponse); err != nil {
-		log.Printf("Failed to unmarshal etcdctl status JSON: %v\n", err)
+		slog.Warn("Failed to parse etcdctl status", "err", err)
 		return false
 	}
 
@@ -518,42 +653,45 @@ func validateClusterStatus(output []byte) bool {
 	return true
 }
 
-func (t *AddMemberTask) updateManifest(learnerClient *ssh.Client, initialCluster, initialClusterState string) (string, error) {
+// updateManifest writes the backed up manifest of the learner, updated to
+// join the cluster, to a temporary file. It returns the path of the file
+// and the original manifest.
+func (t *AddMemberTask) updateManifest(learnerClient transport.Transport, initialCluster, initialClusterState string) (string, []byte, error) {
 	if t.Learner.BackedupManifest == "" {
-		return "", fmt.Errorf("backup manifest path not provided in hosts.json")
+		return "", nil, fmt.Errorf("%w: backup manifest path not provided in hosts.json", config.ErrInvalidConfig)
 	}
 
 	localEtcdPath := filepath.Join(os.TempDir(), "etcd-learner.yaml")
 	if err := learnerClient.Download(t.Learner.BackedupManifest, localEtcdPath); err != nil {
-		return "", fmt.Errorf("failed to download manifest: %w", err)
+		return "", nil, fmt.Errorf("failed to download manifest: %w", err)
 	}
 
 	manifestYaml, err := os.ReadFile(localEtcdPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read manifest: %w", err)
+		return "", nil, fmt.Errorf("failed to read manifest: %w", err)
 	}
 
 	var pod corev1.Pod
 	if err = yaml.Unmarshal(manifestYaml, &pod); err != nil {
-		return "", fmt.Errorf("failed to unmarshal manifest: %w", err)
+		return "", nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
 	}
 
 	pod, err = updateEtcdManifestForExistingCluster(&pod, initialCluster, initialClusterState)
 	if err != nil {
-		return "", fmt.Errorf("failed to update manifest: %w", err)
+		return "", nil, fmt.Errorf("failed to update manifest: %w", err)
 	}
 
 	updatedYaml, err := yaml.Marshal(&pod)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal manifest: %w", err)
 	}
 
 	tmpPath := filepath.Join(os.TempDir(), "etcd-updated.yaml")
 	if err = os.WriteFile(tmpPath, updatedYaml, 0o644); err != nil {
-		return "", fmt.Errorf("failed to write temp manifest: %w", err)
+		return "", nil, fmt.Errorf("failed to write temp manifest: %w", err)
 	}
 
-	return tmpPath, nil
+	return tmpPath, manifestYaml, nil
 }
 
 func updateEtcdManifestForExistingCluster(pod *corev1.Pod, initialCluster, initialClusterState string) (corev1.Pod, error) {
@@ -0,0 +1,248 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/etcd-recovery/pkg/cliui"
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/fakenode"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+const backupManifest = "/root/etcd.yaml"
+
+type fakeEnv struct {
+	cluster *fakenode.Cluster
+	nodes   []*fakenode.Node
+	hosts   []*config.Host
+}
+
+// newFakeEnv returns a cluster of stopped nodes named etcd-vm1..N, each
+// holding a backed up manifest.
+func newFakeEnv(count int) *fakeEnv {
+	env := &fakeEnv{cluster: fakenode.NewCluster()}
+	for i := 1; i <= count; i++ {
+		n := env.cluster.AddNode(fmt.Sprintf("etcd-vm%d", i), fmt.Sprintf("10.0.0.%d", i))
+		n.SetFile(backupManifest, n.Manifest())
+		env.nodes = append(env.nodes, n)
+		env.hosts = append(env.hosts, &config.Host{
+			Name:             n.Name,
+			MemberName:       n.Name,
+			Host:             n.IP,
+			BackedupManifest: backupManifest,
+		})
+	}
+	return env
+}
+
+func (e *fakeEnv) connect(h *config.Host) (transport.Transport, error) {
+	for _, n := range e.nodes {
+		if n.IP == h.Host {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown host %s", h.Host)
+}
+
+// seed turns the first node into a running single-member cluster.
+func (e *fakeEnv) seed() {
+	e.cluster.AddMember(e.nodes[0], false)
+	e.nodes[0].StartEtcd(e.nodes[0].Manifest())
+}
+
+func (e *fakeEnv) addMemberTask(learner int) *AddMemberTask {
+	return &AddMemberTask{
+		Master:   e.hosts[0],
+		Learner:  e.hosts[learner],
+		AllHosts: e.hosts,
+		Connect:  e.connect,
+	}
+}
+
+func TestAddMemberTask(t *testing.T) {
+	env := newFakeEnv(2)
+	env.seed()
+
+	out, err := env.addMemberTask(1).Run(env.nodes[0])
+	require.NoError(t, err)
+	assert.Equal(t, "learner added and promoted successfully", out)
+
+	members := env.cluster.Members()
+	require.Len(t, members, 2)
+	learner := env.cluster.Member(env.nodes[1].IP)
+	require.NotNil(t, learner)
+	assert.Equal(t, "etcd-vm2", learner.Name)
+	assert.False(t, learner.IsLearner)
+
+	manifest, ok := env.nodes[1].File(fakenode.ManifestPath)
+	require.True(t, ok)
+	assert.Contains(t, string(manifest), "--initial-cluster=etcd-vm1=https://10.0.0.1:2380,etcd-vm2=https://10.0.0.2:2380")
+	assert.Contains(t, string(manifest), "--initial-cluster-state=existing")
+	assert.NotEmpty(t, env.nodes[1].RunningContainer())
+}
+
+func TestAddMemberTaskEmitsEvents(t *testing.T) {
+	env := newFakeEnv(2)
+	env.seed()
+
+	var events []event.Event
+	client := event.NewTransport(env.nodes[0], env.nodes[0].IP, func(ev event.Event) {
+		events = append(events, ev)
+	})
+	_, err := env.addMemberTask(1).Run(client)
+	require.NoError(t, err)
+
+	var (
+		added       *event.MemberAdded
+		promoted    *event.LearnerPromoted
+		manifest    *event.ManifestChanged
+		learnerCmds int
+	)
+	for _, ev := range events {
+		switch ev := ev.(type) {
+		case *event.MemberAdded:
+			added = ev
+		case *event.LearnerPromoted:
+			promoted = ev
+		case *event.ManifestChanged:
+			manifest = ev
+		case *event.CommandRun:
+			if ev.Host == env.nodes[1].IP {
+				learnerCmds++
+			}
+		}
+	}
+
+	learner := env.cluster.Member(env.nodes[1].IP)
+	require.NotNil(t, added)
+	assert.Equal(t, learner.ID, added.MemberID)
+	assert.Equal(t, env.nodes[1].IP, added.Host)
+	assert.True(t, added.IsLearner)
+
+	require.NotNil(t, promoted)
+	assert.Equal(t, learner.ID, promoted.MemberID)
+
+	require.NotNil(t, manifest)
+	assert.Equal(t, env.nodes[1].IP, manifest.Host)
+	assert.Equal(t, env.nodes[1].Manifest(), manifest.Old)
+	assert.Contains(t, string(manifest.New), "--initial-cluster-state=existing")
+
+	assert.Positive(t, learnerCmds, "commands run on the learner should be observed")
+}
+
+// TestAddMemberTaskResumeUnstartedLearner covers a previous run that was
+// interrupted after the learner was added but before it was started.
+func TestAddMemberTaskResumeUnstartedLearner(t *testing.T) {
+	env := newFakeEnv(2)
+	env.seed()
+	env.cluster.AddUnstartedLearner(env.nodes[1].IP)
+
+	_, err := env.addMemberTask(1).Run(env.nodes[0])
+	require.NoError(t, err)
+
+	require.Len(t, env.cluster.Members(), 2)
+	learner := env.cluster.Member(env.nodes[1].IP)
+	require.NotNil(t, learner)
+	assert.False(t, learner.IsLearner)
+}
+
+func TestAddMemberTaskAlreadyPromoted(t *testing.T) {
+	env := newFakeEnv(2)
+	env.seed()
+	env.cluster.AddMember(env.nodes[1], false)
+
+	out, err := env.addMemberTask(1).Run(env.nodes[0])
+	require.NoError(t, err)
+	assert.Equal(t, "learner already promoted", out)
+	assert.Empty(t, env.nodes[1].Commands())
+}
+
+func TestAddMemberTaskRemovesUnknownLearner(t *testing.T) {
+	env := newFakeEnv(2)
+	env.seed()
+	env.cluster.AddUnstartedLearner("10.0.0.99")
+
+	_, err := env.addMemberTask(1).Run(env.nodes[0])
+	require.NoError(t, err)
+
+	assert.Nil(t, env.cluster.Member("10.0.0.99"))
+	assert.Len(t, env.cluster.Members(), 2)
+}
+
+func TestAddMemberTaskFailsOnOtherKnownLearner(t *testing.T) {
+	env := newFakeEnv(3)
+	env.seed()
+	env.cluster.AddUnstartedLearner(env.nodes[2].IP)
+
+	_, err := env.addMemberTask(1).Run(env.nodes[0])
+	require.ErrorContains(t, err, "has been added but not started yet")
+	assert.Nil(t, env.cluster.Member(env.nodes[1].IP))
+}
+
+func TestAddMemberTaskFailsWhenLearnerIsRunning(t *testing.T) {
+	env := newFakeEnv(2)
+	env.seed()
+	env.nodes[1].StartEtcd(env.nodes[1].Manifest())
+
+	_, err := env.addMemberTask(1).Run(env.nodes[0])
+	require.ErrorContains(t, err, "etcd is already running on 10.0.0.2")
+}
+
+func TestAddMemberTaskRemovesLearnerOnFailure(t *testing.T) {
+	env := newFakeEnv(3)
+	env.seed()
+	env.hosts[1].BackedupManifest = "/root/missing.yaml"
+
+	task := env.addMemberTask(1)
+	task.RemoveLearnerOnFailure = true
+	_, err := task.Run(env.nodes[0])
+	require.ErrorContains(t, err, "failed to download manifest")
+	assert.Nil(t, env.cluster.Member(env.nodes[1].IP))
+
+	// The next member can be added.
+	_, err = env.addMemberTask(2).Run(env.nodes[0])
+	require.NoError(t, err)
+	assert.Len(t, env.cluster.Members(), 2)
+}
+
+func TestAddMemberTaskStopsLearnerOnFailure(t *testing.T) {
+	env := newFakeEnv(3)
+	env.seed()
+	env.cluster.PromoteNotInSync = 1
+
+	task := env.addMemberTask(1)
+	task.RemoveLearnerOnFailure = true
+	task.PromoteAttempts = 1
+	_, err := task.Run(env.nodes[0])
+	require.ErrorIs(t, err, ErrLearnerNotInSync)
+	assert.Nil(t, env.cluster.Member(env.nodes[1].IP))
+	assert.Empty(t, env.nodes[1].RunningContainer())
+	_, ok := env.nodes[1].File(fakenode.ManifestPath)
+	assert.False(t, ok)
+
+	// The learner can be added again, once its data dir is deleted.
+	cliui.SetPrompter(cliui.NewScriptedPrompter(map[string]string{"delete-data-dir.etcd-vm2": "yes"}, nil))
+	defer cliui.SetPrompter(nil)
+	_, err = env.addMemberTask(1).Run(env.nodes[0])
+	require.NoError(t, err)
+	assert.False(t, env.cluster.Member(env.nodes[1].IP).IsLearner)
+}
+
+func TestAddMemberTaskFailsWithoutBackupManifest(t *testing.T) {
+	env := newFakeEnv(2)
+	env.seed()
+	env.hosts[1].BackedupManifest = "/root/missing.yaml"
+
+	_, err := env.addMemberTask(1).Run(env.nodes[0])
+	require.ErrorContains(t, err, "failed to download manifest")
+	assert.Empty(t, env.nodes[1].RunningContainer())
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// Check declares when the output of a CommandTask is accepted. All the
+// non-zero fields must be satisfied; the task is retried otherwise.
+type Check struct {
+	ExpectedExitCode  int
+	ExpectedOutput    string
+	NotExpectedOutput string
+	// ExpectedRegex must match the stdout of the command, without the
+	// surrounding whitespace.
+	ExpectedRegex string
+	// JSON assertions are evaluated against the stdout parsed as JSON.
+	JSON []JSONAssertion
+	// Numeric compares the stdout parsed as a number.
+	Numeric *Comparison
+	// AllowEmptyOutput accepts a command that prints nothing, by default
+	// empty output is retried.
+	AllowEmptyOutput bool
+	// StopOnStderr stops the retries when the stderr of a failed attempt
+	// contains it, ignoring case, e.g. an error which retrying won't fix.
+	StopOnStderr string
+
+	TimeoutSec       int
+	RetryIntervalSec int
+	// Retry overrides RetryIntervalSec, e.g. to back off exponentially
+	// or to limit the number of attempts.
+	Retry *RetryPolicy
+}
+
+// Comparison compares a value with Value using Op, one of ==, !=, <,
+// <=, > or >=. Values are compared as numbers if both parse as numbers,
+// otherwise as strings, in which case only == and != are supported.
+type Comparison struct {
+	Op    string
+	Value string
+}
+
+// JSONAssertion compares the value at Path in the JSON output. Path is
+// a dot separated list of object keys and array indexes, e.g.
+// "members.0.isLearner"; a trailing "#" yields the length of an array
+// or object, e.g. "members.#".
+type JSONAssertion struct {
+	Path string
+	Comparison
+}
+
+// stops reports whether the failed attempt res must not be retried.
+func (c *Check) stops(res *transport.Result) bool {
+	return c.StopOnStderr != "" && strings.Contains(strings.ToLower(string(res.Stderr)), strings.ToLower(c.StopOnStderr))
+}
+
+func (c *Check) timeout() time.Duration {
+	if c.TimeoutSec > 0 {
+		return time.Duration(c.TimeoutSec) * time.Second
+	}
+	return 10 * time.Second // sensible default timeout
+}
+
+func (c *Check) retryPolicy() *RetryPolicy {
+	if c.Retry != nil {
+		return c.Retry
+	}
+	if c.RetryIntervalSec > 0 {
+		return FixedInterval(time.Duration(c.RetryIntervalSec) * time.Second)
+	}
+	return FixedInterval(time.Second) // sensible default interval
+}
+
+// validate returns an error describing the first assertion the result
+// doesn't satisfy.
+func (c *Check) validate(res *transport.Result) error {
+	out := string(res.Stdout)
+
+	if res.ExitCode != c.ExpectedExitCode {
+		return fmt.Errorf("expected exit code %d but got %d%s", c.ExpectedExitCode, res.ExitCode, stderrSuffix(res))
+	}
+
+	if c.ExpectedOutput != "" && !strings.Contains(out, c.ExpectedOutput) {
+		return fmt.Errorf("expected output : %s not found%s", c.ExpectedOutput, stderrSuffix(res))
+	}
+	if c.NotExpectedOutput != "" && strings.Contains(out, c.NotExpectedOutput) {
+		return fmt.Errorf("not expected output : %s found", c.NotExpectedOutput)
+	}
+
+	if c.ExpectedRegex != "" {
+		re, err := regexp.Compile(c.ExpectedRegex)
+		if err != nil {
+			return fmt.Errorf("invalid regex %q: %w", c.ExpectedRegex, err)
+		}
+		if !re.MatchString(strings.TrimSpace(out)) {
+			return fmt.Errorf("output doesn't match regex %q", c.ExpectedRegex)
+		}
+	}
+
+	if c.Numeric != nil {
+		value := strings.TrimSpace(out)
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("output %q is not a number", value)
+		}
+		if err := c.Numeric.compare(value); err != nil {
+			return fmt.Errorf("output %w", err)
+		}
+	}
+
+	if len(c.JSON) > 0 {
+		var doc any
+		dec := json.NewDecoder(bytes.NewReader(res.Stdout))
+		// keep member IDs, which don't fit in a float64, intact
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return fmt.Errorf("failed to parse output as JSON: %w", err)
+		}
+		for _, a := range c.JSON {
+			value, err := lookupJSONPath(doc, a.Path)
+			if err != nil {
+				return err
+			}
+			if err := a.compare(value); err != nil {
+				return fmt.Errorf("%s %w", a.Path, err)
+			}
+		}
+	}
+
+	if out == "" && !c.AllowEmptyOutput {
+		return fmt.Errorf("empty output%s", stderrSuffix(res))
+	}
+
+	return nil
+}
+
+// compare returns an error if actual doesn't satisfy the comparison.
+func (c Comparison) compare(actual string) error {
+	a, aErr := strconv.ParseFloat(actual, 64)
+	b, bErr := strconv.ParseFloat(c.Value, 64)
+	numeric := aErr == nil && bErr == nil
+
+	var ok bool
+	switch c.Op {
+	case "==", "":
+		ok = actual == c.Value || (numeric && a == b)
+	case "!=":
+		ok = actual != c.Value && !(numeric && a == b)
+	case "<", "<=", ">", ">=":
+		if !numeric {
+			return fmt.Errorf("%q can't be compared with %s %q", actual, c.Op, c.Value)
+		}
+		switch c.Op {
+		case "<":
+			ok = a < b
+		case "<=":
+			ok = a <= b
+		case ">":
+			ok = a > b
+		default:
+			ok = a >= b
+		}
+	default:
+		return fmt.Errorf("unsupported comparison operator %q", c.Op)
+	}
+
+	if !ok {
+		return fmt.Errorf("%q is not %s %q", actual, c.Op, c.Value)
+	}
+	return nil
+}
+
+// lookupJSONPath resolves path in the decoded JSON document and returns
+// the value as a string, JSON encoded for objects and arrays.
+func lookupJSONPath(doc any, path string) (string, error) {
+	cur := doc
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			switch v := cur.(type) {
+			case map[string]any:
+				if key == "#" {
+					return strconv.Itoa(len(v)), nil
+				}
+				next, ok := v[key]
+				if !ok {
+					return "", fmt.Errorf("JSON path %q not found", path)
+				}
+				cur = next
+			case []any:
+				if key == "#" {
+					return strconv.Itoa(len(v)), nil
+				}
+				idx, err := strconv.Atoi(key)
+				if err != nil || idx < 0 || idx >= len(v) {
+					return "", fmt.Errorf("JSON path %q not found", path)
+				}
+				cur = v[idx]
+			default:
+				return "", fmt.Errorf("JSON path %q not found", path)
+			}
+		}
+	}
+
+	switch v := cur.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "null", nil
+	default:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+}
+
+// stderrSuffix formats the stderr of a command to be appended to an error
+// message.
+func stderrSuffix(res *transport.Result) string {
+	stderr := strings.TrimSpace(string(res.Stderr))
+	if stderr == "" {
+		return ""
+	}
+	return ", stderr: " + stderr
+}
@@ -5,101 +5,119 @@
 package task
 
 import (
-	"errors"
 	"fmt"
-	"log"
-	"strings"
 	"time"
 
-	cryptoSSH "golang.org/x/crypto/ssh"
-
-	"github.com/vmware/etcd-recovery/pkg/ssh"
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/transport"
 )
 
 type CommandTask struct {
 	Description string
 	Command     string
 	Check       *Check
+	// OnAttempt is called after every attempt to run the command. The
+	// attempts are logged if it isn't set.
+	OnAttempt func(Attempt)
 }
 
-type Check struct {
-	// Type              string
-	ExpectedExitCode  int
-	ExpectedOutput    string
-	NotExpectedOutput string
-	TimeoutSec        int
-	RetryIntervalSec  int
+// Attempt is the outcome of a single run of a CommandTask.
+type Attempt struct {
+	Command  string
+	Number   int
+	ExitCode int
+	Duration time.Duration
+	// Err is nil if the command passed the check.
+	Err error
+	// NextRetry is the wait before the next attempt, zero if the task
+	// won't be retried.
+	NextRetry time.Duration
 }
 
 func (t *CommandTask) Name() string {
 	return "CommandTask"
 }
 
-func (t *CommandTask) Run(client *ssh.Client) (string, error) {
+func (t *CommandTask) Run(client transport.Transport) (string, error) {
+	check := t.Check
+	if check == nil {
+		check = &Check{}
+	}
+
 	var (
-		start    = time.Now()
-		timeout  = 10 * time.Second // sensible default timeout
-		interval = time.Second      // sensible default interval
+		start   = time.Now()
+		timeout = check.timeout()
+		policy  = check.retryPolicy()
+		lasterr error
 	)
-	if t.Check != nil {
-		if t.Check.TimeoutSec > 0 {
-			timeout = time.Duration(t.Check.TimeoutSec) * time.Second
-		}
-		if t.Check.RetryIntervalSec > 0 {
-			interval = time.Duration(t.Check.RetryIntervalSec) * time.Second
-		}
-	}
 
-	var lasterr error
-
-	for time.Since(start) < timeout {
-		out, err := client.Run(t.Command)
-		exitCode := 0
-		if err != nil {
-			// Try to extract exit code from error if possible
-			var ee *cryptoSSH.ExitError
-			if !errors.As(err, &ee) {
-				// Not an ExitError, treat as command execution failure
-				log.Printf("command '%s' execution failed: %v\n", t.Command, err)
-				lasterr = err
-				time.Sleep(interval)
-				continue
+	for attempt := 1; ; attempt++ {
+		a := Attempt{Command: t.Command, Number: attempt}
+		stop := false
+
+		// An attempt can't outlive the deadline of the task.
+		res, err := client.Exec(t.Command, transport.ExecOptions{Timeout: timeout - time.Since(start)})
+		if err == nil {
+			a.ExitCode = res.ExitCode
+			a.Duration = res.Duration
+			if err = check.validate(res); err != nil {
+				stop = check.stops(res)
 			}
-			exitCode = ee.ExitStatus()
+		} else {
+			// The command couldn't be run to the end, e.g. the connection was lost
+			a.ExitCode = -1
+			err = fmt.Errorf("execution failed: %w", err)
 		}
 
-		// validation check
-		// check expected exit code
-		if t.Check != nil && exitCode != t.Check.ExpectedExitCode {
-			log.Printf("command '%s' validation failed: expected exit code : %d, got: %d\n", t.Command, t.Check.ExpectedExitCode, exitCode)
-			lasterr = fmt.Errorf("command '%s' validation failed: expected exit code %d but got %d", t.Command, t.Check.ExpectedExitCode, exitCode)
-			time.Sleep(interval)
-			continue
+		if err == nil {
+			t.report(client, policy, a)
+			return string(res.Stdout), nil
 		}
 
-		if t.Check != nil && t.Check.ExpectedOutput != "" && !strings.Contains(string(out), t.Check.ExpectedOutput) {
-			log.Printf("command '%s' validation failed: expected output : %s not found\n", t.Command, t.Check.ExpectedOutput)
-			lasterr = fmt.Errorf("command '%s' validation failed: expected output : %s not found", t.Command, t.Check.ExpectedOutput)
-			time.Sleep(interval)
-			continue
+		a.Err = fmt.Errorf("command '%s' validation failed: %w", t.Command, err)
+		lasterr = a.Err
+
+		if stop {
+			t.report(client, policy, a)
+			return "", fmt.Errorf("command '%s' failed, not retried, error: %w", t.Command, lasterr)
 		}
-		if t.Check != nil && t.Check.NotExpectedOutput != "" && strings.Contains(string(out), t.Check.NotExpectedOutput) {
-			log.Printf("command '%s' validation failed: not expected output : %s found\n", t.Command, t.Check.NotExpectedOutput)
-			lasterr = fmt.Errorf("command '%s' validation failed: not expected output : %s found", t.Command, t.Check.NotExpectedOutput)
-			time.Sleep(interval)
-			continue
+
+		if policy.exhausted(attempt) {
+			t.report(client, policy, a)
+			return "", fmt.Errorf("command '%s' failed after %d attempts, error: %w", t.Command, attempt, lasterr)
 		}
 
-		if err == nil && string(out) != "" {
-			return string(out), nil
+		a.NextRetry = policy.Interval(attempt)
+		if time.Since(start)+a.NextRetry >= timeout {
+			a.NextRetry = 0
+			t.report(client, policy, a)
+			break
 		}
+		t.report(client, policy, a)
+		time.Sleep(a.NextRetry)
 	}
 
-	if lasterr != nil {
-		return "", fmt.Errorf("command '%s' failed after timed out, error: %w", t.Command, lasterr)
+	return "", fmt.Errorf("command '%s' failed after timed out, error: %w", t.Command, lasterr)
+}
+
+func (t *CommandTask) report(client transport.Transport, policy *RetryPolicy, a Attempt) {
+	if a.Err != nil && a.NextRetry > 0 {
+		event.Emit(client, &event.Retry{
+			Description: t.Command,
+			Attempt:     a.Number,
+			MaxAttempts: policy.MaxAttempts,
+			Wait:        a.NextRetry,
+			Err:         a.Err,
+		})
 	}
 
-	return "", fmt.Errorf("command '%s' failed after timed out", t.Command)
+	if t.OnAttempt != nil {
+		t.OnAttempt(a)
+		return
+	}
+	if a.Err != nil {
+		logger(client).Debug("Attempt failed", "command", t.Command, "attempt", a.Number, "err", a.Err)
+	}
 }
 
 // Example usage:
@@ -128,6 +146,18 @@ func (t *CommandTask) Run(client *ssh.Client) (string, error) {
 //     Command:     "cp /etc/kubernetes/manifests/etcd.yaml /tmp/etcd.yaml",
 // }
 //
+// memberCountTask := &task.CommandTask{
+//     Description: "Wait for three voting members",
+//     Command:     "etcdctl member list -w json",
+//     Check: &task.Check{
+//         JSON: []task.JSONAssertion{
+//             {Path: "members.#", Comparison: task.Comparison{Op: "==", Value: "3"}},
+//         },
+//         TimeoutSec: 300,
+//         Retry:      task.ExponentialBackoff(time.Second, 30*time.Second, 0),
+//     },
+// }
+//
 // restartTask := &task.CommandTask{
 //     Description: "Restart etcd",
 //     Command:     "systemctl restart etcd",
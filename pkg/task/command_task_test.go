@@ -0,0 +1,309 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// fakeTransport returns canned responses for commands, in order, and
+// records every command that was run.
+type fakeTransport struct {
+	responses []fakeResponse
+	commands  []string
+	timeouts  []time.Duration
+}
+
+type fakeResponse struct {
+	out    string
+	stderr string
+	code   int
+	err    error
+}
+
+func (f *fakeTransport) Exec(cmd string, opts transport.ExecOptions) (*transport.Result, error) {
+	f.commands = append(f.commands, cmd)
+	f.timeouts = append(f.timeouts, opts.Timeout)
+	if len(f.responses) == 0 {
+		return nil, errors.New("no response configured")
+	}
+	resp := f.responses[0]
+	if len(f.responses) > 1 {
+		f.responses = f.responses[1:]
+	}
+	if resp.err != nil {
+		return nil, resp.err
+	}
+	return &transport.Result{Stdout: []byte(resp.out), Stderr: []byte(resp.stderr), ExitCode: resp.code}, nil
+}
+
+func (f *fakeTransport) Run(cmd string) ([]byte, error) {
+	res, err := f.Exec(cmd, transport.ExecOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return append(res.Stdout, res.Stderr...), res.Err()
+}
+
+func (f *fakeTransport) Upload(_ string, _ string) error    { return nil }
+func (f *fakeTransport) Download(_ string, _ string) error  { return nil }
+func (f *fakeTransport) Stat(_ string) (os.FileInfo, error) { return nil, os.ErrNotExist }
+func (f *fakeTransport) Close() error                       { return nil }
+
+func TestCommandTaskRetriesUntilExpectedOutput(t *testing.T) {
+	client := &fakeTransport{
+		responses: []fakeResponse{
+			{err: errors.New("connection reset")},
+			{out: "starting"},
+			{out: "127.0.0.1:2379 is healthy"},
+		},
+	}
+
+	task := &CommandTask{
+		Command: "etcdctl endpoint health",
+		Check: &Check{
+			ExpectedOutput:   "is healthy",
+			TimeoutSec:       5,
+			RetryIntervalSec: 0,
+		},
+	}
+
+	out, err := task.Run(client)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:2379 is healthy", out)
+	assert.Len(t, client.commands, 3)
+}
+
+func TestWaitForEtcdRunningTaskIgnoresOldContainer(t *testing.T) {
+	client := &fakeTransport{
+		responses: []fakeResponse{
+			{out: "old\n"},
+			{out: "new\n"},
+		},
+	}
+
+	task := &WaitForEtcdRunningTask{
+		OldContainerID:   "old",
+		TimeoutSec:       5,
+		RetryIntervalSec: 1,
+	}
+
+	containerID, err := task.Run(client)
+	require.NoError(t, err)
+	assert.Equal(t, "new", containerID)
+}
+
+func TestCommandTaskParsesStdoutOnly(t *testing.T) {
+	client := &fakeTransport{
+		responses: []fakeResponse{
+			{out: `{"members":[]}`, stderr: "WARNING: deprecated flag"},
+		},
+	}
+
+	task := &CommandTask{
+		Command: "etcdctl member list -w json",
+		Check:   &Check{TimeoutSec: 5},
+	}
+
+	out, err := task.Run(client)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"members":[]}`, out)
+}
+
+func TestCommandTaskErrorIncludesStderr(t *testing.T) {
+	client := &fakeTransport{
+		responses: []fakeResponse{
+			{stderr: "Error: etcdserver: Member not found", code: 1},
+		},
+	}
+
+	task := &CommandTask{
+		Command: "etcdctl member remove 1234",
+		Check:   &Check{TimeoutSec: 1, RetryIntervalSec: 1},
+	}
+
+	_, err := task.Run(client)
+	require.ErrorContains(t, err, "expected exit code 0 but got 1, stderr: Error: etcdserver: Member not found")
+}
+
+func TestCommandTaskAttemptTimeout(t *testing.T) {
+	client := &fakeTransport{
+		responses: []fakeResponse{
+			{code: 1},
+			{out: "ok"},
+		},
+	}
+
+	task := &CommandTask{
+		Command: "etcdctl endpoint health",
+		Check:   &Check{TimeoutSec: 3, RetryIntervalSec: 1},
+	}
+
+	_, err := task.Run(client)
+	require.NoError(t, err)
+	require.Len(t, client.timeouts, 2)
+	assert.LessOrEqual(t, client.timeouts[0], 3*time.Second)
+	assert.LessOrEqual(t, client.timeouts[1], 2*time.Second, "the second attempt gets what is left of the timeout")
+	assert.Positive(t, client.timeouts[1])
+}
+
+func TestCommandTaskStopOnStderr(t *testing.T) {
+	client := &fakeTransport{
+		responses: []fakeResponse{
+			{stderr: "Error: etcdserver: member not found", code: 1},
+		},
+	}
+
+	task := &CommandTask{
+		Command: "etcdctl member remove 1234",
+		Check:   &Check{TimeoutSec: 30, RetryIntervalSec: 5, StopOnStderr: "Member not found"},
+	}
+
+	_, err := task.Run(client)
+	require.ErrorContains(t, err, "not retried")
+	assert.Len(t, client.commands, 1)
+}
+
+func TestCheckValidate(t *testing.T) {
+	memberList := `{"header":{"member_id":12345678901234567890},"members":[{"ID":1,"isLearner":true},{"ID":2}]}`
+
+	tests := []struct {
+		name    string
+		check   Check
+		res     transport.Result
+		wantErr string
+	}{
+		{
+			name:    "empty output is retried by default",
+			check:   Check{},
+			wantErr: "empty output",
+		},
+		{
+			name:  "empty output is accepted when allowed",
+			check: Check{AllowEmptyOutput: true},
+		},
+		{
+			name:    "unexpected exit code",
+			check:   Check{ExpectedExitCode: 0},
+			res:     transport.Result{ExitCode: 1, Stderr: []byte("boom\n")},
+			wantErr: "expected exit code 0 but got 1, stderr: boom",
+		},
+		{
+			name:  "regex matches",
+			check: Check{ExpectedRegex: `^[0-9a-f]{12}$`},
+			res:   transport.Result{Stdout: []byte("0123456789ab\n")},
+		},
+		{
+			name:    "regex doesn't match",
+			check:   Check{ExpectedRegex: `^[0-9a-f]{12}$`},
+			res:     transport.Result{Stdout: []byte("not a container")},
+			wantErr: "doesn't match regex",
+		},
+		{
+			name:  "numeric comparison",
+			check: Check{Numeric: &Comparison{Op: ">=", Value: "100"}},
+			res:   transport.Result{Stdout: []byte("120\n")},
+		},
+		{
+			name:    "numeric comparison fails",
+			check:   Check{Numeric: &Comparison{Op: ">", Value: "100"}},
+			res:     transport.Result{Stdout: []byte("99\n")},
+			wantErr: `"99" is not > "100"`,
+		},
+		{
+			name:    "output is not a number",
+			check:   Check{Numeric: &Comparison{Op: ">", Value: "100"}},
+			res:     transport.Result{Stdout: []byte("Error: no such file")},
+			wantErr: "is not a number",
+		},
+		{
+			name: "JSON assertions",
+			check: Check{JSON: []JSONAssertion{
+				{Path: "members.#", Comparison: Comparison{Op: "==", Value: "2"}},
+				{Path: "members.0.isLearner", Comparison: Comparison{Op: "==", Value: "true"}},
+				{Path: "header.member_id", Comparison: Comparison{Op: "==", Value: "12345678901234567890"}},
+			}},
+			res: transport.Result{Stdout: []byte(memberList)},
+		},
+		{
+			name: "JSON assertion fails",
+			check: Check{JSON: []JSONAssertion{
+				{Path: "members.#", Comparison: Comparison{Op: ">=", Value: "3"}},
+			}},
+			res:     transport.Result{Stdout: []byte(memberList)},
+			wantErr: `members.# "2" is not >= "3"`,
+		},
+		{
+			name: "JSON path not found",
+			check: Check{JSON: []JSONAssertion{
+				{Path: "members.5.ID", Comparison: Comparison{Op: "==", Value: "1"}},
+			}},
+			res:     transport.Result{Stdout: []byte(memberList)},
+			wantErr: `JSON path "members.5.ID" not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check.validate(&tt.res)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommandTaskStopsAfterMaxAttempts(t *testing.T) {
+	client := &fakeTransport{
+		responses: []fakeResponse{{stderr: "not ready", code: 1}},
+	}
+
+	var attempts []Attempt
+	task := &CommandTask{
+		Command: "etcdctl member promote 1234",
+		Check: &Check{
+			TimeoutSec: 10,
+			Retry:      &RetryPolicy{MaxAttempts: 3, InitialInterval: time.Millisecond},
+		},
+		OnAttempt: func(a Attempt) { attempts = append(attempts, a) },
+	}
+
+	_, err := task.Run(client)
+	require.ErrorContains(t, err, "failed after 3 attempts")
+	require.Len(t, attempts, 3)
+	for i, a := range attempts {
+		assert.Equal(t, i+1, a.Number)
+		assert.Equal(t, 1, a.ExitCode)
+		require.ErrorContains(t, a.Err, "stderr: not ready")
+	}
+	assert.Zero(t, attempts[2].NextRetry)
+}
+
+func TestRetryPolicyInterval(t *testing.T) {
+	p := &RetryPolicy{InitialInterval: time.Second, Multiplier: 2, MaxInterval: 5 * time.Second}
+	assert.Equal(t, time.Second, p.Interval(1))
+	assert.Equal(t, 2*time.Second, p.Interval(2))
+	assert.Equal(t, 4*time.Second, p.Interval(3))
+	assert.Equal(t, 5*time.Second, p.Interval(4))
+
+	assert.Equal(t, 3*time.Second, FixedInterval(3*time.Second).Interval(10))
+
+	jittered := ExponentialBackoff(time.Second, time.Minute, 0)
+	for i := 0; i < 100; i++ {
+		d := jittered.Interval(2)
+		assert.GreaterOrEqual(t, d, 1600*time.Millisecond)
+		assert.LessOrEqual(t, d, 2400*time.Millisecond)
+	}
+}
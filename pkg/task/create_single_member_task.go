@@ -6,8 +6,8 @@ package task
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"slices"
@@ -19,19 +19,57 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/yaml"
 
-	"github.com/vmware/etcd-recovery/pkg/ssh"
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/transport"
 )
 
 type CreateSingleMemberClusterTask struct {
 	Description    string
 	BackupManifest string
+	// StartTimeoutSec is how long etcd is waited for to start and become
+	// healthy with a new manifest. It defaults to 600.
+	StartTimeoutSec int
+	// RestoreOnFailure puts back the manifest the host had before the
+	// task, or removes it if there was none, when the task fails. The
+	// host is then left as it was found, e.g. to try another seed.
+	RestoreOnFailure bool
 }
 
 func (t *CreateSingleMemberClusterTask) Name() string {
 	return "CreateSingleMemberCluster"
 }
 
-func (t *CreateSingleMemberClusterTask) Run(client *ssh.Client) (string, error) {
+func (t *CreateSingleMemberClusterTask) Run(client transport.Transport) (string, error) {
+	if !t.RestoreOnFailure {
+		return t.run(client)
+	}
+
+	original, err := ReadManifest(client)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the manifest to restore on failure: %w", err)
+	}
+
+	memberID, err := t.run(client)
+	if err != nil {
+		if rErr := restoreManifest(client, original); rErr != nil {
+			return memberID, errors.Join(err, fmt.Errorf("%w: %w", ErrManifestNotRestored, rErr))
+		}
+		logger(client).Warn("Restored the original manifest after the failure to create the single-member cluster")
+		event.Emit(client, &event.Warning{
+			Message: "Restored the original manifest after the failure to create the single-member cluster",
+		})
+	}
+	return memberID, err
+}
+
+func (t *CreateSingleMemberClusterTask) startTimeoutSec() int {
+	if t.StartTimeoutSec > 0 {
+		return t.StartTimeoutSec
+	}
+	return 600
+}
+
+func (t *CreateSingleMemberClusterTask) run(client transport.Transport) (string, error) {
 	var memberID string
 	var isSingleMember bool
 	// steps to create single-member etcd cluster
@@ -40,17 +78,19 @@ func (t *CreateSingleMemberClusterTask) Run(client *ssh.Client) (string, error)
 		Description:      "Get etcd container ID",
 		TimeoutSec:       15,
 		RetryIntervalSec: 5,
+		SkipLogs:         true,
 	}
 	oldContainerID, err := waitForEtcdRunningTask.Run(client)
 	if err != nil {
 		// if error, container is not running, proceed with creating single-member cluster
 		// skip scenario
-		log.Printf("etcd container didn't start: %v\n", err)
+		logger(client).Info("etcd container isn't running", "err", err)
 	}
 
 	// update timeout and retry interval for waiting etcd to restart
-	waitForEtcdRunningTask.TimeoutSec = 600
+	waitForEtcdRunningTask.TimeoutSec = t.startTimeoutSec()
 	waitForEtcdRunningTask.RetryIntervalSec = 5
+	waitForEtcdRunningTask.SkipLogs = false
 
 	// 1.1 `oldContainerID` not empty means that the etcd container is running.
 	if oldContainerID != "" {
@@ -58,13 +98,17 @@ func (t *CreateSingleMemberClusterTask) Run(client *ssh.Client) (string, error)
 		// if memberList contains more than one member, skip with warning
 		memberID, isSingleMember = isSingleMemberCluster(client, oldContainerID)
 		if !isSingleMember {
-			log.Println("WARNING: the etcd instance is part of a multi-member cluster; aborting single-member cluster creation")
+			logger(client).Warn("The etcd instance is part of a multi-member cluster; aborting single-member cluster creation", "member_id", memberID)
+			event.Emit(client, &event.Warning{
+				Message: "The etcd instance is part of a multi-member cluster; the single-member cluster wasn't created",
+			})
 			return memberID, nil
 		}
+		event.Emit(client, &event.SeedStarted{})
 
 		localEtcdPath := filepath.Join(os.TempDir(), filepath.Base(t.BackupManifest))
 		// Download manifest from `/etc/kubernetes/manifests/etcd.yaml` to local temp path
-		err = client.Download("/etc/kubernetes/manifests/etcd.yaml", localEtcdPath)
+		err = client.Download(EtcdManifestPath, localEtcdPath)
 		if err != nil {
 			return memberID, err
 		}
@@ -99,10 +143,11 @@ func (t *CreateSingleMemberClusterTask) Run(client *ssh.Client) (string, error)
 			}
 
 			// Upload manifest without --force-new-cluster to `/etc/kubernetes/manifests/etcd.yaml`
-			err = client.Upload(tmpNoForce, "/etc/kubernetes/manifests/etcd.yaml")
+			err = client.Upload(tmpNoForce, EtcdManifestPath)
 			if err != nil {
 				return memberID, err
 			}
+			emitManifestChanged(client, tmpNoForce, "remove --force-new-cluster", backupEtcdYaml)
 
 			// update old container ID
 			waitForEtcdRunningTask.OldContainerID = oldContainerID
@@ -114,30 +159,30 @@ func (t *CreateSingleMemberClusterTask) Run(client *ssh.Client) (string, error)
 			}
 
 			// final health check
-			if err = waitForEtcdHealthyCommandTask(client, newContainerID); err != nil {
+			if err = waitForEtcdHealthyCommandTask(client, newContainerID, t.startTimeoutSec()); err != nil {
 				return memberID, fmt.Errorf("etcd health check failed: %w", err)
 			}
 
 			//  Ensure it's a single member cluster
 			memberID, isSingleMember = isSingleMemberCluster(client, newContainerID)
 			if !isSingleMember {
-				return memberID, fmt.Errorf("failed to create a single-member cluster")
+				return memberID, fmt.Errorf("failed to create a single-member cluster: %w", ErrMultiMemberCluster)
 			}
 		} else {
 			// final health check
-			if err = waitForEtcdHealthyCommandTask(client, oldContainerID); err != nil {
+			if err = waitForEtcdHealthyCommandTask(client, oldContainerID, t.startTimeoutSec()); err != nil {
 				return memberID, fmt.Errorf("final etcd health check failed: %w", err)
 			}
 
 			//  Ensure it's a single member cluster
 			memberID, isSingleMember = isSingleMemberCluster(client, oldContainerID)
 			if !isSingleMember {
-				return memberID, fmt.Errorf("failed to create a single-member cluster")
+				return memberID, fmt.Errorf("failed to create a single-member cluster: %w", ErrMultiMemberCluster)
 			}
 		}
 	} else {
 		// etcd container is not running, proceed with creating single member cluster
-		log.Println("etcd container is not running, proceeding with single-member cluster creation")
+		logger(client).Info("etcd container is not running, proceeding with single-member cluster creation")
 
 		// Download backup manifest to local temp path
 		localEtcdPath := filepath.Join(os.TempDir(), filepath.Base(t.BackupManifest))
@@ -171,9 +216,11 @@ func (t *CreateSingleMemberClusterTask) Run(client *ssh.Client) (string, error)
 		}
 
 		// Upload manifest with --force-new-cluster
-		if err = client.Upload(tmpWithForce, "/etc/kubernetes/manifests/etcd.yaml"); err != nil {
+		if err = client.Upload(tmpWithForce, EtcdManifestPath); err != nil {
 			return memberID, fmt.Errorf("failed to upload manifest: %w", err)
 		}
+		emitManifestChanged(client, tmpWithForce, "add --force-new-cluster", backupEtcdYaml)
+		event.Emit(client, &event.SeedStarted{})
 		// Wait for etcd to start (container ID becomes available)
 		containerID, err := waitForEtcdRunningTask.Run(client)
 		if err != nil {
@@ -181,7 +228,7 @@ func (t *CreateSingleMemberClusterTask) Run(client *ssh.Client) (string, error)
 		}
 
 		// Wait for etcd to become healthy
-		if err = waitForEtcdHealthyCommandTask(client, containerID); err != nil {
+		if err = waitForEtcdHealthyCommandTask(client, containerID, t.startTimeoutSec()); err != nil {
 			return memberID, fmt.Errorf("etcd did not become healthy: %w", err)
 		}
 
@@ -201,9 +248,10 @@ func (t *CreateSingleMemberClusterTask) Run(client *ssh.Client) (string, error)
 		}
 
 		// Upload manifest without --force-new-cluster
-		if err = client.Upload(tmpNoForce, "/etc/kubernetes/manifests/etcd.yaml"); err != nil {
+		if err = client.Upload(tmpNoForce, EtcdManifestPath); err != nil {
 			return memberID, fmt.Errorf("failed to upload manifest: %w", err)
 		}
+		emitManifestChanged(client, tmpNoForce, "remove --force-new-cluster", etcdYamlWithForce)
 
 		// update old container ID
 		waitForEtcdRunningTask.OldContainerID = containerID
@@ -215,26 +263,25 @@ func (t *CreateSingleMemberClusterTask) Run(client *ssh.Client) (string, error)
 		}
 
 		// Final health check
-		if err := waitForEtcdHealthyCommandTask(client, newContainerID); err != nil {
+		if err := waitForEtcdHealthyCommandTask(client, newContainerID, t.startTimeoutSec()); err != nil {
 			return memberID, fmt.Errorf("final etcd health check failed: %w", err)
 		}
 
 		// Ensure it's a single member cluster
 		memberID, isSingleMember = isSingleMemberCluster(client, newContainerID)
 		if !isSingleMember {
-			return memberID, fmt.Errorf("failed to create a single-member cluster")
+			return memberID, fmt.Errorf("failed to create a single-member cluster: %w", ErrMultiMemberCluster)
 		}
 	}
 
 	return memberID, nil
 }
 
-func isSingleMemberCluster(client *ssh.Client, containerID string) (string, bool) {
+func isSingleMemberCluster(client transport.Transport, containerID string) (string, bool) {
 	// prepare command task to check if single member cluster
-	// use crictl exec to run etcdctl member list inside the etcd container
 	singleMemberTask := &CommandTask{
 		Description: "check if single-member cluster",
-		Command:     fmt.Sprintf("sudo crictl exec %s etcdctl --endpoints=https://127.0.0.1:2379 --cert /etc/kubernetes/pki/etcd/healthcheck-client.crt --key /etc/kubernetes/pki/etcd/healthcheck-client.key --cacert /etc/kubernetes/pki/etcd/ca.crt member list -w json", strings.TrimSpace(containerID)),
+		Command:     EtcdctlCommand(containerID, "member", "list", "-w", "json"),
 		Check: &Check{
 			ExpectedExitCode: 0,
 			TimeoutSec:       60,
@@ -258,19 +305,21 @@ func isSingleMemberCluster(client *ssh.Client, containerID string) (string, bool
 	return strconv.FormatUint(memberListResponse.Header.MemberId, 10), false
 }
 
-func waitForEtcdHealthyCommandTask(client *ssh.Client, containerID string) error {
+func waitForEtcdHealthyCommandTask(client transport.Transport, containerID string, timeoutSec int) error {
 	waitForEtcdToBeHealthyCommandTask := CommandTask{
 		Description: "Wait for etcd to be healthy",
-		Command:     fmt.Sprintf("sudo crictl exec %s etcdctl --endpoints=127.0.0.1:2379 --cert /etc/kubernetes/pki/etcd/healthcheck-client.crt --key /etc/kubernetes/pki/etcd/healthcheck-client.key --cacert /etc/kubernetes/pki/etcd/ca.crt endpoint health --cluster", strings.TrimSpace(containerID)),
+		Command:     EtcdctlCommand(containerID, "endpoint", "health", "--cluster"),
 		Check: &Check{
 			ExpectedExitCode: 0,
 			ExpectedOutput:   "is healthy",
-			TimeoutSec:       600,
+			TimeoutSec:       timeoutSec,
 			RetryIntervalSec: 10,
 		},
 	}
-	_, err := waitForEtcdToBeHealthyCommandTask.Run(client)
-	return err
+	if _, err := waitForEtcdToBeHealthyCommandTask.Run(client); err != nil {
+		return withContainerLogs(client, err)
+	}
+	return nil
 }
 
 func updateForceNewClusterCommand(pod corev1.Pod, containerName string, add bool) (updatePod corev1.Pod, changed bool, err error) {
@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/fakenode"
+)
+
+func TestCreateSingleMemberClusterTaskFromStoppedMember(t *testing.T) {
+	env := newFakeEnv(3)
+	for _, n := range env.nodes {
+		env.cluster.AddMember(n, false)
+		n.SetDataDir(true)
+	}
+	seed := env.nodes[0]
+
+	var seeds int
+	client := event.NewTransport(seed, seed.IP, func(ev event.Event) {
+		if _, ok := ev.(*event.SeedStarted); ok {
+			seeds++
+		}
+	})
+	task := &CreateSingleMemberClusterTask{BackupManifest: backupManifest}
+	memberID, err := task.Run(client)
+	require.NoError(t, err)
+	assert.Equal(t, 1, seeds)
+
+	members := env.cluster.Members()
+	require.Len(t, members, 1)
+	assert.Equal(t, strconv.FormatUint(members[0].ID, 10), memberID)
+
+	// once with --force-new-cluster, then restarted without it
+	assert.Len(t, seed.Containers(), 2)
+	manifest, ok := seed.File(fakenode.ManifestPath)
+	require.True(t, ok)
+	assert.NotContains(t, string(manifest), "--force-new-cluster")
+}
+
+// TestCreateSingleMemberClusterTaskResume covers a previous run that was
+// interrupted while etcd was still running with --force-new-cluster.
+func TestCreateSingleMemberClusterTaskResume(t *testing.T) {
+	env := newFakeEnv(3)
+	seed := env.nodes[0]
+	env.cluster.AddMember(seed, false)
+	oldContainer := seed.StartEtcd(seed.Manifest("--force-new-cluster"))
+
+	task := &CreateSingleMemberClusterTask{BackupManifest: backupManifest}
+	_, err := task.Run(seed)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, oldContainer, seed.RunningContainer())
+	manifest, ok := seed.File(fakenode.ManifestPath)
+	require.True(t, ok)
+	assert.NotContains(t, string(manifest), "--force-new-cluster")
+}
+
+func TestCreateSingleMemberClusterTaskSkipsMultiMemberCluster(t *testing.T) {
+	env := newFakeEnv(3)
+	env.seed()
+	env.cluster.AddMember(env.nodes[1], false)
+	container := env.nodes[0].RunningContainer()
+
+	task := &CreateSingleMemberClusterTask{BackupManifest: backupManifest}
+	_, err := task.Run(env.nodes[0])
+	require.NoError(t, err)
+
+	assert.Len(t, env.cluster.Members(), 2)
+	assert.Equal(t, container, env.nodes[0].RunningContainer())
+}
+
+func TestCreateSingleMemberClusterTaskFailsWithoutBackupManifest(t *testing.T) {
+	env := newFakeEnv(1)
+
+	task := &CreateSingleMemberClusterTask{BackupManifest: "/root/missing.yaml"}
+	_, err := task.Run(env.nodes[0])
+	require.ErrorContains(t, err, "failed to download backup manifest")
+}
+
+func TestCreateSingleMemberClusterTaskRestoresManifestOnFailure(t *testing.T) {
+	env := newFakeEnv(2)
+	for _, n := range env.nodes {
+		env.cluster.AddMember(n, false)
+		n.SetDataDir(true)
+	}
+	seed := env.nodes[0]
+	seed.FailToStart = true
+	seed.StartupLogs = []string{`{"level":"fatal","msg":"member has already been bootstrapped"}`}
+
+	task := &CreateSingleMemberClusterTask{BackupManifest: backupManifest, StartTimeoutSec: 1, RestoreOnFailure: true}
+	_, err := task.Run(seed)
+	require.ErrorIs(t, err, ErrEtcdNotRunning)
+
+	// The logs of the exited container tell why it failed.
+	var logsErr *ContainerLogsError
+	require.ErrorAs(t, err, &logsErr)
+	require.Len(t, logsErr.Logs, 1)
+	assert.Equal(t, seed.Containers()[0].ID, logsErr.Logs[0].ContainerID)
+	assert.ErrorContains(t, err, "member has already been bootstrapped")
+
+	// The stopped member had no manifest, it is removed again.
+	_, ok := seed.File(fakenode.ManifestPath)
+	assert.False(t, ok)
+	assert.Len(t, env.cluster.Members(), 2)
+}
+
+func TestCreateSingleMemberClusterTaskRestoresExistingManifestOnFailure(t *testing.T) {
+	env := newFakeEnv(1)
+	seed := env.nodes[0]
+	env.cluster.AddMember(seed, false)
+	original := seed.Manifest("--force-new-cluster")
+	seed.StartEtcd(original)
+	seed.FailToStart = true
+
+	task := &CreateSingleMemberClusterTask{BackupManifest: backupManifest, StartTimeoutSec: 1, RestoreOnFailure: true}
+	_, err := task.Run(seed)
+	require.Error(t, err)
+
+	manifest, ok := seed.File(fakenode.ManifestPath)
+	require.True(t, ok)
+	assert.Equal(t, string(original), string(manifest))
+}
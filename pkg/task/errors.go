@@ -0,0 +1,34 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import "errors"
+
+var (
+	// ErrEtcdNotRunning is returned when the etcd container isn't running,
+	// or doesn't start in time.
+	ErrEtcdNotRunning = errors.New("etcd not running")
+
+	// ErrMultiMemberCluster is returned when the cluster still has more
+	// than one member after creating a single-member cluster.
+	ErrMultiMemberCluster = errors.New("cluster has more than one member")
+
+	// ErrLearnerNotInSync is returned when a learner doesn't catch up with
+	// the leader in time to be promoted.
+	ErrLearnerNotInSync = errors.New("learner not in sync with the leader")
+
+	// ErrQuorumIntact is returned when a quorum of voters is still
+	// reachable and agrees on a leader, so forcing a new cluster would
+	// throw away the membership of a healthy cluster.
+	ErrQuorumIntact = errors.New("quorum intact")
+
+	// ErrQuorumLost is returned when a member can't be replaced because
+	// no quorum of voters is reachable and agrees on a leader.
+	ErrQuorumLost = errors.New("quorum lost")
+
+	// ErrManifestNotRestored is returned when the manifest of a host can't
+	// be put back after a failure, leaving the host in an unknown state.
+	ErrManifestNotRestored = errors.New("manifest not restored")
+)
@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// EtcdContainerID returns the ID of the etcd container running on the
+// host client is connected to. Unlike WaitForEtcdRunningTask, it doesn't
+// wait for the container to start.
+func EtcdContainerID(client transport.Transport) (string, error) {
+	return etcdContainerID(client, transport.ExecOptions{})
+}
+
+func etcdContainerID(client transport.Transport, opts transport.ExecOptions) (string, error) {
+	res, err := client.Exec("sudo crictl ps --label io.kubernetes.container.name=etcd -q | head -n 1", opts)
+	if err == nil {
+		err = res.Err()
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to list etcd containers: %w", err)
+	}
+
+	containerID := strings.TrimSpace(string(res.Stdout))
+	if containerID == "" {
+		return "", fmt.Errorf("%w: container not found", ErrEtcdNotRunning)
+	}
+	return containerID, nil
+}
+
+// EtcdctlCommand returns the command running etcdctl with args inside the
+// etcd container, against the local member.
+func EtcdctlCommand(containerID string, args ...string) string {
+	return fmt.Sprintf("sudo crictl exec %s etcdctl --endpoints=https://127.0.0.1:2379 "+
+		"--cert /etc/kubernetes/pki/etcd/healthcheck-client.crt "+
+		"--key /etc/kubernetes/pki/etcd/healthcheck-client.key "+
+		"--cacert /etc/kubernetes/pki/etcd/ca.crt %s",
+		strings.TrimSpace(containerID), strings.Join(args, " "))
+}
+
+// Etcdctl runs etcdctl with args inside the etcd container, against the
+// local member. The command is retried for up to 30 seconds.
+func Etcdctl(client transport.Transport, containerID string, args ...string) (string, error) {
+	cmdTask := &CommandTask{
+		Description: "Execute etcdctl command",
+		Command:     EtcdctlCommand(containerID, args...),
+		Check: &Check{
+			ExpectedExitCode: 0,
+			TimeoutSec:       30,
+			RetryIntervalSec: 5,
+		},
+	}
+	return cmdTask.Run(client)
+}
+
+// errMemberNotFound is the error of etcd removing a member which isn't in
+// the cluster.
+const errMemberNotFound = "member not found"
+
+// removeMember removes the member memberID through the etcd container
+// containerID. It reports false if the member was already gone, which
+// isn't retried.
+func removeMember(client transport.Transport, containerID string, memberID string) (bool, error) {
+	cmdTask := &CommandTask{
+		Description: "Remove member",
+		Command:     EtcdctlCommand(containerID, "member", "remove", memberID),
+		Check: &Check{
+			ExpectedExitCode: 0,
+			StopOnStderr:     errMemberNotFound,
+			TimeoutSec:       30,
+			RetryIntervalSec: 5,
+		},
+	}
+	if _, err := cmdTask.Run(client); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), errMemberNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// ListMembers returns the members of the cluster, as seen by the etcd
+// container containerID.
+func ListMembers(client transport.Transport, containerID string) ([]*etcdserverpb.Member, error) {
+	out, err := Etcdctl(client, containerID, "member", "list", "-w", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	return ParseMembers([]byte(out))
+}
+
+// ParseMembers parses the output of `etcdctl member list -w json`.
+func ParseMembers(out []byte) ([]*etcdserverpb.Member, error) {
+	var resp etcdserverpb.MemberListResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse member list: %w", err)
+	}
+	return resp.Members, nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+const (
+	// failureLogLines is the number of lines of logs captured from every
+	// etcd container when etcd fails to start or to become healthy.
+	failureLogLines = 30
+	// failureLogContainers is the number of the newest etcd containers
+	// whose logs are captured, as the newest one may have just been
+	// restarted and logged nothing yet.
+	failureLogContainers = 2
+)
+
+// EtcdContainerIDs returns the IDs of the etcd containers on the host
+// client is connected to, including the exited ones, from the newest to
+// the oldest.
+func EtcdContainerIDs(client transport.Transport) ([]string, error) {
+	res, err := client.Exec("sudo crictl ps -a --label io.kubernetes.container.name=etcd -q", transport.ExecOptions{})
+	if err == nil {
+		err = res.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list etcd containers: %w", err)
+	}
+	return strings.Fields(string(res.Stdout)), nil
+}
+
+// ContainerLogsCommand returns the command printing the logs of the
+// container, each line prefixed with its RFC 3339 timestamp. The logs
+// are limited to the last since if it isn't 0, and followed if follow is
+// set. etcd logs to stderr, so both streams are printed to stdout.
+func ContainerLogsCommand(containerID string, since time.Duration, follow bool) string {
+	args := []string{"sudo crictl logs --timestamps"}
+	if since > 0 {
+		args = append(args, fmt.Sprintf("--since=%ds", int(since.Seconds())))
+	}
+	if follow {
+		args = append(args, "--follow")
+	}
+	args = append(args, strings.TrimSpace(containerID), "2>&1")
+	return strings.Join(args, " ")
+}
+
+// KubeletLogsCommand returns the command printing the kubelet journal,
+// each line prefixed with its ISO 8601 timestamp, with the same since
+// and follow as ContainerLogsCommand.
+func KubeletLogsCommand(since time.Duration, follow bool) string {
+	args := []string{"sudo journalctl -u kubelet --no-pager -o short-iso-precise"}
+	if since > 0 {
+		args = append(args, fmt.Sprintf("--since=-%ds", int(since.Seconds())))
+	}
+	if follow {
+		args = append(args, "--follow")
+	}
+	return strings.Join(args, " ")
+}
+
+// ContainerLogs returns the logs of the container over the last since,
+// or all of them if since is 0.
+func ContainerLogs(client transport.Transport, containerID string, since time.Duration) ([]byte, error) {
+	res, err := client.Exec(ContainerLogsCommand(containerID, since, false), transport.ExecOptions{})
+	if err == nil {
+		err = res.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the logs of container %s: %w", containerID, err)
+	}
+	return res.Stdout, nil
+}
+
+// ContainerLog is the tail of the logs of an etcd container.
+type ContainerLog struct {
+	ContainerID string
+	Lines       []string
+}
+
+// ContainerLogsError is returned when etcd fails to start or to become
+// healthy, with the last lines of the logs of the newest etcd
+// containers, which usually tell why, e.g. "member has already been
+// bootstrapped" or a TLS error.
+type ContainerLogsError struct {
+	Err  error
+	Logs []ContainerLog
+}
+
+func (e *ContainerLogsError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Err.Error())
+	for _, l := range e.Logs {
+		fmt.Fprintf(&sb, "\nlast %d lines of the logs of etcd container %s:", len(l.Lines), l.ContainerID)
+		for _, line := range l.Lines {
+			sb.WriteString("\n  " + line)
+		}
+	}
+	return sb.String()
+}
+
+func (e *ContainerLogsError) Unwrap() error {
+	return e.Err
+}
+
+// withContainerLogs attaches the last lines of the logs of the newest
+// etcd containers of the host, including the exited ones, to err, and
+// emits them. err is returned as is if there are no logs to attach.
+func withContainerLogs(client transport.Transport, err error) error {
+	ids, lErr := EtcdContainerIDs(client)
+	if lErr != nil {
+		logger(client).Debug("Failed to list the etcd containers to capture their logs", "err", lErr)
+		return err
+	}
+
+	var logs []ContainerLog
+	for _, id := range ids[:min(len(ids), failureLogContainers)] {
+		cmd := fmt.Sprintf("sudo crictl logs --tail=%d %s 2>&1", failureLogLines, id)
+		res, lErr := client.Exec(cmd, transport.ExecOptions{})
+		if lErr == nil {
+			lErr = res.Err()
+		}
+		if lErr != nil {
+			logger(client).Debug("Failed to capture the logs of the etcd container", "container_id", id, "err", lErr)
+			continue
+		}
+
+		out := strings.TrimRight(string(res.Stdout), "\n")
+		if out == "" {
+			continue
+		}
+		lines := strings.Split(out, "\n")
+		logs = append(logs, ContainerLog{ContainerID: id, Lines: lines})
+		event.Emit(client, &event.ContainerLogs{ContainerID: id, Reason: err.Error(), Lines: lines})
+	}
+
+	if len(logs) == 0 {
+		return err
+	}
+	return &ContainerLogsError{Err: err, Logs: logs}
+}
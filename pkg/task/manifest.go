@@ -0,0 +1,160 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// EtcdManifestPath is the etcd static pod manifest watched by kubelet.
+const EtcdManifestPath = "/etc/kubernetes/manifests/etcd.yaml"
+
+// ManifestForcesNewCluster reports whether the etcd manifest of the host
+// client is connected to passes --force-new-cluster, e.g. because a
+// repair was interrupted. It is false if the host has no manifest.
+func ManifestForcesNewCluster(client transport.Transport) (bool, error) {
+	manifest, err := ReadManifest(client)
+	if err != nil || manifest == nil {
+		return false, err
+	}
+
+	c, err := EtcdContainer(manifest)
+	if err != nil || c == nil {
+		return false, err
+	}
+	return slices.ContainsFunc(c.Command, forcesNewCluster), nil
+}
+
+// forcesNewCluster reports whether the etcd flag arg enables
+// --force-new-cluster, i.e. is the flag alone or with a true value, but
+// not e.g. --force-new-cluster=false.
+func forcesNewCluster(arg string) bool {
+	name, value, hasValue := strings.Cut(strings.TrimSpace(arg), "=")
+	if name != "--force-new-cluster" && name != "-force-new-cluster" {
+		return false
+	}
+	if !hasValue {
+		return true
+	}
+	force, err := strconv.ParseBool(value)
+	return err == nil && force
+}
+
+// EtcdContainer returns the etcd container of the static pod manifest,
+// or nil if it has none.
+func EtcdContainer(manifest []byte) (*corev1.Container, error) {
+	var pod corev1.Pod
+	if err := yaml.Unmarshal(manifest, &pod); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	for i, c := range pod.Spec.Containers {
+		if strings.TrimSpace(c.Name) == "etcd" {
+			return &pod.Spec.Containers[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ReadManifest returns the etcd manifest of the host, or nil if it has
+// none.
+func ReadManifest(client transport.Transport) ([]byte, error) {
+	return ReadFile(client, EtcdManifestPath)
+}
+
+// ReadFile returns the content of the file at path on the host, or nil
+// if there is no such file.
+func ReadFile(client transport.Transport, path string) ([]byte, error) {
+	if _, err := client.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	f, err := os.CreateTemp("", "etcd-recovery-*")
+	if err != nil {
+		return nil, err
+	}
+	localPath := f.Name()
+	f.Close()
+	defer os.Remove(localPath)
+
+	if err := client.Download(path, localPath); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(localPath)
+}
+
+// restoreManifest puts back the etcd manifest read by ReadManifest, or
+// removes the manifest if original is nil, which stops etcd.
+func restoreManifest(client transport.Transport, original []byte) error {
+	current, err := ReadManifest(client)
+	if err != nil {
+		return err
+	}
+
+	if original == nil {
+		if current == nil {
+			return nil
+		}
+		return removeManifest(client, "remove the manifest, there was none before", current)
+	}
+
+	localPath := filepath.Join(os.TempDir(), "etcd-original.yaml")
+	if err = os.WriteFile(localPath, original, 0o644); err != nil {
+		return fmt.Errorf("failed to write temp manifest: %w", err)
+	}
+	if err = client.Upload(localPath, EtcdManifestPath); err != nil {
+		return fmt.Errorf("failed to upload manifest: %w", err)
+	}
+	emitManifestChanged(client, localPath, "restore the original manifest", current)
+	return nil
+}
+
+// removeManifest removes the etcd manifest, whose content is current,
+// which makes kubelet stop etcd.
+func removeManifest(client transport.Transport, reason string, current []byte) error {
+	res, err := client.Exec(fmt.Sprintf("sudo rm -f %s", EtcdManifestPath), transport.ExecOptions{})
+	if err == nil {
+		err = res.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove manifest: %w", err)
+	}
+	event.Emit(client, &event.ManifestChanged{
+		Path:   EtcdManifestPath,
+		Reason: reason,
+		Old:    current,
+	})
+	return nil
+}
+
+// emitManifestChanged reports that the manifest at localPath has just been
+// uploaded to EtcdManifestPath.
+func emitManifestChanged(client transport.Transport, localPath, reason string, old []byte) {
+	newManifest, err := os.ReadFile(localPath)
+	if err != nil {
+		return
+	}
+	event.Emit(client, &event.ManifestChanged{
+		Path:   EtcdManifestPath,
+		Reason: reason,
+		Old:    old,
+		New:    newManifest,
+	})
+}
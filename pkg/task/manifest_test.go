@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestForcesNewCluster(t *testing.T) {
+	env := newFakeEnv(2)
+	env.nodes[0].StartEtcd(env.nodes[0].Manifest("--force-new-cluster"))
+	env.nodes[1].StartEtcd(env.nodes[1].Manifest())
+
+	force, err := ManifestForcesNewCluster(env.nodes[0])
+	require.NoError(t, err)
+	assert.True(t, force)
+
+	force, err = ManifestForcesNewCluster(env.nodes[1])
+	require.NoError(t, err)
+	assert.False(t, force)
+
+	force, err = ManifestForcesNewCluster(newFakeEnv(1).nodes[0])
+	require.NoError(t, err)
+	assert.False(t, force)
+
+	env.nodes[1].StartEtcd(env.nodes[1].Manifest("--force-new-cluster=false"))
+	force, err = ManifestForcesNewCluster(env.nodes[1])
+	require.NoError(t, err)
+	assert.False(t, force)
+}
+
+func TestForcesNewCluster(t *testing.T) {
+	for arg, want := range map[string]bool{
+		"--force-new-cluster":               true,
+		"--force-new-cluster=true":          true,
+		"-force-new-cluster=1":              true,
+		"--force-new-cluster=false":         false,
+		"--force-new-cluster=0":             false,
+		"--force-new-cluster=invalid":       false,
+		"--force-new-cluster-bump-amount=5": false,
+		"--name=etcd-vm1":                   false,
+	} {
+		assert.Equal(t, want, forcesNewCluster(arg), arg)
+	}
+}
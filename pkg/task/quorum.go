@@ -0,0 +1,207 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// probeTimeout bounds every command of a probe, beyond the command
+// timeout of etcdctl, so that a hung crictl or SSH session doesn't block
+// the probe, e.g. status --watch, forever.
+const probeTimeout = 15 * time.Second
+
+// EtcdProbe is the state of the etcd member running on a host.
+type EtcdProbe struct {
+	ContainerID string
+	MemberID    uint64
+	// Leader is the ID of the leader known to the member, 0 if it has
+	// none.
+	Leader    uint64
+	RaftTerm  uint64
+	RaftIndex uint64
+	IsLearner bool
+	DBSize    int64
+	// Alarms are the active alarms of the cluster, e.g. NOSPACE, as
+	// listed by `etcdctl alarm list`.
+	Alarms    []string
+	AlarmsErr error
+	// Members is the member list as seen by the member. It is nil if it
+	// couldn't be read, e.g. because the member has no quorum.
+	Members    []*etcdserverpb.Member
+	MembersErr error
+}
+
+// ProbeEtcd reads the status, the alarms and the member list of the etcd
+// member running on the host client is connected to. Unlike ListMembers,
+// it doesn't wait for etcd nor retry, as the cluster may have lost
+// quorum. If the status can't be read, the probe is returned with the
+// container ID only, along with the error.
+func ProbeEtcd(client transport.Transport) (*EtcdProbe, error) {
+	containerID, err := etcdContainerID(client, transport.ExecOptions{Timeout: probeTimeout})
+	if err != nil {
+		return nil, err
+	}
+	p := &EtcdProbe{ContainerID: containerID}
+
+	out, err := etcdctlOnce(client, containerID, "endpoint", "status", "-w", "json")
+	if err != nil {
+		return p, fmt.Errorf("failed to read endpoint status: %w", err)
+	}
+	var statuses []epStatus
+	if err = json.Unmarshal(out, &statuses); err != nil {
+		return p, fmt.Errorf("failed to parse endpoint status: %w", err)
+	}
+	if len(statuses) == 0 || statuses[0].Resp == nil || statuses[0].Resp.Header == nil {
+		return p, fmt.Errorf("empty endpoint status")
+	}
+
+	s := statuses[0].Resp
+	p.MemberID = s.Header.MemberId
+	p.Leader = s.Leader
+	p.RaftTerm = s.RaftTerm
+	p.RaftIndex = s.RaftIndex
+	p.IsLearner = s.IsLearner
+	p.DBSize = s.DbSize
+
+	if out, err = etcdctlOnce(client, containerID, "alarm", "list"); err == nil {
+		p.Alarms = ParseAlarms(out)
+	} else {
+		p.AlarmsErr = fmt.Errorf("failed to list alarms: %w", err)
+	}
+
+	if out, err = etcdctlOnce(client, containerID, "member", "list", "-w", "json"); err == nil {
+		p.Members, err = ParseMembers(out)
+	}
+	if err != nil {
+		p.MembersErr = fmt.Errorf("failed to list members: %w", err)
+	}
+	return p, nil
+}
+
+// HealthyMembers returns the IDs of the members answering the status
+// request of the etcd member running on the host client is connected to.
+// The members which don't answer are left out, rather than failing.
+func HealthyMembers(client transport.Transport, containerID string) (map[uint64]bool, error) {
+	res, err := client.Exec(EtcdctlCommand(containerID, "--command-timeout=5s", "endpoint", "status", "--cluster", "-w", "json"), transport.ExecOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read endpoint status: %w", err)
+	}
+
+	// etcdctl prints the status of the members which answered, and fails
+	// if any didn't.
+	var statuses []epStatus
+	if jErr := json.Unmarshal(res.Stdout, &statuses); jErr != nil {
+		if err = res.Err(); err == nil {
+			err = jErr
+		}
+		return nil, fmt.Errorf("failed to read endpoint status: %w", err)
+	}
+
+	healthy := map[uint64]bool{}
+	for _, s := range statuses {
+		if s.Resp != nil && s.Resp.Header != nil && len(s.Resp.Errors) == 0 {
+			healthy[s.Resp.Header.MemberId] = true
+		}
+	}
+	return healthy, nil
+}
+
+// ParseAlarms parses the output of `etcdctl alarm list`, one alarm per
+// line such as "memberID:8e9e05c52164694d alarm:NOSPACE". It returns the
+// alarms without the member ID prefix.
+func ParseAlarms(out []byte) []string {
+	var alarms []string
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if i := strings.Index(line, "alarm:"); i >= 0 {
+			alarm := strings.TrimPrefix(line[i:], "alarm:")
+			if member := strings.TrimSpace(line[:i]); member != "" {
+				alarm += " (" + member + ")"
+			}
+			line = alarm
+		}
+		alarms = append(alarms, line)
+	}
+	return alarms
+}
+
+// etcdctlOnce runs etcdctl with args inside the etcd container, giving
+// up after 5 seconds, or after probeTimeout if crictl or SSH hang.
+func etcdctlOnce(client transport.Transport, containerID string, args ...string) ([]byte, error) {
+	args = append([]string{"--command-timeout=5s"}, args...)
+	res, err := client.Exec(EtcdctlCommand(containerID, args...), transport.ExecOptions{Timeout: probeTimeout})
+	if err == nil {
+		err = res.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res.Stdout, nil
+}
+
+// Quorum is the cluster-wide view built from the probes of every host.
+type Quorum struct {
+	// Voters are the IDs of the members which aren't learners, in any of
+	// the member lists read.
+	Voters []uint64
+	// Leader is the leader agreed upon by the largest number of voters,
+	// 0 if none has one.
+	Leader uint64
+	// Agreeing is the number of voters reachable and agreeing on Leader.
+	Agreeing int
+}
+
+// Needed returns the number of voters making a quorum.
+func (q *Quorum) Needed() int {
+	return len(q.Voters)/2 + 1
+}
+
+// Intact reports whether a quorum of voters is reachable and agrees on a
+// leader, in which case the cluster doesn't need to be recreated.
+func (q *Quorum) Intact() bool {
+	return len(q.Voters) > 0 && q.Leader != 0 && q.Agreeing >= q.Needed()
+}
+
+// CheckQuorum builds the cluster-wide view of the probes. The probes of
+// the hosts which couldn't be probed are nil.
+func CheckQuorum(probes []*EtcdProbe) *Quorum {
+	q := &Quorum{}
+	voters := map[uint64]bool{}
+	for _, p := range probes {
+		if p == nil {
+			continue
+		}
+		for _, m := range p.Members {
+			if !m.IsLearner && !voters[m.ID] {
+				voters[m.ID] = true
+				q.Voters = append(q.Voters, m.ID)
+			}
+		}
+	}
+
+	votes := map[uint64]int{}
+	for _, p := range probes {
+		if p != nil && voters[p.MemberID] && p.Leader != 0 {
+			votes[p.Leader]++
+		}
+	}
+	for leader, n := range votes {
+		if n > q.Agreeing || (n == q.Agreeing && leader < q.Leader) {
+			q.Leader, q.Agreeing = leader, n
+		}
+	}
+	return q
+}
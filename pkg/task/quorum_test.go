@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+func TestProbeEtcd(t *testing.T) {
+	env := newFakeEnv(2)
+	env.seed()
+	id := env.cluster.Member(env.nodes[0].IP).ID
+
+	p, err := ProbeEtcd(env.nodes[0])
+	require.NoError(t, err)
+	assert.Equal(t, id, p.MemberID)
+	assert.Equal(t, id, p.Leader)
+	assert.NoError(t, p.MembersErr)
+	assert.Len(t, p.Members, 1)
+	assert.Equal(t, int64(20480), p.DBSize)
+	assert.Empty(t, p.Alarms)
+
+	env.cluster.Alarms = []string{"NOSPACE"}
+	p, err = ProbeEtcd(env.nodes[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprintf("NOSPACE (memberID:%x)", id)}, p.Alarms)
+
+	_, err = ProbeEtcd(env.nodes[1])
+	assert.ErrorIs(t, err, ErrEtcdNotRunning)
+}
+
+// execOptionsRecorder records the options of the commands run through it.
+type execOptionsRecorder struct {
+	transport.Transport
+	opts []transport.ExecOptions
+}
+
+func (r *execOptionsRecorder) Exec(cmd string, opts transport.ExecOptions) (*transport.Result, error) {
+	r.opts = append(r.opts, opts)
+	return r.Transport.Exec(cmd, opts)
+}
+
+func TestProbeEtcdTimesOut(t *testing.T) {
+	env := newFakeEnv(1)
+	env.seed()
+
+	client := &execOptionsRecorder{Transport: env.nodes[0]}
+	_, err := ProbeEtcd(client)
+	require.NoError(t, err)
+	require.NotEmpty(t, client.opts)
+	for _, opts := range client.opts {
+		assert.Equal(t, probeTimeout, opts.Timeout)
+	}
+}
+
+func TestParseAlarms(t *testing.T) {
+	assert.Empty(t, ParseAlarms(nil))
+	assert.Equal(t, []string{"NOSPACE (memberID:8e9e05c52164694d)", "CORRUPT (memberID:1)"},
+		ParseAlarms([]byte("memberID:8e9e05c52164694d alarm:NOSPACE\nmemberID:1 alarm:CORRUPT\n")))
+}
+
+func TestHealthyMembers(t *testing.T) {
+	env := newFakeEnv(2)
+	env.seed()
+	env.cluster.AddUnstartedLearner(env.nodes[1].IP)
+
+	healthy, err := HealthyMembers(env.nodes[0], env.nodes[0].RunningContainer())
+	require.NoError(t, err)
+	assert.Equal(t, map[uint64]bool{env.cluster.Member(env.nodes[0].IP).ID: true}, healthy)
+}
+
+func TestCheckQuorum(t *testing.T) {
+	members := []*etcdserverpb.Member{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4, IsLearner: true}}
+	probe := func(id, leader uint64) *EtcdProbe {
+		return &EtcdProbe{MemberID: id, Leader: leader, Members: members}
+	}
+
+	tests := []struct {
+		name     string
+		probes   []*EtcdProbe
+		intact   bool
+		leader   uint64
+		agreeing int
+	}{
+		{"all agree", []*EtcdProbe{probe(1, 1), probe(2, 1), probe(3, 1)}, true, 1, 3},
+		{"one down", []*EtcdProbe{probe(1, 1), probe(2, 1), nil}, true, 1, 2},
+		{"two down", []*EtcdProbe{probe(1, 1), nil, nil}, false, 1, 1},
+		{"no leader", []*EtcdProbe{probe(1, 0), probe(2, 0), probe(3, 0)}, false, 0, 0},
+		{"split", []*EtcdProbe{probe(1, 1), probe(2, 2), nil}, false, 1, 1},
+		{"learner doesn't count", []*EtcdProbe{probe(1, 1), probe(4, 1), nil}, false, 1, 1},
+		{"nothing reachable", []*EtcdProbe{nil, nil, nil}, false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := CheckQuorum(tt.probes)
+			assert.Equal(t, tt.intact, q.Intact())
+			assert.Equal(t, tt.leader, q.Leader)
+			assert.Equal(t, tt.agreeing, q.Agreeing)
+		})
+	}
+
+	// Without any member list, the voters are unknown.
+	q := CheckQuorum([]*EtcdProbe{{MemberID: 1, Leader: 1}})
+	assert.False(t, q.Intact())
+}
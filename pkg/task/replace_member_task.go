@@ -0,0 +1,282 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// RemoveMemberTask removes the stale member of Member from the cluster,
+// through the healthy member it runs on. The member is found by its peer
+// URL, and may be a learner left by an interrupted replacement.
+type RemoveMemberTask struct {
+	Description string
+	Member      *config.Host
+	// MemberID removes the member with this ID instead, e.g. one which
+	// isn't in the hosts config. Member is then unused.
+	MemberID uint64
+}
+
+func (t *RemoveMemberTask) Name() string {
+	return "RemoveMemberTask"
+}
+
+func (t *RemoveMemberTask) Run(client transport.Transport) (string, error) {
+	containerID, err := EtcdContainerID(client)
+	if err != nil {
+		return "", err
+	}
+
+	members, err := ListMembers(client, containerID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, m := range members {
+		if !t.matches(m) {
+			continue
+		}
+
+		memberID := fmt.Sprintf("%x", m.ID)
+		logger(client).Info("Removing stale member", "member", t.name(m), "member_id", memberID, "is_learner", m.IsLearner)
+		if _, err = removeMember(client, containerID, memberID); err != nil {
+			return memberID, fmt.Errorf("failed to remove member %s: %w", memberID, err)
+		}
+		meta := event.Meta{}
+		if t.Member != nil {
+			meta.Host = t.Member.Host
+		}
+		event.Emit(client, &event.MemberRemoved{Meta: meta, MemberID: m.ID})
+		logger(client).Info("Stale member removed", "member", t.name(m), "member_id", memberID)
+		return memberID, nil
+	}
+
+	logger(client).Info("The member isn't in the cluster, nothing to remove", "member", t.name(nil))
+	return "", nil
+}
+
+func (t *RemoveMemberTask) matches(m *etcdserverpb.Member) bool {
+	if t.MemberID != 0 {
+		return m.ID == t.MemberID
+	}
+	return len(m.PeerURLs) > 0 && extractIPFromPeerURL(m.PeerURLs[0]) == t.Member.Host
+}
+
+// name returns the name of the member removed, for the logs.
+func (t *RemoveMemberTask) name(m *etcdserverpb.Member) string {
+	switch {
+	case t.Member != nil:
+		return t.Member.Name
+	case m != nil && m.Name != "":
+		return m.Name
+	default:
+		return fmt.Sprintf("%x", t.MemberID)
+	}
+}
+
+// UpdatePeerURLTask points the member MemberID to PeerURL, through the
+// healthy member it runs on. The member is updated in place rather than
+// removed and added again, so it keeps its ID and its data. The URL flags
+// of the etcd manifest of Member, if it has one, are then rewritten from
+// the old address to the new one, which restarts etcd on it.
+type UpdatePeerURLTask struct {
+	Description string
+	MemberID    uint64
+	PeerURL     string
+	// Member is the host of the member, at its new address. Its manifest
+	// is left as is if unset.
+	Member *config.Host
+
+	// Connect opens a transport to Member, it defaults to
+	// config.Host.Connect.
+	Connect func(h *config.Host) (transport.Transport, error)
+}
+
+func (t *UpdatePeerURLTask) Name() string {
+	return "UpdatePeerURLTask"
+}
+
+func (t *UpdatePeerURLTask) Run(client transport.Transport) (string, error) {
+	containerID, err := EtcdContainerID(client)
+	if err != nil {
+		return "", err
+	}
+
+	members, err := ListMembers(client, containerID)
+	if err != nil {
+		return "", err
+	}
+	var oldPeerURL string
+	for _, m := range members {
+		if m.ID == t.MemberID && len(m.PeerURLs) > 0 {
+			oldPeerURL = m.PeerURLs[0]
+		}
+	}
+
+	memberID := fmt.Sprintf("%x", t.MemberID)
+	logger(client).Info("Updating peer URL", "member_id", memberID, "peer_url", t.PeerURL)
+	if _, err = Etcdctl(client, containerID, "member", "update", memberID, "--peer-urls="+t.PeerURL); err != nil {
+		return "", fmt.Errorf("failed to update the peer URL of member %s: %w", memberID, err)
+	}
+	logger(client).Info("Peer URL updated", "member_id", memberID, "peer_url", t.PeerURL)
+
+	if t.Member == nil || oldPeerURL == "" {
+		return memberID, nil
+	}
+	if err = t.updateManifest(client, extractIPFromPeerURL(oldPeerURL), extractIPFromPeerURL(t.PeerURL)); err != nil {
+		return memberID, fmt.Errorf("failed to update the manifest of %s: %w", t.Member.Name, err)
+	}
+	return memberID, nil
+}
+
+// updateManifest replaces oldHost with newHost in the URLs passed to etcd
+// by the manifest of Member.
+func (t *UpdatePeerURLTask) updateManifest(masterClient transport.Transport, oldHost, newHost string) error {
+	if oldHost == newHost {
+		return nil
+	}
+
+	connect := t.Connect
+	if connect == nil {
+		connect = (*config.Host).Connect
+	}
+	conn, err := connect(t.Member)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	client := event.Attach(masterClient, conn, t.Member.Host)
+	defer client.Close()
+
+	current, err := ReadManifest(client)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if current == nil {
+		logger(client).Info("etcd has no manifest, nothing to update", "path", EtcdManifestPath)
+		return nil
+	}
+
+	updated, changed, err := replaceURLHost(current, oldHost, newHost)
+	if err != nil || !changed {
+		return err
+	}
+
+	localPath := filepath.Join(os.TempDir(), "etcd-peer-url.yaml")
+	if err = os.WriteFile(localPath, updated, 0o644); err != nil {
+		return fmt.Errorf("failed to write temp manifest: %w", err)
+	}
+	if err = client.Upload(localPath, EtcdManifestPath); err != nil {
+		return fmt.Errorf("failed to upload manifest: %w", err)
+	}
+	emitManifestChanged(client, localPath, "point the URLs to the new address", current)
+	logger(client).Info("Manifest updated to the new address", "old_address", oldHost, "new_address", newHost)
+	return nil
+}
+
+// replaceURLHost replaces oldHost with newHost in the URLs of the flags of
+// the etcd container of manifest, such as --initial-advertise-peer-urls,
+// --listen-peer-urls and --advertise-client-urls. It reports whether a
+// flag changed.
+func replaceURLHost(manifest []byte, oldHost, newHost string) ([]byte, bool, error) {
+	var pod corev1.Pod
+	if err := yaml.Unmarshal(manifest, &pod); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+
+	var changed, found bool
+	for i, c := range pod.Spec.Containers {
+		if strings.TrimSpace(c.Name) != "etcd" {
+			continue
+		}
+		found = true
+		for j, arg := range c.Command {
+			if !strings.HasPrefix(arg, "--") {
+				continue
+			}
+			updated := strings.ReplaceAll(arg, "//"+oldHost+":", "//"+newHost+":")
+			if updated != arg {
+				pod.Spec.Containers[i].Command[j] = updated
+				changed = true
+			}
+		}
+	}
+	if !found {
+		return nil, false, fmt.Errorf("etcd container not found in manifest")
+	}
+	if !changed {
+		return manifest, false, nil
+	}
+
+	data, err := yaml.Marshal(&pod)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return data, true, nil
+}
+
+// StopEtcdTask stops etcd on the host it runs on by removing its
+// manifest, and waits for the container to exit. The manifest is rebuilt
+// from the backed up one when the host is added back to the cluster.
+type StopEtcdTask struct {
+	Description string
+	TimeoutSec  int
+	// Reason is recorded with the removal of the manifest, "stop the
+	// stale member" by default.
+	Reason string
+}
+
+func (t *StopEtcdTask) Name() string {
+	return "StopEtcdTask"
+}
+
+func (t *StopEtcdTask) Run(client transport.Transport) (string, error) {
+	current, err := ReadManifest(client)
+	if err != nil {
+		return "", fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if current != nil {
+		reason := t.Reason
+		if reason == "" {
+			reason = "stop the stale member"
+		}
+		if err = removeManifest(client, reason, current); err != nil {
+			return "", err
+		}
+		logger(client).Info("Removed etcd manifest", "path", EtcdManifestPath)
+	}
+
+	timeoutSec := t.TimeoutSec
+	if timeoutSec == 0 {
+		timeoutSec = 120
+	}
+	waitTask := &CommandTask{
+		Description: "Wait for etcd container to stop",
+		Command:     "sudo crictl ps --label io.kubernetes.container.name=etcd -q | head -n 1",
+		Check: &Check{
+			ExpectedExitCode: 0,
+			ExpectedRegex:    "^$",
+			AllowEmptyOutput: true,
+			TimeoutSec:       timeoutSec,
+			RetryIntervalSec: 5,
+		},
+	}
+	if _, err = waitTask.Run(client); err != nil {
+		return "", fmt.Errorf("etcd did not stop: %w", err)
+	}
+
+	logger(client).Info("etcd is stopped")
+	return "etcd stopped", nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vmware/etcd-recovery/pkg/config"
+	"github.com/vmware/etcd-recovery/pkg/fakenode"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
+
+// newDegradedEnv returns a cluster of three members, whose last one has
+// failed: its etcd container keeps running but isn't part of the quorum.
+func newDegradedEnv() *fakeEnv {
+	env := newFakeEnv(3)
+	for _, n := range env.nodes {
+		env.cluster.AddMember(n, false)
+		n.StartEtcd(n.Manifest())
+	}
+	return env
+}
+
+func TestReplaceMember(t *testing.T) {
+	env := newDegradedEnv()
+	failed := env.nodes[2]
+	oldID := env.cluster.Member(failed.IP).ID
+
+	remove := &RemoveMemberTask{Member: env.hosts[2]}
+	removedID, err := remove.Run(env.nodes[0])
+	require.NoError(t, err)
+	assert.NotEmpty(t, removedID)
+	assert.Nil(t, env.cluster.Member(failed.IP))
+
+	// Nothing left to remove when run again.
+	removedID, err = remove.Run(env.nodes[0])
+	require.NoError(t, err)
+	assert.Empty(t, removedID)
+
+	_, err = (&StopEtcdTask{TimeoutSec: 1}).Run(failed)
+	require.NoError(t, err)
+	assert.Empty(t, failed.RunningContainer())
+	_, ok := failed.File(fakenode.ManifestPath)
+	assert.False(t, ok)
+
+	// The data of the stale member is wiped before it is added back,
+	// which is confirmed by the user; start from an empty one here.
+	failed.SetDataDir(false)
+	_, err = env.addMemberTask(2).Run(env.nodes[0])
+	require.NoError(t, err)
+
+	members := env.cluster.Members()
+	require.Len(t, members, 3)
+	replaced := env.cluster.Member(failed.IP)
+	require.NotNil(t, replaced)
+	assert.NotEqual(t, oldID, replaced.ID)
+	assert.False(t, replaced.IsLearner)
+
+	// The cluster was never forced to a single member.
+	for _, n := range env.nodes {
+		manifest, _ := n.File(fakenode.ManifestPath)
+		assert.NotContains(t, string(manifest), "--force-new-cluster")
+	}
+}
+
+func TestStopEtcdTaskWithoutManifest(t *testing.T) {
+	env := newFakeEnv(1)
+
+	_, err := (&StopEtcdTask{TimeoutSec: 1}).Run(env.nodes[0])
+	require.NoError(t, err)
+}
+
+func TestRemoveMemberTaskByID(t *testing.T) {
+	env := newDegradedEnv()
+	unknown := env.cluster.AddUnstartedLearner("10.0.0.9")
+
+	_, err := (&RemoveMemberTask{MemberID: unknown}).Run(env.nodes[0])
+	require.NoError(t, err)
+	assert.Len(t, env.cluster.Members(), 3)
+	assert.Nil(t, env.cluster.Member("10.0.0.9"))
+}
+
+func TestRemoveMemberAlreadyGone(t *testing.T) {
+	env := newDegradedEnv()
+	containerID, err := EtcdContainerID(env.nodes[0])
+	require.NoError(t, err)
+
+	start := time.Now()
+	removed, err := removeMember(env.nodes[0], containerID, "9999")
+	require.NoError(t, err)
+	assert.False(t, removed)
+	assert.Less(t, time.Since(start), time.Second, "a member already gone must not be retried")
+}
+
+func TestUpdatePeerURLTask(t *testing.T) {
+	env := newDegradedEnv()
+	id := env.cluster.Member(env.nodes[2].IP).ID
+
+	moved := &config.Host{Name: "etcd-vm3", Host: "10.0.0.30"}
+	update := &UpdatePeerURLTask{
+		MemberID: id,
+		PeerURL:  "https://10.0.0.30:2380",
+		Member:   moved,
+		Connect: func(h *config.Host) (transport.Transport, error) {
+			return env.nodes[2], nil
+		},
+	}
+	_, err := update.Run(env.nodes[0])
+	require.NoError(t, err)
+	m := env.cluster.Member("10.0.0.30")
+	require.NotNil(t, m)
+	assert.Equal(t, id, m.ID)
+
+	manifest, ok := env.nodes[2].File(fakenode.ManifestPath)
+	require.True(t, ok)
+	assert.Contains(t, string(manifest), "--initial-advertise-peer-urls=https://10.0.0.30:2380")
+	assert.Contains(t, string(manifest), "--listen-peer-urls=https://10.0.0.30:2380")
+	assert.Contains(t, string(manifest), "--advertise-client-urls=https://10.0.0.30:2379")
+	assert.NotContains(t, string(manifest), "10.0.0.3:")
+	assert.NotEmpty(t, env.nodes[2].RunningContainer())
+}
+
+func TestUpdatePeerURLTaskWithoutManifest(t *testing.T) {
+	env := newDegradedEnv()
+	id := env.cluster.Member(env.nodes[2].IP).ID
+	_, err := (&StopEtcdTask{TimeoutSec: 1}).Run(env.nodes[2])
+	require.NoError(t, err)
+
+	update := &UpdatePeerURLTask{
+		MemberID: id,
+		PeerURL:  "https://10.0.0.30:2380",
+		Member:   &config.Host{Name: "etcd-vm3", Host: "10.0.0.30"},
+		Connect: func(h *config.Host) (transport.Transport, error) {
+			return env.nodes[2], nil
+		},
+	}
+	_, err = update.Run(env.nodes[0])
+	require.NoError(t, err)
+	_, ok := env.nodes[2].File(fakenode.ManifestPath)
+	assert.False(t, ok)
+}
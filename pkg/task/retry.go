@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package task
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how often a CommandTask is retried until its
+// Check passes. The overall Check.TimeoutSec still applies.
+type RetryPolicy struct {
+	// MaxAttempts stops retrying after the given number of attempts,
+	// zero means retry until the timeout expires.
+	MaxAttempts int
+	// InitialInterval is the wait before the second attempt.
+	InitialInterval time.Duration
+	// Multiplier grows the interval after each attempt, a value <= 1
+	// keeps the interval fixed.
+	Multiplier float64
+	// MaxInterval caps the interval, zero means no cap.
+	MaxInterval time.Duration
+	// Jitter randomizes each interval by up to the given fraction, e.g.
+	// 0.2 waits between 80% and 120% of the interval.
+	Jitter float64
+}
+
+// FixedInterval returns a policy retrying every interval until the timeout.
+func FixedInterval(interval time.Duration) *RetryPolicy {
+	return &RetryPolicy{InitialInterval: interval}
+}
+
+// ExponentialBackoff returns a policy doubling the interval after each
+// attempt, up to maxInterval, with 20% jitter.
+func ExponentialBackoff(initial, maxInterval time.Duration, maxAttempts int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:     maxAttempts,
+		InitialInterval: initial,
+		Multiplier:      2,
+		MaxInterval:     maxInterval,
+		Jitter:          0.2,
+	}
+}
+
+// Interval returns the wait after the given attempt, starting at 1.
+func (p *RetryPolicy) Interval(attempt int) time.Duration {
+	interval := float64(p.InitialInterval)
+	if p.Multiplier > 1 && attempt > 1 {
+		interval *= math.Pow(p.Multiplier, float64(attempt-1))
+	}
+	if p.MaxInterval > 0 && interval > float64(p.MaxInterval) {
+		interval = float64(p.MaxInterval)
+	}
+	if p.Jitter > 0 {
+		interval *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(interval)
+}
+
+// exhausted returns true if no attempt is allowed after the given one.
+func (p *RetryPolicy) exhausted(attempt int) bool {
+	return p.MaxAttempts > 0 && attempt >= p.MaxAttempts
+}
@@ -4,9 +4,23 @@
 
 package task
 
-import "github.com/vmware/etcd-recovery/pkg/ssh"
+import (
+	"log/slog"
+
+	"github.com/vmware/etcd-recovery/pkg/event"
+	"github.com/vmware/etcd-recovery/pkg/transport"
+)
 
 type Task interface {
 	Name() string
-	Run(client *ssh.Client) (string, error)
+	Run(client transport.Transport) (string, error)
+}
+
+// logger returns the logger of the records about the host client is
+// connected to, if it is known.
+func logger(client transport.Transport) *slog.Logger {
+	if t, ok := client.(*event.Transport); ok {
+		return slog.With(slog.Group("host", slog.String("address", t.Host())))
+	}
+	return slog.Default()
 }
@@ -6,10 +6,9 @@ package task
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
-	"github.com/vmware/etcd-recovery/pkg/ssh"
+	"github.com/vmware/etcd-recovery/pkg/transport"
 )
 
 // WaitForEtcdRunningTask waits for etcd container to be running
@@ -18,13 +17,16 @@ type WaitForEtcdRunningTask struct {
 	OldContainerID   string
 	TimeoutSec       int
 	RetryIntervalSec int
+	// SkipLogs doesn't attach the logs of the etcd containers to the
+	// error, when etcd isn't expected to be running.
+	SkipLogs bool
 }
 
 func (t *WaitForEtcdRunningTask) Name() string {
 	return "WaitForEtcdRunningTask"
 }
 
-func (t *WaitForEtcdRunningTask) Run(client *ssh.Client) (string, error) {
+func (t *WaitForEtcdRunningTask) Run(client transport.Transport) (string, error) {
 	task := &CommandTask{
 		Description: "Wait for etcd container to be running",
 		Command:     "sudo crictl ps --label io.kubernetes.container.name=etcd -q | head -n 1",
@@ -45,14 +47,21 @@ func (t *WaitForEtcdRunningTask) Run(client *ssh.Client) (string, error) {
 
 	out, err := task.Run(client)
 	if err != nil {
-		return "", err
+		return "", t.withContainerLogs(client, fmt.Errorf("%w: %w", ErrEtcdNotRunning, err))
 	}
 
 	containerID := strings.TrimSpace(out)
 	if containerID == "" {
-		return "", fmt.Errorf("etcd container not running")
+		return "", t.withContainerLogs(client, fmt.Errorf("%w: container not found", ErrEtcdNotRunning))
 	}
 
-	log.Printf("etcd container %s is running\n", containerID)
+	logger(client).Debug("etcd container is running", "container_id", containerID)
 	return containerID, nil
 }
+
+func (t *WaitForEtcdRunningTask) withContainerLogs(client transport.Transport, err error) error {
+	if t.SkipLogs {
+		return err
+	}
+	return withContainerLogs(client, err)
+}
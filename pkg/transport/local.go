@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// Local runs commands and copies files on the machine etcd-recovery
+// is running on, so that an operator logged into a control plane VM
+// doesn't need to SSH into the same VM.
+type Local struct{}
+
+// NewLocal returns a transport for the local machine.
+func NewLocal() *Local {
+	return &Local{}
+}
+
+// Run runs the cmd with `sh -c`, it returns CombinedOutput and err if any.
+func (l *Local) Run(cmd string) ([]byte, error) {
+	return exec.Command("sh", "-c", cmd).CombinedOutput()
+}
+
+// Exec runs the cmd with `sh -c` and returns its stdout, stderr and exit
+// status separately.
+func (l *Local) Exec(cmd string, opts ExecOptions) (*Result, error) {
+	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, "sh", "-c", cmd)
+	c.Stdout = teeWriter(&stdout, opts.Stdout)
+	c.Stderr = teeWriter(&stderr, opts.Stderr)
+	// don't wait for background processes keeping the output open
+	// once the command is killed
+	c.WaitDelay = time.Second
+
+	start := time.Now()
+	err := c.Run()
+	res := &Result{
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		Duration: time.Since(start),
+	}
+
+	if ctx.Err() != nil {
+		return res, fmt.Errorf("command %q timed out after %v", cmd, opts.Timeout)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		res.ExitCode = exitErr.ExitCode()
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			res.Signal = ws.Signal().String()
+		}
+		return res, nil
+	}
+	return res, err
+}
+
+// Upload copies localPath to remotePath, falling back to sudo if the
+// current user isn't allowed to write remotePath.
+func (l *Local) Upload(localPath string, remotePath string) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+
+	if err := copyFile(localPath, remotePath, info.Mode()); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return l.sudoCopy(localPath, remotePath)
+		}
+		return err
+	}
+	return nil
+}
+
+// Download copies remotePath to localPath, falling back to sudo if the
+// current user isn't allowed to read remotePath.
+func (l *Local) Download(remotePath string, localPath string) error {
+	info, err := os.Stat(remotePath)
+	if err != nil && !errors.Is(err, os.ErrPermission) {
+		return err
+	}
+
+	if err == nil {
+		if err = copyFile(remotePath, localPath, info.Mode()); err == nil {
+			return nil
+		}
+		if !errors.Is(err, os.ErrPermission) {
+			return err
+		}
+	}
+
+	out, err := exec.Command("sudo", "cat", remotePath).Output()
+	if err != nil {
+		return fmt.Errorf("failed to sudo cat %s: %w", remotePath, err)
+	}
+	return os.WriteFile(localPath, out, 0o644)
+}
+
+// Stat returns the file info of path.
+func (l *Local) Stat(path string) (os.FileInfo, error) {
+	return os.Stat(path)
+}
+
+// Close is a no-op for the local transport.
+func (l *Local) Close() error {
+	return nil
+}
+
+func (l *Local) sudoCopy(src, dst string) error {
+	if out, err := exec.Command("sudo", "cp", src, dst).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to sudo cp from %s to %s: %w, output: %s", src, dst, err, string(out))
+	}
+	return nil
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err = io.Copy(out, in); err != nil {
+		return err
+	}
+
+	if err = out.Chmod(mode); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// teeWriter returns buf, or a writer duplicating to buf and w if w is set.
+func teeWriter(buf *bytes.Buffer, w io.Writer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(buf, w)
+}
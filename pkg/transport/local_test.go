@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package transport
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalRun(t *testing.T) {
+	l := NewLocal()
+
+	out, err := l.Run("echo hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(out))
+
+	_, err = l.Run("exit 3")
+	require.Error(t, err)
+	code, ok := ExitStatus(err)
+	require.True(t, ok)
+	assert.Equal(t, 3, code)
+}
+
+func TestLocalExec(t *testing.T) {
+	l := NewLocal()
+
+	res, err := l.Exec("echo out; echo err >&2; exit 2", ExecOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "out\n", string(res.Stdout))
+	assert.Equal(t, "err\n", string(res.Stderr))
+	assert.Equal(t, 2, res.ExitCode)
+	require.ErrorContains(t, res.Err(), "Process exited with status 2: err")
+
+	var streamed strings.Builder
+	res, err = l.Exec("echo streamed", ExecOptions{Stdout: &streamed})
+	require.NoError(t, err)
+	require.NoError(t, res.Err())
+	assert.Equal(t, "streamed\n", streamed.String())
+
+	_, err = l.Exec("sleep 5", ExecOptions{Timeout: 100 * time.Millisecond})
+	require.ErrorContains(t, err, "timed out")
+}
+
+func TestLocalUploadDownloadStat(t *testing.T) {
+	l := NewLocal()
+	dir := t.TempDir()
+
+	src := filepath.Join(dir, "src.yaml")
+	require.NoError(t, os.WriteFile(src, []byte("etcd"), 0o600))
+
+	dst := filepath.Join(dir, "dst.yaml")
+	require.NoError(t, l.Upload(src, dst))
+
+	info, err := l.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	back := filepath.Join(dir, "back.yaml")
+	require.NoError(t, l.Download(dst, back))
+	data, err := os.ReadFile(back)
+	require.NoError(t, err)
+	assert.Equal(t, "etcd", string(data))
+
+	_, err = l.Stat(filepath.Join(dir, "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestExitStatus(t *testing.T) {
+	code, ok := ExitStatus(nil)
+	assert.True(t, ok)
+	assert.Equal(t, 0, code)
+
+	_, ok = ExitStatus(errors.New("connection lost"))
+	assert.False(t, ok)
+}
+
+func TestIsLocalAddress(t *testing.T) {
+	assert.True(t, IsLocalAddress("127.0.0.1"))
+	assert.False(t, IsLocalAddress("192.0.2.1"))
+}
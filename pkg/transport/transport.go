@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Broadcom. All Rights Reserved.
+// Broadcom Confidential. The term "Broadcom" refers to Broadcom Inc.
+// and/or its subsidiaries.
+
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	cryptoSSH "golang.org/x/crypto/ssh"
+)
+
+// Transport is the set of operations the tasks need to drive a
+// control plane VM. It is implemented by ssh.Client for remote hosts
+// and by Local for the machine etcd-recovery is running on.
+type Transport interface {
+	// Run runs the cmd and returns its combined output.
+	Run(cmd string) ([]byte, error)
+	// Exec runs the cmd and returns its stdout, stderr and exit status
+	// separately. A non-zero exit status isn't reported as an error;
+	// the error is only set if the command couldn't be run to the end.
+	Exec(cmd string, opts ExecOptions) (*Result, error)
+	// Upload copies a local file to the target path.
+	Upload(localPath string, remotePath string) error
+	// Download copies the target file to a local path.
+	Download(remotePath string, localPath string) error
+	// Stat returns the file info of the target path.
+	Stat(remotePath string) (os.FileInfo, error)
+	// Close releases any resources held by the transport.
+	Close() error
+}
+
+var _ Transport = (*Local)(nil)
+
+// ExecOptions configures Transport.Exec.
+type ExecOptions struct {
+	// Timeout kills the command if it hasn't finished in time. Zero
+	// means no timeout.
+	Timeout time.Duration
+	// Stdout and Stderr, if set, receive the output of the command
+	// while it is running, in addition to the Result.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Result is the outcome of a command run by Transport.Exec.
+type Result struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+	// Signal is the name of the signal that terminated the command, if any.
+	Signal   string
+	Duration time.Duration
+}
+
+// Err returns an *ExitError if the command exited with a non-zero
+// status, or nil otherwise.
+func (r *Result) Err() error {
+	if r.ExitCode == 0 && r.Signal == "" {
+		return nil
+	}
+	return &ExitError{Status: r.ExitCode, Signal: r.Signal, Stderr: strings.TrimSpace(string(r.Stderr))}
+}
+
+// ExitError is returned when a command exits with a non-zero status. It
+// carries the stderr of the command so that it ends up in error messages.
+type ExitError struct {
+	Status int
+	Signal string
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	msg := fmt.Sprintf("Process exited with status %d", e.Status)
+	if e.Signal != "" {
+		msg += fmt.Sprintf(" from signal %s", e.Signal)
+	}
+	if e.Stderr != "" {
+		msg += ": " + e.Stderr
+	}
+	return msg
+}
+
+// ExitStatus returns the exit status of the command.
+func (e *ExitError) ExitStatus() int {
+	return e.Status
+}
+
+// ExitStatus extracts the exit status of a command from the error
+// returned by Transport.Run or Result.Err. The second return value is false if the
+// error isn't caused by the command exiting with a non-zero status,
+// e.g. the connection was lost.
+func ExitStatus(err error) (int, bool) {
+	if err == nil {
+		return 0, true
+	}
+
+	var sshErr *cryptoSSH.ExitError
+	if errors.As(err, &sshErr) {
+		return sshErr.ExitStatus(), true
+	}
+
+	var transportErr *ExitError
+	if errors.As(err, &transportErr) {
+		return transportErr.ExitStatus(), true
+	}
+
+	var execErr *exec.ExitError
+	if errors.As(err, &execErr) {
+		return execErr.ExitCode(), true
+	}
+
+	return -1, false
+}
+
+// IsLocalAddress returns true if host resolves to an address assigned
+// to one of the network interfaces of this machine.
+func IsLocalAddress(host string) bool {
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return false
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+
+	for _, ip := range ips {
+		if ip.IsLoopback() {
+			return true
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}